@@ -1,57 +1,35 @@
 package main
 
+// build_genesis kiểm tra genesis.json và in ra hash của genesis block. Node
+// tự dựng genesis block từ cùng file này, nên mọi node dùng chung một file
+// sẽ có cùng genesis hash.
+//
+//	go run ./cmd/build_genesis [--genesis genesis.json]
+
 import (
-	"blockchain-go/pkg/blockchain"
-	"encoding/hex"
-	"encoding/json"
+	"blockchain-go/pkg/genesis"
+	"flag"
 	"fmt"
-	"os"
+	"log"
 )
 
-type GenesisData struct {
-	Alloc map[string]struct {
-		Balance float64 `json:"balance"`
-	} `json:"alloc"`
-}
-
 func main() {
-	genesisFile, err := os.ReadFile("genesis.json")
-	if err != nil {
-		panic(fmt.Sprintf("Failed to read genesis.json: %v", err))
-	}
-
-	var genesisData GenesisData
-	if err := json.Unmarshal(genesisFile, &genesisData); err != nil {
-		panic(fmt.Sprintf("Failed to parse genesis.json: %v", err))
-	}
-
-	var transactions []*blockchain.Transaction
-	for addr, data := range genesisData.Alloc {
-		receiverAddr, err := hex.DecodeString(addr)
-		if err != nil {
-			panic(fmt.Sprintf("Invalid address in genesis.json: %s", addr))
-		}
-		tx := &blockchain.Transaction{
-			Sender: []byte("GENESIS"), Receiver: receiverAddr, Amount: data.Balance,
-			Timestamp: 0, Signature: nil, PublicKey: nil,
-		}
-		transactions = append(transactions, tx)
-	}
+	path := flag.String("genesis", "genesis.json", "genesis file to check")
+	flag.Parse()
 
-	// Gọi hàm NewBlock sạch
-	genesisBlock := blockchain.NewBlock(transactions, []byte{}, 0)
-
-	blockData, err := json.MarshalIndent(genesisBlock, "", "  ")
+	g, err := genesis.Load(*path)
 	if err != nil {
-		panic(fmt.Sprintf("Failed to marshal genesis block: %v", err))
-	}
-
-	if err := os.WriteFile("genesis.dat", blockData, 0644); err != nil {
-		panic(fmt.Sprintf("Failed to write genesis.dat: %v", err))
+		log.Fatalf("❌ %v", err)
 	}
+	genesisBlock := g.Block()
 
-	fmt.Println("✅ Genesis block 'genesis.dat' created successfully!")
+	fmt.Printf("✅ %s is valid\n", *path)
+	fmt.Printf("   - Chain ID: %s\n", g.ChainID)
 	fmt.Printf("   - Hash: %x\n", genesisBlock.CurrentBlockHash)
-	fmt.Printf("   - Merkle Root: %x\n", genesisBlock.MerkleRoot)
+	fmt.Printf("   - Merkle Root: %x (%s)\n", genesisBlock.MerkleRoot, genesisBlock.TxRootType)
+	fmt.Printf("   - State Root: %x\n", genesisBlock.StateRoot)
+	fmt.Printf("   - Config Hash: %x\n", genesisBlock.ConfigHash)
+	fmt.Printf("   - Validators: %d\n", len(g.Validators))
+	fmt.Printf("   - Params: max %d txs per block, block interval %s\n", g.Params.MaxBlockTxs, g.Params.BlockInterval())
 	fmt.Printf("   - Transactions: %d\n", len(genesisBlock.Transactions))
 }
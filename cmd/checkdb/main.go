@@ -0,0 +1,338 @@
+package main
+
+// checkdb kiểm tra thư mục data của một node (nên dừng node trước): duyệt
+// chuỗi từ genesis, kiểm tra liên kết hash, Merkle root, chữ ký và state root
+// qua validation.ValidateBlock, tính lại số dư và so sánh với state đã lưu.
+// Với --repair, chuỗi được cắt về height nhất quán cuối cùng và state được
+// ghi lại từ kết quả tính lại.
+//
+//	go run ./cmd/checkdb --data data/node1 [--engine leveldb] [--repair]
+
+import (
+	"blockchain-go/pkg/blockchain"
+	"blockchain-go/pkg/snapshot"
+	"blockchain-go/pkg/state"
+	"blockchain-go/pkg/storage"
+	"blockchain-go/pkg/validation"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+// maxBalanceReports bounds how many mismatching balances are printed.
+const maxBalanceReports = 20
+
+type checker struct {
+	db          *storage.DB
+	tip         int64
+	prunedBelow int64
+
+	// replay is the state rebuilt from the blocks, at height lastGood. It is
+	// nil when the bodies needed to rebuild it have been pruned.
+	replay      *state.State
+	replayStart int64
+
+	lastGood int64
+	chainOK  bool
+	extra    int // blocks in the height index above the tip
+	problems int
+}
+
+func main() {
+	dataDir := flag.String("data", "", "node data directory, e.g. data/node1")
+	engineName := flag.String("engine", "", "storage engine: leveldb or bolt (default leveldb)")
+	repair := flag.Bool("repair", false, "truncate to the last consistent height and rewrite the state")
+	flag.Parse()
+
+	if *dataDir == "" {
+		log.Fatal("❌ You must provide --data")
+	}
+	engine, err := storage.ParseEngine(*engineName)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if engine == storage.Memory {
+		log.Fatal("❌ The memory engine has nothing on disk to check")
+	}
+	if _, err := os.Stat(*dataDir); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	db, err := storage.OpenDBWithEngine(engine, *dataDir)
+	if err != nil {
+		log.Fatalf("❌ Failed to open DB: %v", err)
+	}
+	defer db.Close()
+
+	c := &checker{db: db, lastGood: -1}
+	if err := c.run(); err != nil {
+		db.Close()
+		log.Fatalf("❌ Check aborted: %v", err)
+	}
+
+	if c.problems == 0 {
+		fmt.Printf("✅ Database is consistent: %d blocks, tip at height %d\n", c.tip+1, c.tip)
+		return
+	}
+	fmt.Printf("\n❌ Found %d problem(s). Last consistent height: %d\n", c.problems, c.lastGood)
+	if !*repair {
+		fmt.Println("Run again with --repair to truncate the chain to that height and rewrite the state.")
+		db.Close()
+		os.Exit(1)
+	}
+	if err := c.repair(); err != nil {
+		db.Close()
+		log.Fatalf("❌ Repair failed: %v", err)
+	}
+}
+
+func (c *checker) report(height int64, format string, args ...interface{}) {
+	c.problems++
+	if height < 0 {
+		fmt.Printf("❌ %s\n", fmt.Sprintf(format, args...))
+		return
+	}
+	fmt.Printf("❌ height %d: %s\n", height, fmt.Sprintf(format, args...))
+}
+
+func (c *checker) run() error {
+	var err error
+	c.prunedBelow, err = c.db.PrunedBelow()
+	if err != nil {
+		return err
+	}
+	tip, _, err := c.db.GetLatestHeader()
+	if err != nil {
+		return fmt.Errorf("can not load the tip: %w", err)
+	}
+	c.tip = tip.Height
+	fmt.Printf("🔍 Checking %d blocks (tip %d, bodies pruned below %d)\n", c.tip+1, c.tip, c.prunedBelow)
+
+	if err := c.loadReplayBase(); err != nil {
+		return err
+	}
+	c.checkChain()
+
+	for h := c.tip + 1; ; h++ {
+		if _, _, err := c.db.GetHeaderByHeight(int(h)); err != nil {
+			break
+		}
+		c.extra++
+	}
+	if c.extra > 0 {
+		c.report(-1, "%d blocks are indexed above the tip %d", c.extra, c.tip)
+	}
+
+	if c.chainOK && c.replay != nil {
+		return c.checkState(tip)
+	}
+	return nil
+}
+
+// loadReplayBase picks the state the blocks are replayed on: the empty state
+// before genesis, or on a pruned node the oldest local snapshot that the
+// remaining bodies can be replayed from.
+func (c *checker) loadReplayBase() error {
+	c.replay, _ = state.NewState(storage.OpenMemoryDB())
+	if c.prunedBelow == 0 {
+		return nil
+	}
+
+	infos, err := snapshot.NewStore(c.db, 0, 1).List()
+	if err != nil {
+		return fmt.Errorf("can not list snapshots: %w", err)
+	}
+	var base *snapshot.Info
+	for _, info := range infos {
+		if info.Height >= c.prunedBelow-1 && info.Height <= c.tip {
+			base = info // newest first, so the last match is the oldest
+		}
+	}
+	if base == nil {
+		fmt.Printf("⚠️ Bodies are pruned below %d and no snapshot covers them: only headers and the stored state root are checked\n", c.prunedBelow)
+		c.replay = nil
+		return nil
+	}
+
+	store := snapshot.NewStore(c.db, 0, 1)
+	chunks := make([][]state.Account, base.Chunks())
+	var accounts []state.Account
+	for i := range chunks {
+		chunks[i], err = store.Chunk(base.Height, i)
+		if err != nil {
+			return err
+		}
+		accounts = append(accounts, chunks[i]...)
+	}
+	if err := snapshot.Verify(base, chunks); err != nil {
+		return fmt.Errorf("snapshot at height %d is corrupted: %w", base.Height, err)
+	}
+	header, _, err := c.db.GetHeaderByHeight(int(base.Height))
+	if err != nil {
+		return fmt.Errorf("can not load header %d of the snapshot: %w", base.Height, err)
+	}
+	if !bytes.Equal(header.CurrentBlockHash, base.BlockHash) || !bytes.Equal(header.StateRoot, base.StateRoot) {
+		return fmt.Errorf("snapshot at height %d does not match the stored header", base.Height)
+	}
+	if err := c.replay.RestoreSnapshot(base.Height, accounts); err != nil {
+		return err
+	}
+	c.replayStart = base.Height + 1
+	fmt.Printf("📸 Replaying from the snapshot at height %d\n", base.Height)
+	return nil
+}
+
+// checkChain walks the chain from genesis and stops at the first block that
+// fails. Blocks before the replay base are only checked by header.
+func (c *checker) checkChain() {
+	var prev *blockchain.Block
+	for h := int64(0); h <= c.tip; h++ {
+		header, txCount, err := c.db.GetHeaderByHeight(int(h))
+		if err != nil {
+			// Fast-synced nodes never had the headers before their snapshot.
+			if h < c.prunedBelow && errors.Is(err, storage.ErrNotFound) {
+				prev = nil
+				continue
+			}
+			c.report(h, "can not load header: %v", err)
+			return
+		}
+		if header.Height != h {
+			c.report(h, "height index points to block %d", header.Height)
+			return
+		}
+		if !bytes.Equal(header.Hash(), header.CurrentBlockHash) {
+			c.report(h, "header hash %x does not match its contents", header.CurrentBlockHash)
+			return
+		}
+		if prev != nil && !bytes.Equal(header.PreviousBlockHash, prev.CurrentBlockHash) {
+			c.report(h, "previous hash %x does not link to block %d (%x)", header.PreviousBlockHash, h-1, prev.CurrentBlockHash)
+			return
+		}
+
+		// The replay always starts at or above prunedBelow, so every block it
+		// needs has a body here.
+		if h >= c.prunedBelow {
+			block, err := c.db.GetBlockByHeight(int(h))
+			if err != nil {
+				c.report(h, "can not load block body: %v", err)
+				return
+			}
+			if len(block.Transactions) != txCount {
+				c.report(h, "header says %d transactions, body has %d", txCount, len(block.Transactions))
+				return
+			}
+			if c.replay == nil || h < c.replayStart {
+				if !bytes.Equal(blockchain.TxRoot(block.TxRootType, block.Transactions), block.MerkleRoot) {
+					c.report(h, "transactions do not match the Merkle root")
+					return
+				}
+			} else if h >= c.replayStart {
+				if err := validation.ValidateBlock(block, c.replay, prev); err != nil {
+					c.report(h, "%v", err)
+					return
+				}
+				if err := c.replay.ApplyBlock(block); err != nil {
+					c.report(h, "replay failed: %v", err)
+					c.replay = nil // partly applied, no longer usable for repair
+					return
+				}
+			}
+		}
+		prev = header
+		c.lastGood = h
+	}
+	c.chainOK = true
+}
+
+// checkState compares the stored balances with the replayed ones.
+func (c *checker) checkState(tip *blockchain.Block) error {
+	stored, err := state.NewState(c.db)
+	if err != nil {
+		return err
+	}
+	height, ok, err := stored.Height()
+	if err != nil {
+		return err
+	}
+	if !ok || height != c.tip {
+		c.report(-1, "state is at height %d (recorded: %v), chain tip is %d", height, ok, c.tip)
+	}
+
+	want, err := c.replay.Accounts()
+	if err != nil {
+		return err
+	}
+	got, err := stored.Accounts()
+	if err != nil {
+		return fmt.Errorf("can not read stored balances: %w", err)
+	}
+	balances := make(map[string]float64, len(got))
+	for _, acc := range got {
+		balances[acc.Address] = acc.Balance
+	}
+	mismatches := 0
+	for _, acc := range want {
+		balance, ok := balances[acc.Address]
+		delete(balances, acc.Address)
+		if ok && balance == acc.Balance {
+			continue
+		}
+		if mismatches < maxBalanceReports {
+			fmt.Printf("❌ balance of %s: stored %f, replayed %f\n", acc.Address, balance, acc.Balance)
+		}
+		mismatches++
+	}
+	for address, balance := range balances {
+		if mismatches < maxBalanceReports {
+			fmt.Printf("❌ balance of %s: stored %f, not in the replayed state\n", address, balance)
+		}
+		mismatches++
+	}
+	if mismatches > 0 {
+		c.report(-1, "%d balances differ from the replayed state", mismatches)
+	}
+	if root := state.ComputeStateRoot(got); !bytes.Equal(root, tip.StateRoot) {
+		c.report(-1, "stored state root %x does not match the tip's %x", root, tip.StateRoot)
+	}
+	return nil
+}
+
+// repair truncates the chain to the last consistent height and rewrites the
+// state from the replay.
+func (c *checker) repair() error {
+	if c.replay == nil {
+		return fmt.Errorf("the state can not be rebuilt from this database")
+	}
+	if c.lastGood < c.replayStart-1 || c.lastGood < 0 {
+		return fmt.Errorf("the first inconsistent block is below the replay base %d", c.replayStart)
+	}
+
+	if c.lastGood < c.tip || c.extra > 0 {
+		removed, err := c.db.TruncateAbove(c.lastGood)
+		if err != nil {
+			return err
+		}
+		snapshots, err := snapshot.NewStore(c.db, 0, 1).DeleteAbove(c.lastGood)
+		if err != nil {
+			return fmt.Errorf("failed to drop snapshots: %w", err)
+		}
+		fmt.Printf("✂️ Removed %d blocks and %d snapshots above height %d\n", removed, snapshots, c.lastGood)
+	}
+
+	accounts, err := c.replay.Accounts()
+	if err != nil {
+		return err
+	}
+	stored, err := state.NewState(c.db)
+	if err != nil {
+		return err
+	}
+	if err := stored.RollBack(c.lastGood, accounts); err != nil {
+		return fmt.Errorf("failed to rewrite state: %w", err)
+	}
+	fmt.Printf("🔧 Repaired: tip at height %d with %d accounts\n", c.lastGood, len(accounts))
+	return nil
+}
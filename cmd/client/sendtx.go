@@ -47,6 +47,13 @@ func main() {
 
 	client := nodepb.NewNodeServiceClient(conn)
 
+	// Hỏi node chain ID để ký giao dịch cho đúng mạng
+	info, err := client.Handshake(context.Background(), &nodepb.HandshakeRequest{})
+	if err != nil {
+		log.Fatalf("Handshake failed: %v", err)
+	}
+	log.Printf("🌐 Connected to chain %s", info.ChainId)
+
 	// Gửi một vài giao dịch
 	amounts := []float64{3500.0, 5500.123}
 
@@ -59,23 +66,17 @@ func main() {
 			Receiver:  receiverAddrBytes, // Sử dụng địa chỉ đã được decode
 			Amount:    amt,
 			Timestamp: time.Now().Unix(),
+			ChainID:   info.ChainId,
 		}
 
 		// 3. Ký giao dịch bằng Private Key đã được nạp từ file của Alice
-		err := wallet.SignTransaction(tx, aliceWallet.PrivateKey)
+		err := aliceWallet.SignTransaction(tx)
 		if err != nil {
 			log.Fatalf("Failed to sign transaction %d: %v", i+1, err)
 		}
 
 		// Chuyển đổi giao dịch sang định dạng protobuf
-		txProto := &nodepb.Transaction{
-			Sender:    tx.Sender,
-			Receiver:  tx.Receiver,
-			Amount:    tx.Amount,
-			Timestamp: tx.Timestamp,
-			Signature: tx.Signature,
-			PublicKey: tx.PublicKey,
-		}
+		txProto := blockchain.TransactionToProto(tx)
 
 		// Gửi giao dịch đến node
 		res, err := client.SendTransaction(context.Background(), txProto)
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"blockchain-go/pkg/cryptohelper"
 	"blockchain-go/pkg/wallet"
 	"flag"
 	"fmt"
@@ -10,7 +11,7 @@ import (
 
 func main() {
 	if len(os.Args) < 2 {
-		fmt.Println("Cách dùng: cli create-user --name alice")
+		fmt.Println("Cách dùng: cli create-user --name alice [--words 12] [--scheme p256] | cli restore-from-mnemonic --name alice [--index 0] [--scheme p256] | cli derive-account --from alice --name alice2 --index 1 | cli migrate-wallets [--dir wallets]")
 		return
 	}
 
@@ -18,22 +19,76 @@ func main() {
 	case "create-user":
 		fs := flag.NewFlagSet("create-user", flag.ExitOnError)
 		name := fs.String("name", "", "user name (must)")
+		words := fs.Int("words", 12, "number of words of the mnemonic: 12, 15, 18, 21 or 24")
+		schemeName := fs.String("scheme", "p256", "signature scheme: p256, secp256k1 or ed25519")
 		fs.Parse(os.Args[2:])
 
 		if *name == "" {
 			fmt.Println("❌ You must provide --name")
 			return
 		}
+		scheme, err := cryptohelper.ParseScheme(*schemeName)
+		if err != nil {
+			fmt.Println("❌", err)
+			return
+		}
+
+		createUser(*name, *words, scheme)
+
+	case "restore-from-mnemonic":
+		fs := flag.NewFlagSet("restore-from-mnemonic", flag.ExitOnError)
+		name := fs.String("name", "", "user name (must)")
+		index := fs.Uint("index", 0, "account index derived from the mnemonic")
+		schemeName := fs.String("scheme", "p256", "signature scheme the account was created with")
+		fs.Parse(os.Args[2:])
+
+		if *name == "" {
+			fmt.Println("❌ You must provide --name")
+			return
+		}
+		scheme, err := cryptohelper.ParseScheme(*schemeName)
+		if err != nil {
+			fmt.Println("❌", err)
+			return
+		}
+
+		restoreFromMnemonic(*name, uint32(*index), scheme)
+
+	case "derive-account":
+		fs := flag.NewFlagSet("derive-account", flag.ExitOnError)
+		from := fs.String("from", "", "wallet created from a mnemonic (must)")
+		name := fs.String("name", "", "name of the new account (must)")
+		index := fs.Uint("index", 1, "account index derived from the mnemonic")
+		fs.Parse(os.Args[2:])
+
+		if *from == "" || *name == "" {
+			fmt.Println("❌ You must provide --from and --name")
+			return
+		}
+
+		deriveAccount(*from, *name, uint32(*index))
 
-		createUser(*name)
+	case "migrate-wallets":
+		fs := flag.NewFlagSet("migrate-wallets", flag.ExitOnError)
+		dir := fs.String("dir", "wallets", "directory of the wallet files")
+		fs.Parse(os.Args[2:])
+
+		migrateWallets(*dir)
 
 	default:
-		fmt.Println("Command Invalid. Use: cli create-user --name alice")
+		fmt.Println("Command Invalid. Use: cli create-user --name alice [--words 12] [--scheme p256] | cli restore-from-mnemonic --name alice [--index 0] [--scheme p256] | cli derive-account --from alice --name alice2 --index 1 | cli migrate-wallets [--dir wallets]")
 	}
 }
 
-func createUser(name string) {
-	relPath := filepath.Join("wallets", name+".json")
+func walletPath(name string) string {
+	return filepath.Join("wallets", name+".json")
+}
+
+// createUser creates a wallet from a new mnemonic at account index 0. The
+// mnemonic is printed once: it is the backup of every account derived from
+// it.
+func createUser(name string, words int, scheme cryptohelper.SchemeID) {
+	relPath := walletPath(name)
 	absPath, _ := filepath.Abs(relPath)
 
 	if wallet.WalletExists(relPath) {
@@ -41,20 +96,137 @@ func createUser(name string) {
 		return
 	}
 
-	w, err := wallet.CreateWallet()
+	mnemonic, err := wallet.NewMnemonic(words)
+	if err != nil {
+		fmt.Println("❌ Error creating mnemonic:", err)
+		return
+	}
+	w, err := wallet.DeriveWallet(scheme, mnemonic, wallet.AccountPath(scheme, 0))
 	if err != nil {
 		fmt.Println("❌ Error creating account:", err)
 		return
 	}
 
-	err = w.SaveToFile(relPath)
+	// Passphrase lấy từ WALLET_PASSPHRASE, WALLET_PASSPHRASE_FILE hoặc nhập từ terminal
+	passphrase, err := wallet.ReadPassphrase("New passphrase: ", true)
 	if err != nil {
-		fmt.Println("❌ Error saving account:", err)
+		fmt.Println("❌ Error reading passphrase:", err)
+		return
+	}
+
+	if !saveAccount(name, w, passphrase) {
+		return
+	}
+	fmt.Println("📝 Mnemonic (write it down, it restores this account on any machine):")
+	fmt.Printf("   %s\n", mnemonic)
+}
+
+// restoreFromMnemonic recreates the account of scheme at index of a
+// mnemonic read from WALLET_MNEMONIC or the terminal.
+func restoreFromMnemonic(name string, index uint32, scheme cryptohelper.SchemeID) {
+	if wallet.WalletExists(walletPath(name)) {
+		fmt.Printf("❌ Account '%s' already exists\n", name)
+		return
+	}
+
+	mnemonic, err := wallet.ReadMnemonic("Mnemonic: ")
+	if err != nil {
+		fmt.Println("❌ Error reading mnemonic:", err)
+		return
+	}
+	w, err := wallet.DeriveWallet(scheme, mnemonic, wallet.AccountPath(scheme, index))
+	if err != nil {
+		fmt.Println("❌ Error restoring account:", err)
+		return
+	}
+
+	passphrase, err := wallet.ReadPassphrase("New passphrase: ", true)
+	if err != nil {
+		fmt.Println("❌ Error reading passphrase:", err)
+		return
+	}
+	saveAccount(name, w, passphrase)
+}
+
+// deriveAccount derives the account at index from the mnemonic stored in
+// the wallet from, with its scheme, and saves it with the same passphrase.
+func deriveAccount(from, name string, index uint32) {
+	if wallet.WalletExists(walletPath(name)) {
+		fmt.Printf("❌ Account '%s' already exists\n", name)
+		return
+	}
+
+	passphrase, err := wallet.ReadPassphrase(fmt.Sprintf("Passphrase for %s: ", walletPath(from)), false)
+	if err != nil {
+		fmt.Println("❌ Error reading passphrase:", err)
+		return
+	}
+	parent, err := wallet.LoadWalletWithPassphrase(walletPath(from), passphrase)
+	if err != nil {
+		fmt.Printf("❌ Error loading %s: %v\n", from, err)
+		return
+	}
+	if parent.Mnemonic == "" {
+		fmt.Printf("❌ Wallet '%s' was not created from a mnemonic\n", from)
+		return
+	}
+
+	w, err := wallet.DeriveWallet(parent.Scheme(), parent.Mnemonic, wallet.AccountPath(parent.Scheme(), index))
+	if err != nil {
+		fmt.Println("❌ Error deriving account:", err)
 		return
 	}
+	saveAccount(name, w, passphrase)
+}
+
+func saveAccount(name string, w *wallet.Wallet, passphrase string) bool {
+	relPath := walletPath(name)
+	absPath, _ := filepath.Abs(relPath)
+	if err := w.SaveToFile(relPath, passphrase); err != nil {
+		fmt.Println("❌ Error saving account:", err)
+		return false
+	}
 
 	fmt.Println("✅ Account saved successfully!")
 	fmt.Printf("👤 Name: %s\n", name)
 	fmt.Printf("🏦 Address: %s\n", w.Address)
+	fmt.Printf("🔏 Scheme: %s\n", w.Scheme())
+	fmt.Printf("🧭 Path: %s\n", w.Path)
 	fmt.Printf("📁 File saved at: %s\n", absPath)
+	return true
+}
+
+// migrateWallets encrypts every plaintext wallet in dir with one passphrase.
+// Wallets that are already encrypted are left as they are.
+func migrateWallets(dir string) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil || len(files) == 0 {
+		fmt.Printf("❌ No wallet files found in %s\n", dir)
+		return
+	}
+
+	passphrase, err := wallet.ReadPassphrase("Passphrase for the migrated wallets: ", true)
+	if err != nil {
+		fmt.Println("❌ Error reading passphrase:", err)
+		return
+	}
+
+	migrated, failed := 0, 0
+	for _, file := range files {
+		ok, err := wallet.MigrateFile(file, passphrase)
+		switch {
+		case err != nil:
+			fmt.Printf("❌ %s: %v\n", file, err)
+			failed++
+		case ok:
+			fmt.Printf("🔐 %s encrypted\n", file)
+			migrated++
+		default:
+			fmt.Printf("✅ %s already encrypted\n", file)
+		}
+	}
+	fmt.Printf("Migrated %d of %d wallet(s), %d failed\n", migrated, len(files), failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"blockchain-go/pkg/blockchain"
+	"blockchain-go/pkg/cryptohelper"
+	"blockchain-go/pkg/merkle"
+	"blockchain-go/pkg/mpt"
+	"blockchain-go/proto/nodepb"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const usage = `Usage: explorer <command> [flags]
+
+Commands:
+  block   --height N | --hash HEX | --latest   print a block with its transactions
+  header  --height N | --hash HEX | --latest   print only the block header
+  txs     --height N | --hash HEX | --latest   print the transactions of a block
+  proof   --height N (--tx HEX | --index I)    fetch a transaction's inclusion proof and verify
+                                               it against the block header
+
+Common flags:
+  --addr    node address (default localhost:50051)
+  --format  table | json (default table)`
+
+type options struct {
+	addr   string
+	format string
+	height int64
+	hash   string
+	latest bool
+	tx     string
+	index  int
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		return
+	}
+
+	cmd := os.Args[1]
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	opts := options{}
+	fs.StringVar(&opts.addr, "addr", "localhost:50051", "node address")
+	fs.StringVar(&opts.format, "format", "table", "output format: table or json")
+	fs.Int64Var(&opts.height, "height", -1, "block height")
+	fs.StringVar(&opts.hash, "hash", "", "block hash (hex)")
+	fs.BoolVar(&opts.latest, "latest", false, "use the latest block")
+	fs.StringVar(&opts.tx, "tx", "", "transaction hash (hex), for proof")
+	fs.IntVar(&opts.index, "index", 0, "transaction position in the block, for proof")
+	fs.Parse(os.Args[2:])
+
+	if opts.format != "table" && opts.format != "json" {
+		log.Fatalf("❌ Unknown format %q. Use table or json", opts.format)
+	}
+	if opts.height < 0 && opts.hash == "" && !opts.latest {
+		log.Fatal("❌ You must provide --height, --hash or --latest")
+	}
+
+	conn, err := grpc.NewClient(opts.addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	client := nodepb.NewNodeServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	switch cmd {
+	case "block":
+		block, err := fetchBlock(ctx, client, opts)
+		if err != nil {
+			log.Fatalf("❌ Could not get block: %v", err)
+		}
+		if opts.format == "json" {
+			printJSON(toBlockView(block))
+			return
+		}
+		printHeaderTable(headerOf(block))
+		fmt.Println()
+		printTxTable(block.Transactions)
+
+	case "header":
+		header, err := fetchHeader(ctx, client, opts)
+		if err != nil {
+			log.Fatalf("❌ Could not get block header: %v", err)
+		}
+		if opts.format == "json" {
+			printJSON(toHeaderView(header))
+			return
+		}
+		printHeaderTable(header)
+
+	case "txs":
+		block, err := fetchBlock(ctx, client, opts)
+		if err != nil {
+			log.Fatalf("❌ Could not get block: %v", err)
+		}
+		if opts.format == "json" {
+			printJSON(toBlockView(block).Transactions)
+			return
+		}
+		printTxTable(block.Transactions)
+
+	case "proof":
+		if opts.height < 0 {
+			log.Fatal("❌ proof needs --height")
+		}
+		if err := proveTx(ctx, client, opts); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+
+	default:
+		fmt.Println(usage)
+	}
+}
+
+// proveTx fetches the proof of a transaction and checks it locally: the
+// header must hash to its block hash, and the proof must lead from the
+// header's Merkle root to the transaction's hash.
+func proveTx(ctx context.Context, client nodepb.NodeServiceClient, opts options) error {
+	req := &nodepb.TransactionProofRequest{Height: opts.height, Index: int32(opts.index)}
+	if opts.tx != "" {
+		hash, err := hex.DecodeString(opts.tx)
+		if err != nil {
+			return fmt.Errorf("invalid transaction hash: %w", err)
+		}
+		req.TxHash = hash
+	}
+	res, err := client.GetTransactionProof(ctx, req)
+	if err != nil {
+		return fmt.Errorf("could not get proof: %w", err)
+	}
+	header, err := client.GetBlockHeader(ctx, &nodepb.BlockRequest{Height: opts.height})
+	if err != nil {
+		return fmt.Errorf("could not get block header: %w", err)
+	}
+	// Kiểu tx root và số giao dịch lấy từ header, không tin vào phản hồi
+	rootType := blockchain.TxRootType(header.TxRootType)
+	proofItems, itemName := proofSize(rootType, res.Proof)
+
+	headerOK := bytes.Equal(blockchain.ProtoToHeader(header).Hash(), header.CurrentBlockHash) &&
+		bytes.Equal(header.MerkleRoot, res.MerkleRoot)
+	// Phản hồi không có giao dịch thì không kiểm tra được hash của nó
+	hashOK := false
+	var txJSON *txView
+	if res.Transaction != nil {
+		hashOK = bytes.Equal(blockchain.ProtoToTransaction(res.Transaction).Hash(), res.TxHash)
+		view := toTxView(res.Transaction)
+		txJSON = &view
+	}
+	proofOK := blockchain.VerifyTransactionProof(rootType, header.MerkleRoot, res.TxHash, int(res.Index), int(header.TxCount), res.Proof)
+	verified := headerOK && hashOK && proofOK
+
+	if opts.format == "json" {
+		printJSON(proofView{
+			Height:      res.Height,
+			BlockHash:   hex.EncodeToString(res.BlockHash),
+			MerkleRoot:  hex.EncodeToString(res.MerkleRoot),
+			TxRoot:      rootType.String(),
+			Index:       res.Index,
+			TxHash:      hex.EncodeToString(res.TxHash),
+			Proof:       hex.EncodeToString(res.Proof),
+			ProofItems:  proofItems,
+			Verified:    verified,
+			Transaction: txJSON,
+		})
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "Block\t%d (%x)\n", res.Height, res.BlockHash)
+		fmt.Fprintf(w, "Merkle Root\t%x (%s)\n", res.MerkleRoot, rootType)
+		fmt.Fprintf(w, "Transaction\t#%d of %d, %x\n", res.Index, header.TxCount, res.TxHash)
+		fmt.Fprintf(w, "Proof\t%d %s, %d bytes\n", proofItems, itemName, len(res.Proof))
+		fmt.Fprintf(w, "Header\t%s\n", check(headerOK))
+		if res.Transaction == nil {
+			fmt.Fprintf(w, "Tx hash\t❌ the node sent no transaction\n")
+		} else {
+			fmt.Fprintf(w, "Tx hash\t%s\n", check(hashOK))
+		}
+		fmt.Fprintf(w, "Inclusion\t%s\n", check(proofOK))
+		w.Flush()
+	}
+	if !verified {
+		return fmt.Errorf("proof does not verify")
+	}
+	return nil
+}
+
+// proofSize returns the number of items in an encoded proof and what they
+// are: trie nodes for an MPT root, sibling hashes for a binary one.
+func proofSize(rootType blockchain.TxRootType, proof []byte) (int, string) {
+	if rootType == blockchain.TxRootBinary {
+		return len(proof) / merkle.HashSize, "hashes"
+	}
+	nodes, err := mpt.DecodeProof(proof)
+	if err != nil {
+		return 0, "nodes"
+	}
+	return len(nodes), "nodes"
+}
+
+func check(ok bool) string {
+	if ok {
+		return "✅ valid"
+	}
+	return "❌ invalid"
+}
+
+func fetchBlock(ctx context.Context, client nodepb.NodeServiceClient, opts options) (*nodepb.Block, error) {
+	switch {
+	case opts.latest:
+		return client.GetLatestBlock(ctx, &nodepb.Empty{})
+	case opts.hash != "":
+		hash, err := hex.DecodeString(opts.hash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hash: %w", err)
+		}
+		return client.GetBlockByHash(ctx, &nodepb.BlockHashRequest{Hash: hash})
+	default:
+		return client.GetBlock(ctx, &nodepb.BlockRequest{Height: opts.height})
+	}
+}
+
+func fetchHeader(ctx context.Context, client nodepb.NodeServiceClient, opts options) (*nodepb.BlockHeader, error) {
+	switch {
+	case opts.latest:
+		return client.GetLatestBlockHeader(ctx, &nodepb.Empty{})
+	case opts.hash != "":
+		hash, err := hex.DecodeString(opts.hash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hash: %w", err)
+		}
+		return client.GetBlockHeaderByHash(ctx, &nodepb.BlockHashRequest{Hash: hash})
+	default:
+		return client.GetBlockHeader(ctx, &nodepb.BlockRequest{Height: opts.height})
+	}
+}
+
+func headerOf(b *nodepb.Block) *nodepb.BlockHeader {
+	return &nodepb.BlockHeader{
+		Height:            b.Height,
+		MerkleRoot:        b.MerkleRoot,
+		TxRootType:        b.TxRootType,
+		PreviousBlockHash: b.PreviousBlockHash,
+		CurrentBlockHash:  b.CurrentBlockHash,
+		Timestamp:         b.Timestamp,
+		TxCount:           int32(len(b.Transactions)),
+		StateRoot:         b.StateRoot,
+		Proposer:          b.Proposer,
+		ChainId:           b.ChainId,
+		ConfigHash:        b.ConfigHash,
+	}
+}
+
+// === Output ===
+
+type headerView struct {
+	Height            int64  `json:"height"`
+	Hash              string `json:"hash"`
+	PreviousBlockHash string `json:"previous_block_hash"`
+	MerkleRoot        string `json:"merkle_root"`
+	TxRoot            string `json:"tx_root"`
+	StateRoot         string `json:"state_root"`
+	Proposer          string `json:"proposer"`
+	ChainID           string `json:"chain_id,omitempty"`
+	ConfigHash        string `json:"config_hash,omitempty"`
+	Timestamp         int64  `json:"timestamp"`
+	TxCount           int32  `json:"tx_count"`
+}
+
+type txView struct {
+	Sender    string  `json:"sender"`
+	Receiver  string  `json:"receiver"`
+	Amount    float64 `json:"amount"`
+	Timestamp int64   `json:"timestamp"`
+	Signature string  `json:"signature"`
+	Scheme    string  `json:"scheme"`
+}
+
+type proofView struct {
+	Height      int64   `json:"height"`
+	BlockHash   string  `json:"block_hash"`
+	MerkleRoot  string  `json:"merkle_root"`
+	TxRoot      string  `json:"tx_root"`
+	Index       int32   `json:"index"`
+	TxHash      string  `json:"tx_hash"`
+	Proof       string  `json:"proof"`
+	ProofItems  int     `json:"proof_items"`
+	Verified    bool    `json:"verified"`
+	Transaction *txView `json:"transaction,omitempty"`
+}
+
+type blockView struct {
+	headerView
+	Transactions []txView `json:"transactions"`
+}
+
+func toHeaderView(h *nodepb.BlockHeader) headerView {
+	return headerView{
+		Height:            h.Height,
+		Hash:              hex.EncodeToString(h.CurrentBlockHash),
+		PreviousBlockHash: hex.EncodeToString(h.PreviousBlockHash),
+		MerkleRoot:        hex.EncodeToString(h.MerkleRoot),
+		TxRoot:            blockchain.TxRootType(h.TxRootType).String(),
+		StateRoot:         hex.EncodeToString(h.StateRoot),
+		Proposer:          h.Proposer,
+		ChainID:           h.ChainId,
+		ConfigHash:        hex.EncodeToString(h.ConfigHash),
+		Timestamp:         h.Timestamp,
+		TxCount:           h.TxCount,
+	}
+}
+
+func toBlockView(b *nodepb.Block) blockView {
+	view := blockView{headerView: toHeaderView(headerOf(b)), Transactions: []txView{}}
+	for _, tx := range b.Transactions {
+		view.Transactions = append(view.Transactions, toTxView(tx))
+	}
+	return view
+}
+
+func toTxView(tx *nodepb.Transaction) txView {
+	return txView{
+		Sender:    addressString(tx.Sender),
+		Receiver:  hex.EncodeToString(tx.Receiver),
+		Amount:    tx.Amount,
+		Timestamp: tx.Timestamp,
+		Signature: hex.EncodeToString(tx.Signature),
+		Scheme:    cryptohelper.SchemeID(tx.SignatureScheme).String(),
+	}
+}
+
+// addressString prints the GENESIS pseudo-sender as text instead of hex.
+func addressString(addr []byte) string {
+	if string(addr) == "GENESIS" {
+		return "GENESIS"
+	}
+	return hex.EncodeToString(addr)
+}
+
+func printJSON(v interface{}) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		log.Fatalf("❌ Failed to encode output: %v", err)
+	}
+	fmt.Println(string(data))
+}
+
+func printHeaderTable(h *nodepb.BlockHeader) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Height\t%d\n", h.Height)
+	fmt.Fprintf(w, "Hash\t%x\n", h.CurrentBlockHash)
+	fmt.Fprintf(w, "Previous\t%x\n", h.PreviousBlockHash)
+	fmt.Fprintf(w, "Merkle Root\t%x (%s)\n", h.MerkleRoot, blockchain.TxRootType(h.TxRootType))
+	fmt.Fprintf(w, "State Root\t%x\n", h.StateRoot)
+	fmt.Fprintf(w, "Proposer\t%s\n", h.Proposer)
+	if h.ChainId != "" {
+		fmt.Fprintf(w, "Chain ID\t%s\n", h.ChainId)
+	}
+	if len(h.ConfigHash) > 0 {
+		fmt.Fprintf(w, "Config Hash\t%x\n", h.ConfigHash)
+	}
+	fmt.Fprintf(w, "Time\t%s\n", time.Unix(h.Timestamp, 0).UTC().Format(time.RFC3339))
+	fmt.Fprintf(w, "Transactions\t%d\n", h.TxCount)
+	w.Flush()
+}
+
+func printTxTable(txs []*nodepb.Transaction) {
+	if len(txs) == 0 {
+		fmt.Println("(no transactions)")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tFROM\tTO\tAMOUNT\tTIME")
+	for i, tx := range txs {
+		fmt.Fprintf(w, "%d\t%s\t%x\t%f\t%s\n",
+			i, addressString(tx.Sender), tx.Receiver, tx.Amount,
+			time.Unix(tx.Timestamp, 0).UTC().Format(time.RFC3339))
+	}
+	w.Flush()
+}
@@ -51,6 +51,12 @@ func main() {
 	defer conn.Close()
 	client := nodepb.NewNodeServiceClient(conn)
 
+	// Hỏi node chain ID để ký giao dịch cho đúng mạng
+	info, err := client.Handshake(context.Background(), &nodepb.HandshakeRequest{})
+	if err != nil {
+		log.Fatalf("Handshake failed: %v", err)
+	}
+
 	// 5. Tạo, ký và gửi giao dịch
 	log.Printf("🚀 Preparing to send %.2f coins to %s", amount, *recipientAddr)
 	tx := &blockchain.Transaction{
@@ -58,16 +64,14 @@ func main() {
 		Receiver:  receiverAddrBytes,
 		Amount:    amount,
 		Timestamp: time.Now().Unix(),
+		ChainID:   info.ChainId,
 	}
 
-	if err := wallet.SignTransaction(tx, faucetWallet.PrivateKey); err != nil {
+	if err := faucetWallet.SignTransaction(tx); err != nil {
 		log.Fatalf("Failed to sign transaction: %v", err)
 	}
 
-	txProto := &nodepb.Transaction{
-		Sender: tx.Sender, Receiver: tx.Receiver, Amount: tx.Amount,
-		Timestamp: tx.Timestamp, Signature: tx.Signature, PublicKey: tx.PublicKey,
-	}
+	txProto := blockchain.TransactionToProto(tx)
 
 	res, err := client.SendTransaction(context.Background(), txProto)
 	if err != nil {
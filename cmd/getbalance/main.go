@@ -12,6 +12,7 @@ import (
 
 func main() {
 	address := flag.String("address", "", "The address to check the balance of")
+	height := flag.Int64("height", -1, "Balance right after this block (default: latest)")
 	flag.Parse()
 
 	if *address == "" {
@@ -29,13 +30,20 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
 
-	res, err := client.GetBalance(ctx, &nodepb.GetBalanceRequest{Address: *address})
+	req := &nodepb.GetBalanceRequest{Address: *address}
+	if *height >= 0 {
+		req.Height = height
+	}
+	res, err := client.GetBalance(ctx, req)
 	if err != nil {
 		log.Fatalf("❌ Could not get balance: %v", err)
 	}
 
 	fmt.Println("--- Account Balance ---")
 	fmt.Printf("🏦 Address: %s\n", res.Address)
+	if req.Height != nil {
+		fmt.Printf("⛓️ Height: %d\n", *req.Height)
+	}
 	fmt.Printf("💰 Balance: %f\n", res.Balance)
 	fmt.Println("-----------------------")
 }
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"blockchain-go/proto/nodepb"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// history lists the transactions sent or received by an address.
+//
+//	go run ./cmd/history --address <hex> [--direction sent] [--from 10 --to 20] [--all]
+
+type entryView struct {
+	Height    int64   `json:"height"`
+	Index     int32   `json:"index"`
+	Direction string  `json:"direction"`
+	TxHash    string  `json:"tx_hash"`
+	Sender    string  `json:"sender"`
+	Receiver  string  `json:"receiver"`
+	Amount    float64 `json:"amount"`
+	Timestamp int64   `json:"timestamp"`
+	// Pruned is set when the block body was pruned and only the position of
+	// the transaction is known.
+	Pruned bool `json:"pruned,omitempty"`
+}
+
+type pageView struct {
+	Entries    []entryView `json:"entries"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+func main() {
+	addr := flag.String("addr", "localhost:50051", "node address")
+	address := flag.String("address", "", "account address (hex)")
+	direction := flag.String("direction", "all", "all, sent or received")
+	from := flag.Int64("from", 0, "first block height")
+	to := flag.Int64("to", -1, "last block height (default: latest)")
+	limit := flag.Int("limit", 50, "transactions per page")
+	cursor := flag.String("cursor", "", "cursor returned by the previous page (hex)")
+	all := flag.Bool("all", false, "follow cursors and print every page")
+	format := flag.String("format", "table", "output format: table or json")
+	flag.Parse()
+
+	if *address == "" {
+		log.Fatal("❌ You must provide --address")
+	}
+	if *format != "table" && *format != "json" {
+		log.Fatalf("❌ Unknown format %q. Use table or json", *format)
+	}
+	req := &nodepb.AccountHistoryRequest{Address: *address, FromHeight: *from, Limit: int32(*limit)}
+	switch *direction {
+	case "all":
+		req.Direction = nodepb.TxDirection_BOTH
+	case "sent":
+		req.Direction = nodepb.TxDirection_SENT
+	case "received":
+		req.Direction = nodepb.TxDirection_RECEIVED
+	default:
+		log.Fatalf("❌ Unknown direction %q. Use all, sent or received", *direction)
+	}
+	if *to >= 0 {
+		req.ToHeight = to
+	}
+	if *cursor != "" {
+		c, err := hex.DecodeString(*cursor)
+		if err != nil {
+			log.Fatalf("❌ Invalid cursor: %v", err)
+		}
+		req.Cursor = c
+	}
+
+	conn, err := grpc.NewClient(*addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	client := nodepb.NewNodeServiceClient(conn)
+
+	view := pageView{Entries: []entryView{}}
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		page, err := client.GetAccountHistory(ctx, req)
+		cancel()
+		if err != nil {
+			log.Fatalf("❌ Could not get account history: %v", err)
+		}
+		for _, e := range page.Entries {
+			view.Entries = append(view.Entries, toEntryView(e))
+		}
+		view.NextCursor = hex.EncodeToString(page.NextCursor)
+		if !*all || !page.HasMore {
+			break
+		}
+		req.Cursor = page.NextCursor
+	}
+
+	if *format == "json" {
+		data, err := json.MarshalIndent(view, "", "  ")
+		if err != nil {
+			log.Fatalf("❌ Failed to encode output: %v", err)
+		}
+		fmt.Println(string(data))
+		return
+	}
+	printTable(view)
+}
+
+func toEntryView(e *nodepb.AccountTx) entryView {
+	dir := "received"
+	if e.Direction == nodepb.TxDirection_SENT {
+		dir = "sent"
+	}
+	view := entryView{Height: e.Height, Index: e.Index, Direction: dir}
+	tx := e.GetTransaction()
+	if tx == nil {
+		view.Pruned = true
+		return view
+	}
+	view.TxHash = hex.EncodeToString(e.TxHash)
+	view.Sender = addressString(tx.Sender)
+	view.Receiver = hex.EncodeToString(tx.Receiver)
+	view.Amount = tx.Amount
+	view.Timestamp = tx.Timestamp
+	return view
+}
+
+// addressString prints the GENESIS pseudo-sender as text instead of hex.
+func addressString(addr []byte) string {
+	if string(addr) == "GENESIS" {
+		return "GENESIS"
+	}
+	return hex.EncodeToString(addr)
+}
+
+func printTable(view pageView) {
+	if len(view.Entries) == 0 {
+		fmt.Println("(no transactions)")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "HEIGHT\t#\tDIR\tFROM\tTO\tAMOUNT\tTIME")
+	for _, e := range view.Entries {
+		if e.Pruned {
+			fmt.Fprintf(w, "%d\t%d\t%s\t(pruned)\t\t\t\n", e.Height, e.Index, e.Direction)
+			continue
+		}
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\t%f\t%s\n",
+			e.Height, e.Index, e.Direction, e.Sender, e.Receiver, e.Amount,
+			time.Unix(e.Timestamp, 0).UTC().Format(time.RFC3339))
+	}
+	w.Flush()
+	if view.NextCursor != "" {
+		fmt.Printf("\nMore transactions: --cursor %s\n", view.NextCursor)
+	}
+}
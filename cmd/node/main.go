@@ -3,21 +3,26 @@ package main
 import (
 	"blockchain-go/pkg/blockchain"
 	"blockchain-go/pkg/consensus"
+	"blockchain-go/pkg/genesis"
 	"blockchain-go/pkg/p2p_v2"
+	"blockchain-go/pkg/pruning"
+	"blockchain-go/pkg/snapshot"
 	"blockchain-go/pkg/state"
 	"blockchain-go/pkg/storage"
 	"blockchain-go/proto/nodepb"
-	"encoding/json"
+	"bytes"
 	"errors"
 
 	"context"
 	"log"
 	"net"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
-	"github.com/syndtr/goleveldb/leveldb"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -25,6 +30,10 @@ import (
 func main() {
 	// === Cấu hình từ biến môi trường ===
 	nodeID := os.Getenv("NODE_ID")
+	if !utf8.ValidString(nodeID) {
+		// NODE_ID là Proposer trong header, protobuf không mã hoá được string sai UTF-8
+		log.Fatalf("❌ NODE_ID %q is not valid UTF-8", nodeID)
+	}
 	leaderAddr := os.Getenv("LEADER_ADDR")
 	if leaderAddr == "" {
 		log.Fatal("❌ LEADER_ADDR is not set")
@@ -39,39 +48,76 @@ func main() {
 
 	totalNodes := len(peerAddrs) + 1
 
+	// GENESIS_FILE: chain ID, validators, params and alloc (default genesis.json)
+	genesisPath := os.Getenv("GENESIS_FILE")
+	if genesisPath == "" {
+		genesisPath = "genesis.json"
+	}
+
+	// SYNC_MODE=fast lets a new follower restore state from a snapshot
+	// instead of replaying every block (default: full).
+	fastSync := strings.ToLower(os.Getenv("SYNC_MODE")) == "fast"
+	snapshotInterval := envInt("SNAPSHOT_INTERVAL", 100)
+	snapshotKeep := envInt("SNAPSHOT_KEEP", 2)
+
+	// PRUNE_MODE=archive|full|pruned, PRUNE_RETENTION=<blocks>
+	pruneMode, err := pruning.ParseMode(os.Getenv("PRUNE_MODE"))
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	pruneRetention := envInt("PRUNE_RETENTION", pruning.DefaultRetention)
+
+	// BLOCK_CACHE_SIZE, HEADER_CACHE_SIZE, ACCOUNT_CACHE_SIZE in entries (0 disables)
+	blockCacheSize := envInt("BLOCK_CACHE_SIZE", storage.DefaultBlockCacheSize)
+	headerCacheSize := envInt("HEADER_CACHE_SIZE", storage.DefaultHeaderCacheSize)
+	accountCacheSize := envInt("ACCOUNT_CACHE_SIZE", state.DefaultAccountCacheSize)
+
+	// DB_ENGINE=leveldb|bolt|memory (memory loses everything on restart)
+	dbEngine, err := storage.ParseEngine(os.Getenv("DB_ENGINE"))
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
 	// === Khởi tạo DB ===
 	dbPath := "data/" + nodeID
 	if err := os.MkdirAll(dbPath, os.ModePerm); err != nil {
 		log.Fatalf("❌ Failed to create data directory: %v", err)
 	}
-	db, err := storage.OpenDB(dbPath)
+	db, err := storage.OpenDBWithEngine(dbEngine, dbPath)
 	if err != nil {
 		log.Fatalf("❌ Failed to open DB: %v", err)
 	}
+	log.Printf("💾 Node %s: using %s storage at %s", nodeID, dbEngine, dbPath)
+	db.SetCacheSizes(blockCacheSize, headerCacheSize)
 	defer db.Close()
 
 	// =============
 
 	// === APPLY GENESIS BLOCK ===
+	// Genesis block được dựng lại từ genesis.json mỗi lần khởi động; DB đã có
+	// dữ liệu phải có cùng genesis hash.
+	chain, err := genesis.Load(genesisPath)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	genesisBlock := chain.Block()
+	log.Printf("🌐 Chain %s, genesis %x", chain.ChainID, genesisBlock.CurrentBlockHash)
+	if !chain.IsValidator(nodeID) {
+		log.Printf("⚠️ Node %s is not one of the genesis validators", nodeID)
+	}
 	_, err = db.GetLatestBlock()
 	if err != nil {
-		if errors.Is(err, leveldb.ErrNotFound) {
+		if errors.Is(err, storage.ErrNotFound) {
 			log.Printf("🌱 Node %s: Database is empty. Loading genesis block...", nodeID)
-			genesisData, err := os.ReadFile("genesis.dat")
-			if err != nil {
-				log.Fatalf("❌ Could not read genesis.dat: %v.", err)
-			}
-			var genesisBlock blockchain.Block
-			if err := json.Unmarshal(genesisData, &genesisBlock); err != nil {
-				log.Fatalf("❌ Failed to parse genesis block: %v", err)
-			}
-			if err := db.SaveBlock(&genesisBlock); err != nil {
+			if err := db.SaveBlock(genesisBlock); err != nil {
 				log.Fatalf("❌ Failed to save genesis block to DB: %v", err)
 			}
 			log.Printf("✅ Node %s: Genesis block loaded and saved to DB.", nodeID)
 		} else {
 			log.Fatalf("❌ Error checking for latest block: %v", err)
 		}
+	} else if stored, _, err := db.GetHeaderByHeight(0); err == nil && !bytes.Equal(stored.CurrentBlockHash, genesisBlock.CurrentBlockHash) {
+		log.Fatalf("❌ The database belongs to another chain (genesis %x, %s gives %x)", stored.CurrentBlockHash, genesisPath, genesisBlock.CurrentBlockHash)
 	}
 
 	// === Khởi tạo State Manager ===
@@ -79,6 +125,7 @@ func main() {
 	if err != nil {
 		log.Fatalf("❌ Failed to initialize state manager: %v", err)
 	}
+	stateManager.SetCacheSize(accountCacheSize)
 
 	// === Xây dựng lại trạng thái từ blockchain ===
 	if err := stateManager.RebuildStateFromBlockchain(); err != nil {
@@ -93,21 +140,58 @@ func main() {
 		log.Println("🌱 Starting with genesis block")
 	}
 
+	// Danh sách validator trong genesis quyết định số phiếu cần để commit
+	if len(chain.Validators) > 0 {
+		totalNodes = len(chain.Validators)
+	}
+
 	networkAdapter := p2p_v2.NewGrpcAdapter(leaderAddr, peerAddrs)
 
 	consensusManager := consensus.NewManager(nodeID, totalNodes, db, stateManager, latestBlock, networkAdapter)
+	consensusManager.IsValidator = chain.IsValidator
+
+	snapshots := snapshot.NewStore(db, int64(snapshotInterval), snapshotKeep)
+	pruner := pruning.NewPruner(pruneMode, int64(pruneRetention), db, stateManager)
+	log.Printf("🗄️ Node mode: %s (retention %d blocks)", pruner.Mode, pruner.Retention)
+	consensusManager.OnCommit = func(block *blockchain.Block) {
+		snapshots.MaybeCreate(stateManager, block)
+		pruner.MaybePrune(block.Height)
+		if block.Height%cacheStatsEvery == 0 {
+			logCacheStats(db, stateManager)
+		}
+	}
+
+	// === Consensus journal ===
+	// Vote, proposal và quyết định commit được ghi xuống đĩa trước khi gửi đi,
+	// để sau khi restart node không vote hai lần hay mất block đã đồng thuận.
+	if dbEngine != storage.Memory {
+		journal, err := consensus.OpenJournal(filepath.Join(dbPath, "consensus.wal"))
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		defer journal.Close()
+		if err := consensusManager.RecoverFromJournal(journal); err != nil {
+			log.Fatalf("❌ Failed to recover from the consensus journal: %v", err)
+		}
+	}
 
 	// === Tạo server node ===
 	server := &p2p_v2.NodeServer{
-		NodeID:     nodeID,
-		IsLeader:   isLeader,
-		Consensus:  consensusManager,
-		State:      stateManager,
-		PendingTxs: []*blockchain.Transaction{},
+		NodeID:    nodeID,
+		IsLeader:  isLeader,
+		Consensus: consensusManager,
+		State:     stateManager,
+		Snapshots: snapshots,
+
+		ChainID:       chain.ChainID,
+		GenesisHash:   genesisBlock.CurrentBlockHash,
+		MaxBlockTxs:   chain.Params.MaxBlockTxs,
+		BlockInterval: chain.Params.BlockInterval(),
 	}
 
 	if !isLeader {
-		syncFromLeader(leaderAddr, db, stateManager, consensusManager)
+		handshake := &nodepb.HandshakeRequest{NodeId: nodeID, ChainId: chain.ChainID, GenesisHash: genesisBlock.CurrentBlockHash}
+		syncFromLeader(leaderAddr, handshake, db, stateManager, consensusManager, fastSync)
 
 		latestBlock, err = db.GetLatestBlock()
 		if err != nil {
@@ -126,12 +210,15 @@ func main() {
 	nodepb.RegisterNodeServiceServer(grpcServer, server)
 
 	log.Printf("🚀 Node %s started on :50051", nodeID)
+	if isLeader {
+		go consensusManager.ResumeProposal()
+	}
 	if err := grpcServer.Serve(listener); err != nil {
 		log.Fatalf("❌ gRPC server error: %v", err)
 	}
 }
 
-func syncFromLeader(leaderAddr string, db *storage.DB, _ *state.State, consensusManager *consensus.Manager) {
+func syncFromLeader(leaderAddr string, handshake *nodepb.HandshakeRequest, db *storage.DB, stateManager *state.State, consensusManager *consensus.Manager, fastSync bool) {
 	log.Println("🔄 Syncing blocks from leader...")
 	var latestBlock, _ = db.GetLatestBlock()
 
@@ -161,24 +248,64 @@ func syncFromLeader(leaderAddr string, db *storage.DB, _ *state.State, consensus
 	defer conn.Close()
 
 	client := nodepb.NewNodeServiceClient(conn)
-	res, err := client.GetBlockFromHeight(context.Background(), &nodepb.HeightRequest{FromHeight: int64(startHeight)})
+
+	// Không đồng bộ với leader chạy chain khác
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	leader, err := p2p_v2.Handshake(ctx, client, handshake)
+	cancel()
 	if err != nil {
-		log.Fatalf("❌ Sync failed during GetBlockFromHeight: %v", err)
+		log.Fatalf("❌ Handshake with leader %s failed: %v", leaderAddr, err)
+	}
+	log.Printf("🤝 Leader %s runs chain %s at height %d", leader.NodeId, leader.ChainId, leader.Height)
+
+	if fastSync {
+		block, err := p2p_v2.FastSync(context.Background(), leaderAddr, client, db, stateManager)
+		switch {
+		case err == nil:
+			consensusManager.LatestBlock = block
+			startHeight = int(block.Height) + 1
+		case errors.Is(err, p2p_v2.ErrNoSnapshot):
+			log.Println("ℹ️ No snapshot ahead of the local chain, falling back to full sync.")
+		default:
+			log.Fatalf("❌ Fast sync failed: %v", err)
+		}
 	}
 
-	if len(res.Blocks) == 0 {
+	// Header-first, paged sync: safe for chains of any length and resumable,
+	// because every block is committed as soon as its window is verified.
+	syncer := p2p_v2.NewSyncer(db, consensusManager.CommitBlock, p2p_v2.DefaultSyncOptions())
+	synced, err := syncer.SyncFrom(context.Background(), leaderAddr, client)
+	if err != nil {
+		log.Fatalf("❌ Sync from leader failed after %d blocks: %v", synced, err)
+	}
+
+	if synced == 0 {
 		log.Printf("✅ Sync done. Already at latest height %d.", startHeight-1)
 		return
 	}
+	log.Printf("✅ Sync done. Synced and committed %d blocks.", synced)
+}
 
-	log.Printf("⛓️  Received %d blocks from leader. Applying...", len(res.Blocks))
-	for _, pb := range res.Blocks {
-		block := blockchain.ProtoToBlock(pb)
-		// Sử dụng trực tiếp ConsensusManager để commit block,
-		// việc này đảm bảo tính nhất quán vì nó cũng xác thực lại block.
-		if err := consensusManager.CommitBlock(block); err != nil {
-			log.Fatalf("❌ Failed to commit synced block %d: %v", block.Height, err)
-		}
-		log.Printf("⛓️  Synced and committed block at height %d", block.Height)
+// cacheStatsEvery is how often, in blocks, the cache hit rates are logged.
+const cacheStatsEvery = 1000
+
+func logCacheStats(db *storage.DB, st *state.State) {
+	dbStats, accounts := db.CacheStats(), st.CacheStats()
+	log.Printf("📊 Cache hit rate: blocks %.0f%% (%d/%d), headers %.0f%% (%d/%d), accounts %.0f%% (%d/%d)",
+		100*dbStats.Blocks.HitRate(), dbStats.Blocks.Len, dbStats.Blocks.Capacity,
+		100*dbStats.Headers.HitRate(), dbStats.Headers.Len, dbStats.Headers.Capacity,
+		100*accounts.HitRate(), accounts.Len, accounts.Capacity)
+}
+
+// envInt reads an integer environment variable, falling back to def.
+func envInt(name string, def int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Fatalf("❌ %s must be a number, got %q", name, value)
 	}
+	return n
 }
@@ -0,0 +1,203 @@
+package main
+
+// So sánh tốc độ đọc block, header và số dư khi tắt và bật cache, với
+// workload giống cmd/client: alice liên tục chuyển tiền cho bob.
+//
+//	go run ./cmd/test/cache_bench --blocks 1000 --txs 10
+
+import (
+	"blockchain-go/pkg/blockchain"
+	"blockchain-go/pkg/state"
+	"blockchain-go/pkg/storage"
+	"blockchain-go/pkg/wallet"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+)
+
+// recentWindow is how far behind the tip the block reads go, like peers
+// catching up and explorers following the chain.
+const recentWindow = 128
+
+type result struct {
+	name       string
+	off, on    float64 // ops per second
+	onHitRate  float64
+	hasHitRate bool
+}
+
+func main() {
+	blocks := flag.Int("blocks", 1000, "number of blocks in the chain")
+	txCount := flag.Int("txs", 10, "transactions per block")
+	flag.Parse()
+
+	dir, err := os.MkdirTemp("", "cache-bench-")
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := storage.OpenDB(dir)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	defer db.Close()
+	st, _ := state.NewState(db)
+	alice, bob := buildChain(db, st, *blocks, *txCount)
+	fmt.Printf("Chain of %d blocks with %d transactions each\n\n", *blocks+1, *txCount)
+
+	var results []result
+	measure := func(name string, fn func(i int)) {
+		r := result{name: name}
+		for _, enabled := range []bool{false, true} {
+			if enabled {
+				db.SetCacheSizes(storage.DefaultBlockCacheSize, storage.DefaultHeaderCacheSize)
+				st.SetCacheSize(state.DefaultAccountCacheSize)
+			} else {
+				db.SetCacheSizes(0, 0)
+				st.SetCacheSize(0)
+			}
+			before := db.CacheStats()
+			beforeAccounts := st.CacheStats()
+			res := testing.Benchmark(func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					fn(i)
+				}
+			})
+			ops := float64(time.Second) / float64(res.NsPerOp())
+			if !enabled {
+				r.off = ops
+				continue
+			}
+			r.on = ops
+			after := db.CacheStats()
+			afterAccounts := st.CacheStats()
+			hits := after.Blocks.Hits + after.Headers.Hits + afterAccounts.Hits - before.Blocks.Hits - before.Headers.Hits - beforeAccounts.Hits
+			misses := after.Blocks.Misses + after.Headers.Misses + afterAccounts.Misses - before.Blocks.Misses - before.Headers.Misses - beforeAccounts.Misses
+			if hits+misses > 0 {
+				r.onHitRate = storage.CacheStats{Hits: hits, Misses: misses}.HitRate()
+				r.hasHitRate = true
+			}
+		}
+		results = append(results, r)
+	}
+
+	tip := int64(*blocks)
+	rng := rand.New(rand.NewSource(1))
+	measure("GetBlockByHeight (recent)", func(int) {
+		if _, err := db.GetBlockByHeight(int(tip - rng.Int63n(recentWindow))); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+	})
+	measure("GetHeaderByHeight (recent)", func(int) {
+		if _, _, err := db.GetHeaderByHeight(int(tip - rng.Int63n(recentWindow))); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+	})
+	measure("GetBalance", func(i int) {
+		addr := alice
+		if i%2 == 1 {
+			addr = bob
+		}
+		if _, err := st.GetBalance(addr); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+	})
+	next := clientTxs(alice, bob, *txCount, time.Now().Unix())
+	measure("PreviewRoot (next block)", func(int) {
+		if _, err := st.PreviewRoot(next); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+	})
+
+	fmt.Printf("%-28s %14s %14s %8s %8s\n", "", "no cache", "cache", "speedup", "hits")
+	for _, r := range results {
+		hitRate := "-"
+		if r.hasHitRate {
+			hitRate = fmt.Sprintf("%.0f%%", 100*r.onHitRate)
+		}
+		fmt.Printf("%-28s %10.0f op/s %10.0f op/s %7.1fx %8s\n", r.name, r.off, r.on, r.on/r.off, hitRate)
+	}
+
+	fmt.Println()
+	for _, enabled := range []bool{false, true} {
+		if enabled {
+			st.SetCacheSize(state.DefaultAccountCacheSize)
+		} else {
+			st.SetCacheSize(0)
+		}
+		// Dropping every balance and the state height makes the rebuild
+		// replay the chain from genesis.
+		if err := st.RollBack(-1, nil); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		start := time.Now()
+		if err := st.RebuildStateFromBlockchain(); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		fmt.Printf("RebuildStateFromBlockchain (cache %v): %s\n", enabled, time.Since(start))
+	}
+}
+
+// buildChain saves a chain of client-style transfers and applies it to st.
+func buildChain(db *storage.DB, st *state.State, blocks, txCount int) (string, string) {
+	aliceWallet, err := wallet.CreateWallet()
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	bobWallet, err := wallet.CreateWallet()
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	aliceAddr, _ := hex.DecodeString(aliceWallet.Address)
+
+	genesisTxs := []*blockchain.Transaction{{Sender: []byte("GENESIS"), Receiver: aliceAddr, Amount: 1e12}}
+	root, _ := st.PreviewRoot(genesisTxs)
+	prev := blockchain.NewBlockWithState(genesisTxs, []byte{}, 0, root, "bench")
+	commit(db, st, prev)
+
+	now := time.Now().Unix()
+	for h := 1; h <= blocks; h++ {
+		txs := clientTxs(aliceWallet.Address, bobWallet.Address, txCount, now+int64(h*txCount))
+		for _, tx := range txs {
+			if err := wallet.SignTransaction(tx, aliceWallet.PrivateKey); err != nil {
+				log.Fatalf("❌ %v", err)
+			}
+		}
+		root, err := st.PreviewRoot(txs)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		block := blockchain.NewBlockWithState(txs, prev.CurrentBlockHash, h, root, "bench")
+		commit(db, st, block)
+		prev = block
+	}
+	return aliceWallet.Address, bobWallet.Address
+}
+
+// clientTxs returns transfers from alice to bob with the amounts cmd/client
+// sends.
+func clientTxs(alice, bob string, n int, timestamp int64) []*blockchain.Transaction {
+	sender, _ := hex.DecodeString(alice)
+	receiver, _ := hex.DecodeString(bob)
+	amounts := []float64{3500.0, 5500.123}
+	var txs []*blockchain.Transaction
+	for i := 0; i < n; i++ {
+		txs = append(txs, &blockchain.Transaction{Sender: sender, Receiver: receiver, Amount: amounts[i%len(amounts)], Timestamp: timestamp + int64(i)})
+	}
+	return txs
+}
+
+func commit(db *storage.DB, st *state.State, block *blockchain.Block) {
+	if err := db.SaveBlock(block); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if err := st.ApplyBlock(block); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+// So sánh encoding JSON cũ với encoding nhị phân (deterministic protobuf)
+// của block: kích thước, tốc độ encode/decode và tốc độ băm.
+//
+//	go run ./cmd/test/encoding_bench --txs 500
+
+import (
+	"blockchain-go/pkg/blockchain"
+	"blockchain-go/pkg/wallet"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"testing"
+	"time"
+)
+
+func main() {
+	txCount := flag.Int("txs", 500, "number of transactions per block")
+	flag.Parse()
+
+	block := sampleBlock(*txCount)
+	jsonData, _ := json.Marshal(block)
+	binData, err := block.Encode()
+	if err != nil {
+		log.Fatalf("❌ encode failed: %v", err)
+	}
+	decoded, err := blockchain.DecodeBlock(binData)
+	if err != nil || hex.EncodeToString(decoded.Hash()) != hex.EncodeToString(block.CurrentBlockHash) {
+		log.Fatalf("❌ binary round trip changed the block (err=%v)", err)
+	}
+
+	fmt.Printf("Block with %d transactions\n", *txCount)
+	fmt.Printf("  JSON size:   %8d bytes\n", len(jsonData))
+	fmt.Printf("  binary size: %8d bytes (%.0f%%)\n\n", len(binData), 100*float64(len(binData))/float64(len(jsonData)))
+
+	run := func(name string, fn func()) {
+		res := testing.Benchmark(func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				fn()
+			}
+		})
+		fmt.Printf("  %-16s %12s/op  %8d allocs/op\n", name, time.Duration(res.NsPerOp()), res.AllocsPerOp())
+	}
+
+	fmt.Println("Encode")
+	run("json", func() { json.Marshal(block) })
+	run("binary", func() { block.Encode() })
+
+	fmt.Println("Decode")
+	run("json", func() {
+		var b blockchain.Block
+		json.Unmarshal(jsonData, &b)
+	})
+	run("binary", func() { blockchain.DecodeBlock(binData) })
+
+	fmt.Println("Block hash")
+	run("json", func() {
+		copyBlock := *block
+		copyBlock.CurrentBlockHash = nil
+		data, _ := json.Marshal(copyBlock)
+		sha256.Sum256(data)
+	})
+	run("binary", func() { block.Hash() })
+
+	fmt.Println("Transaction hash")
+	tx := block.Transactions[0]
+	run("json", func() {
+		txCopy := *tx
+		txCopy.Signature, txCopy.PublicKey = nil, nil
+		data, _ := json.Marshal(txCopy)
+		sha256.Sum256(data)
+	})
+	run("binary", func() { tx.Hash() })
+}
+
+func sampleBlock(n int) *blockchain.Block {
+	alice, err := wallet.CreateWallet()
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	sender, _ := hex.DecodeString(alice.Address)
+	receiver := make([]byte, len(sender))
+	copy(receiver, sender)
+	receiver[0] ^= 0xff
+
+	var txs []*blockchain.Transaction
+	for i := 0; i < n; i++ {
+		tx := &blockchain.Transaction{Sender: sender, Receiver: receiver, Amount: float64(i) + 0.5, Timestamp: time.Now().Unix() + int64(i)}
+		if err := wallet.SignTransaction(tx, alice.PrivateKey); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		txs = append(txs, tx)
+	}
+	return blockchain.NewBlock(txs, make([]byte, 32), 1)
+}
@@ -0,0 +1,106 @@
+package main
+
+// Ghim encoding của block và giao dịch: hash của block và giao dịch là sha256
+// của protobuf deterministic, mà protobuf không có encoding chuẩn, nên một
+// phiên bản google.golang.org/protobuf khác có thể ghi cùng các trường thành
+// byte khác và làm node không còn đồng ý về hash. Chương trình này so encoding
+// của các block và giao dịch cố định với byte đã ghi lại; chạy nó trước khi
+// đổi phiên bản protobuf trong go.mod.
+//
+//	go run ./cmd/test/encoding_pin
+
+import (
+	"blockchain-go/pkg/blockchain"
+	"blockchain-go/pkg/cryptohelper"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"runtime/debug"
+)
+
+func fill(n int, b byte) []byte {
+	return bytes.Repeat([]byte{b}, n)
+}
+
+// Mọi trường đều khác giá trị mặc định, để trường nào cũng xuất hiện trong
+// encoding
+var tx = &blockchain.Transaction{
+	Sender:          fill(20, 0x11),
+	Receiver:        fill(20, 0x22),
+	Amount:          12.5,
+	Timestamp:       1700000000,
+	Signature:       fill(64, 0x33),
+	PublicKey:       fill(33, 0x44),
+	ChainID:         "pin-test",
+	SignatureScheme: cryptohelper.Secp256k1,
+}
+
+var block = &blockchain.Block{
+	BlockHeader: blockchain.BlockHeader{
+		Height:            42,
+		MerkleRoot:        fill(32, 0x55),
+		TxRootType:        blockchain.TxRootBinary,
+		PreviousBlockHash: fill(32, 0x66),
+		StateRoot:         fill(32, 0x77),
+		Timestamp:         1700000100,
+		Proposer:          "node1",
+		ChainID:           "pin-test",
+		ConfigHash:        fill(32, 0x88),
+	},
+	Transactions: []*blockchain.Transaction{tx, {Sender: []byte("GENESIS"), Receiver: fill(20, 0x22), Amount: 1}},
+}
+
+func main() {
+	block.CurrentBlockHash = block.Hash()
+	txData, _ := tx.Encode()
+	blockData, _ := block.Encode()
+	body, _ := block.EncodeBody()
+
+	pins := []struct {
+		name string
+		got  []byte
+		want string
+	}{
+		{"tx encoding", txData, "0a141111111111111111111111111111111111111111121422222222222222222222222222222222222222221900000000000029402080e2cfaa062a403333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333332214444444444444444444444444444444444444444444444444444444444444444443a0870696e2d746573744001"},
+		{"tx hash", tx.Hash(), "a3804da2420fe5f711e5508b7778093de481556550d18cf2777dacdce3959028"},
+		{"header hash", block.Hash(), "f54daecf0182b63065c09978705caa90ac39a6aff07e11c5f679933db78fd324"},
+		{"body encoding", body, "12ac010a141111111111111111111111111111111111111111121422222222222222222222222222222222222222221900000000000029402080e2cfaa062a403333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333332214444444444444444444444444444444444444444444444444444444444444444443a0870696e2d74657374400112280a0747454e455349531214222222222222222222222222222222222222222219000000000000f03f"},
+		{"block encoding", blockData, "082a12ac010a141111111111111111111111111111111111111111121422222222222222222222222222222222222222221900000000000029402080e2cfaa062a403333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333333332214444444444444444444444444444444444444444444444444444444444444444443a0870696e2d74657374400112280a0747454e455349531214222222222222222222222222222222222222222219000000000000f03f1a205555555555555555555555555555555555555555555555555555555555555555222066666666666666666666666666666666666666666666666666666666666666662a20f54daecf0182b63065c09978705caa90ac39a6aff07e11c5f679933db78fd32430e4e2cfaa063a20777777777777777777777777777777777777777777777777777777777777777742056e6f6465314a0870696e2d74657374522088888888888888888888888888888888888888888888888888888888888888885801"},
+	}
+	failed := 0
+	for _, p := range pins {
+		if got := hex.EncodeToString(p.got); got != p.want {
+			fmt.Printf("❌ %-14s changed:\n   got  %s\n   want %s\n", p.name, got, p.want)
+			failed++
+		}
+	}
+
+	// Decode rồi encode lại phải ra đúng các byte cũ
+	decoded, err := blockchain.DecodeBlock(blockData)
+	if err != nil {
+		fmt.Printf("❌ decode: %v\n", err)
+		failed++
+	} else if again, _ := decoded.Encode(); !bytes.Equal(again, blockData) || !bytes.Equal(decoded.Hash(), block.CurrentBlockHash) {
+		fmt.Println("❌ round trip: a decoded block encodes to other bytes")
+		failed++
+	}
+
+	if failed > 0 {
+		fmt.Printf("\n%d pin(s) failed: the encoding, and so every block and transaction hash, changed\n", failed)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ %-12s %d encodings match the recorded bytes with protobuf %s, round trip stable\n", "pins", len(pins), protobufVersion())
+}
+
+// protobufVersion is the version of google.golang.org/protobuf built in.
+func protobufVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path == "google.golang.org/protobuf" {
+				return dep.Version
+			}
+		}
+	}
+	return "(unknown)"
+}
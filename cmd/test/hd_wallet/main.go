@@ -0,0 +1,314 @@
+package main
+
+// Kiểm tra mnemonic BIP-39 và dẫn xuất khoá SLIP-10 với các test vector chính
+// thức (bộ vector của Trezor cho BIP-39, passphrase "TREZOR", và vector của
+// SLIP-10 cho P-256, secp256k1 và Ed25519), rồi kiểm tra ví dẫn xuất lưu được vào
+// keystore và khôi phục lại đúng địa chỉ.
+//
+//	go run ./cmd/test/hd_wallet
+
+import (
+	"blockchain-go/pkg/cryptohelper"
+	"blockchain-go/pkg/wallet"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// bip39Vectors are entropy, mnemonic and seed with passphrase "TREZOR".
+var bip39Vectors = [][3]string{
+	{"00000000000000000000000000000000",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04"},
+	{"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+		"legal winner thank year wave sausage worth useful legal winner thank yellow",
+		"2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607"},
+	{"80808080808080808080808080808080",
+		"letter advice cage absurd amount doctor acoustic avoid letter advice cage above",
+		"d71de856f81a8acc65e6fc851a38d4d7ec216fd0796d0a6827a3ad6ed5511a30fa280f12eb2e47ed2ac03b5c462a0358d18d69fe4f985ec81778c1b370b652a8"},
+	{"ffffffffffffffffffffffffffffffff",
+		"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+		"ac27495480225222079d7be181583751e86f571027b0497b5b5d11218e0a8a13332572917f0f8e5a589620c6f15b11c61dee327651a14c34e18231052e48c069"},
+	{"000000000000000000000000000000000000000000000000",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon agent",
+		"035895f2f481b1b0f01fcf8c289c794660b289981a78f8106447707fdd9666ca06da5a9a565181599b79f53b844d8a71dd9f439c52a3d7b3e8a79c906ac845fa"},
+	{"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+		"legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal will",
+		"f2b94508732bcbacbcc020faefecfc89feafa6649a5491b8c952cede496c214a0c7b3c392d168748f2d4a612bada0753b52a1c7ac53c1e93abd5c6320b9e95dd"},
+	{"808080808080808080808080808080808080808080808080",
+		"letter advice cage absurd amount doctor acoustic avoid letter advice cage absurd amount doctor acoustic avoid letter always",
+		"107d7c02a5aa6f38c58083ff74f04c607c2d2c0ecc55501dadd72d025b751bc27fe913ffb796f841c49b1d33b610cf0e91d3aa239027f5e99fe4ce9e5088cd65"},
+	{"ffffffffffffffffffffffffffffffffffffffffffffffff",
+		"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo when",
+		"0cd6e5d827bb62eb8fc1e262254223817fd068a74b5b449cc2f667c3f1f985a76379b43348d952e2265b4cd129090758b3e3c2c49103b5051aac2eaeb890a528"},
+	{"0000000000000000000000000000000000000000000000000000000000000000",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art",
+		"bda85446c68413707090a52022edd26a1c9462295029f2e60cd7c4f2bbd3097170af7a4d73245cafa9c3cca8d561a7c3de6f5d4a10be8ed2a5e608d68f92fcc8"},
+	{"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+		"legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth title",
+		"bc09fca1804f7e69da93c2f2028eb238c227f2e9dda30cd63699232578480a4021b146ad717fbb7e451ce9eb835f43620bf5c514db0f8add49f5d121449d3e87"},
+	{"8080808080808080808080808080808080808080808080808080808080808080",
+		"letter advice cage absurd amount doctor acoustic avoid letter advice cage absurd amount doctor acoustic avoid letter advice cage absurd amount doctor acoustic bless",
+		"c0c519bd0e91a2ed54357d9d1ebef6f5af218a153624cf4f2da911a0ed8f7a09e2ef61af0aca007096df430022f7a2b6fb91661a9589097069720d015e4e982f"},
+	{"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo vote",
+		"dd48c104698c30cfe2b6142103248622fb7bb0ff692eebb00089b32d22484e1613912f0a5b694407be899ffd31ed3992c456cdf60f5d4564b8ba3f05a69890ad"},
+	{"77c2b00716cec7213839159e404db50d",
+		"jelly better achieve collect unaware mountain thought cargo oxygen act hood bridge",
+		"b5b6d0127db1a9d2226af0c3346031d77af31e918dba64287a1b44b8ebf63cdd52676f672a290aae502472cf2d602c051f3e6f18055e84e4c43897fc4e51a6ff"},
+	{"b63a9c59a6e641f288ebc103017f1da9f8290b3da6bdef7b",
+		"renew stay biology evidence goat welcome casual join adapt armor shuffle fault little machine walk stumble urge swap",
+		"9248d83e06f4cd98debf5b6f010542760df925ce46cf38a1bdb4e4de7d21f5c39366941c69e1bdbf2966e0f6e6dbece898a0e2f0a4c2b3e640953dfe8b7bbdc5"},
+	{"3e141609b97933b66a060dcddc71fad1d91677db872031e85f4c015c5e7e8982",
+		"dignity pass list indicate nasty swamp pool script soccer toe leaf photo multiply desk host tomato cradle drill spread actor shine dismiss champion exotic",
+		"ff7f3184df8696d8bef94b6c03114dbee0ef89ff938712301d27ed8336ca89ef9635da20af07d4175f2bf5f3de130f39c9d9e8dd0472489c19b1a020a940da67"},
+	{"0460ef47585604c5660618db2e6a7e7f",
+		"afford alter spike radar gate glance object seek swamp infant panel yellow",
+		"65f93a9f36b6c85cbe634ffc1f99f2b82cbb10b31edc7f087b4f6cb9e976e9faf76ff41f8f27c99afdf38f7a303ba1136ee48a4c1e7fcd3dba7aa876113a36e4"},
+	{"72f60ebac5dd8add8d2a25a797102c3ce21bc029c200076f",
+		"indicate race push merry suffer human cruise dwarf pole review arch keep canvas theme poem divorce alter left",
+		"3bbf9daa0dfad8229786ace5ddb4e00fa98a044ae4c4975ffd5e094dba9e0bb289349dbe2091761f30f382d4e35c4a670ee8ab50758d2c55881be69e327117ba"},
+	{"2c85efc7f24ee4573d2b81a6ec66cee209b2dcbd09d8eddc51e0215b0b68e416",
+		"clutch control vehicle tonight unusual clog visa ice plunge glimpse recipe series open hour vintage deposit universe tip job dress radar refuse motion taste",
+		"fe908f96f46668b2d5b37d82f558c77ed0d69dd0e7e043a5b0511c48c2f1064694a956f86360c93dd04052a8899497ce9e985ebe0c8c52b955e6ae86d4ff4449"},
+	{"eaebabb2383351fd31d703840b32e9e2",
+		"turtle front uncle idea crush write shrug there lottery flower risk shell",
+		"bdfb76a0759f301b0b899a1e3985227e53b3f51e67e3f2a65363caedf3e32fde42a66c404f18d7b05818c95ef3ca1e5146646856c461c073169467511680876c"},
+	{"7ac45cfe7722ee6c7ba84fbc2d5bd61b45cb2fe5eb65aa78",
+		"kiss carry display unusual confirm curtain upgrade antique rotate hello void custom frequent obey nut hole price segment",
+		"ed56ff6c833c07982eb7119a8f48fd363c4a9b1601cd2de736b01045c5eb8ab4f57b079403485d1c4924f0790dc10a971763337cb9f9c62226f64fff26397c79"},
+	{"4fa1a8bc3e6d80ee1316050e862c1812031493212b7ec3f3bb1b08f168cabeef",
+		"exile ask congress lamp submit jacket era scheme attend cousin alcohol catch course end lucky hurt sentence oven short ball bird grab wing top",
+		"095ee6f817b4c2cb30a5a797360a81a40ab0f9a4e25ecd672a3f58a0b5ba0687c096a6b14d2c0deb3bdefce4f61d01ae07417d502429352e27695163f7447a8c"},
+	{"18ab19a9f54a9274f03e5209a2ac8a91",
+		"board flee heavy tunnel powder denial science ski answer betray cargo cat",
+		"6eff1bb21562918509c73cb990260db07c0ce34ff0e3cc4a8cb3276129fbcb300bddfe005831350efd633909f476c45c88253276d9fd0df6ef48609e8bb7dca8"},
+	{"18a2e1d81b8ecfb2a333adcb0c17a5b9eb76cc5d05db91a4",
+		"board blade invite damage undo sun mimic interest slam gaze truly inherit resist great inject rocket museum chief",
+		"f84521c777a13b61564234bf8f8b62b3afce27fc4062b51bb5e62bdfecb23864ee6ecf07c1d5a97c0834307c5c852d8ceb88e7c97923c0a3b496bedd4e5f88a9"},
+	{"15da872c95a13dd738fbf50e427583ad61f18fd99f628c417a61cf8343c90419",
+		"beyond stage sleep clip because twist token leaf atom beauty genius food business side grid unable middle armed observe pair crouch tonight away coconut",
+		"b15509eaa2d09d3efd3e006ef42151b30367dc6e3aa5e44caba3fe4d3e352e65101fbdb86a96776b91946ff06f8eac594dc6ee1d3e82a42dfe1b40fef6bcc3fd"},
+}
+
+type step struct{ path, chainCode, key string }
+
+// slip10Vectors is test vector 1 of SLIP-10 for every scheme (for
+// secp256k1 it is vector 1 of BIP-32): each step derives the next child
+// from the seed 000102030405060708090a0b0c0d0e0f.
+var slip10Vectors = map[cryptohelper.SchemeID][]step{
+	cryptohelper.P256: {
+		{"m", "beeb672fe4621673f722f38529c07392fecaa61015c80c34f29ce8b41b3cb6ea", "612091aaa12e22dd2abef664f8a01a82cae99ad7441b7ef8110424915c268bc2"},
+		{"m/0H", "3460cea53e6a6bb5fb391eeef3237ffd8724bf0a40e94943c98b83825342ee11", "6939694369114c67917a182c59ddb8cafc3004e63ca5d3b84403ba8613debc0c"},
+		{"m/0H/1", "4187afff1aafa8445010097fb99d23aee9f599450c7bd140b6826ac22ba21d0c", "284e9d38d07d21e4e281b645089a94f4cf5a5a81369acf151a1c3a57f18b2129"},
+		{"m/0H/1/2H", "98c7514f562e64e74170cc3cf304ee1ce54d6b6da4f880f313e8204c2a185318", "694596e8a54f252c960eb771a3c41e7e32496d03b954aeb90f61635b8e092aa7"},
+		{"m/0H/1/2H/2", "ba96f776a5c3907d7fd48bde5620ee374d4acfd540378476019eab70790c63a0", "5996c37fd3dd2679039b23ed6f70b506c6b56b3cb5e424681fb0fa64caf82aaa"},
+		{"m/0H/1/2H/2/1000000000", "b9b7b82d326bb9cb5b5b121066feea4eb93d5241103c9e7a18aad40f1dde8059", "21c4f269ef0a5fd1badf47eeacebeeaa3de22eb8e5b0adcd0f27dd99d34d0119"},
+	},
+	cryptohelper.Secp256k1: {
+		{"m", "873dff81c02f525623fd1fe5167eac3a55a049de3d314bb42ee227ffed37d508", "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"},
+		{"m/0H", "47fdacbd0f1097043b78c63c20c34ef4ed9a111d980047ad16282c7ae6236141", "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{"m/0H/1", "2a7857631386ba23dacac34180dd1983734e444fdbf774041578e9b6adb37c19", "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+		{"m/0H/1/2H", "04466b9cc8e161e966409ca52986c584f07e9dc81f735db683c3ff6ec7b1503f", "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca"},
+		{"m/0H/1/2H/2", "cfb71883f01676f587d023cc53a35bc7f88f724b1f8c2892ac1275ac822a3edd", "0f479245fb19a38a1954c5c7c0ebab2f9bdfd96a17563ef28a6a4b1a2a764ef4"},
+		{"m/0H/1/2H/2/1000000000", "c783e67b921d2beb8f6b389cc646d7263b4145701dadd2161548a8b078e65e9e", "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"},
+	},
+	cryptohelper.Ed25519: {
+		{"m", "90046a93de5380a72b5e45010748567d5ea02bbf6522f979e05c0d8d8ca9fffb", "2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7"},
+		{"m/0H", "8b59aa11380b624e81507a27fedda59fea6d0b779a778918a2fd3590e16e9c69", "68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3"},
+		{"m/0H/1H", "a320425f77d1b5c2505a6b1b27382b37368ee640e3557c315416801243552f14", "b1d0bad404bf35da785a64ca1ac54b2617211d2777696fbffaf208f746ae84f2"},
+		{"m/0H/1H/2H", "2e69929e00b5ab250f49c3fb1c12f252de4fed2c1db88387094a0f8c4c9ccd6c", "92a5b23c0b8a99e37d07df3fb9966917f5d06e02ddbd909c7e184371463e9fc9"},
+		{"m/0H/1H/2H/2H", "8f6d87f93d750e0efccda017d662a1b31a266e4a6f5993b15f5c1f07f74dd5cc", "30d1dc7e5fc04c31219ab25a27ae00b50f6fd66622f6e9c913253d6511d1e662"},
+		{"m/0H/1H/2H/2H/1000000000H", "68789923a0cac2cd5a29172a475fe9e0fb14cd6adb5ad98a3fa70333e7afa230", "8f94d394a8e8fd6b1bc2f3f49f5c47e385281d5c17e65324b0f62483e37e8793"},
+	},
+}
+
+func main() {
+	failed := 0
+	for _, check := range []func() bool{checkMnemonics, checkInvalid, checkDerivation, checkRetry, checkKeystore} {
+		if !check() {
+			failed++
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("\n%d check(s) failed\n", failed)
+		os.Exit(1)
+	}
+}
+
+func checkMnemonics() bool {
+	for _, v := range bip39Vectors {
+		entropy, _ := hex.DecodeString(v[0])
+		mnemonic, err := wallet.EntropyToMnemonic(entropy)
+		if err != nil || mnemonic != v[1] {
+			fmt.Printf("❌ mnemonic: %s encodes to %q (%v)\n", v[0], mnemonic, err)
+			return false
+		}
+		decoded, err := wallet.MnemonicToEntropy(v[1])
+		if err != nil || !bytes.Equal(decoded, entropy) {
+			fmt.Printf("❌ mnemonic: %q decodes to %x (%v)\n", v[1], decoded, err)
+			return false
+		}
+		seed, err := wallet.MnemonicToSeed(v[1], "TREZOR")
+		if err != nil || hex.EncodeToString(seed) != v[2] {
+			fmt.Printf("❌ mnemonic: seed of %q is %x (%v)\n", v[1], seed, err)
+			return false
+		}
+	}
+	for _, words := range []int{12, 15, 18, 21, 24} {
+		m, err := wallet.NewMnemonic(words)
+		if err != nil || len(strings.Fields(m)) != words || wallet.ValidateMnemonic(m) != nil {
+			fmt.Printf("❌ mnemonic: new mnemonic of %d words is %q (%v)\n", words, m, err)
+			return false
+		}
+	}
+	fmt.Printf("✅ %-12s %d BIP-39 vectors, new mnemonics of 12-24 words\n", "mnemonic", len(bip39Vectors))
+	return true
+}
+
+// checkInvalid checks that mistyped mnemonics are rejected with the right
+// error.
+func checkInvalid() bool {
+	valid := strings.Fields(bip39Vectors[0][1])
+	swapped := append([]string{}, valid...)
+	swapped[0], swapped[11] = swapped[11], swapped[0]
+	bad := map[string]error{
+		"11 words":     wallet.ErrInvalidMnemonic,
+		"unknown word": wallet.ErrInvalidMnemonic,
+		"swapped":      wallet.ErrMnemonicChecksum,
+		"wrong word":   wallet.ErrMnemonicChecksum,
+	}
+	inputs := map[string]string{
+		"11 words":     strings.Join(valid[:11], " "),
+		"unknown word": strings.Join(append(valid[:11:11], "bitcoinz"), " "),
+		"swapped":      strings.Join(swapped, " "),
+		"wrong word":   strings.Join(append(valid[:11:11], "zoo"), " "),
+	}
+	for name, want := range bad {
+		if err := wallet.ValidateMnemonic(inputs[name]); !errors.Is(err, want) {
+			fmt.Printf("❌ invalid: %s gives %v, want %v\n", name, err, want)
+			return false
+		}
+	}
+	if err := wallet.ValidateMnemonic("  Abandon abandon ABANDON abandon abandon abandon abandon abandon abandon abandon abandon about\n"); err != nil {
+		fmt.Printf("❌ invalid: case and spaces are not ignored: %v\n", err)
+		return false
+	}
+	for _, path := range []string{"", "44'/0", "m/", "m/x", "m/2147483648", "m/-1"} {
+		if _, err := wallet.ParsePath(path); !errors.Is(err, wallet.ErrInvalidPath) {
+			fmt.Printf("❌ invalid: path %q gives %v\n", path, err)
+			return false
+		}
+	}
+	fmt.Printf("✅ %-12s %d bad mnemonics and 6 bad paths rejected\n", "invalid", len(bad))
+	return true
+}
+
+func checkDerivation() bool {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	keys := 0
+	for scheme, steps := range slip10Vectors {
+		master, err := wallet.NewMasterKey(scheme, seed)
+		if err != nil {
+			fmt.Printf("❌ slip-10: %s: %v\n", scheme, err)
+			return false
+		}
+		for _, v := range steps {
+			key, err := master.Derive(v.path)
+			if err != nil || hex.EncodeToString(key.ChainCode) != v.chainCode || hex.EncodeToString(key.Key) != v.key {
+				fmt.Printf("❌ slip-10: %s %s derives key %x chain code %x (%v)\n", scheme, v.path, key.Key, key.ChainCode, err)
+				return false
+			}
+			keys++
+		}
+		// Ed25519 không có khoá con thường
+		if _, err := master.Derive("m/0"); (err != nil) != (scheme == cryptohelper.Ed25519) {
+			fmt.Printf("❌ slip-10: %s non-hardened child gives %v\n", scheme, err)
+			return false
+		}
+	}
+	fmt.Printf("✅ %-12s %d keys of vector 1 for p256, secp256k1 and ed25519\n", "slip-10", keys)
+	return true
+}
+
+// checkRetry runs the SLIP-10 vectors where a derived IL is not a valid key
+// and the derivation has to be repeated.
+func checkRetry() bool {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, _ := wallet.NewMasterKey(cryptohelper.P256, seed)
+	retries := []struct{ path, chainCode, key string }{
+		{"m/28578H", "e94c8ebe30c2250a14713212f6449b20f3329105ea15b652ca5bdfc68f6c65c2", "06f0db126f023755d0b8d86d4591718a5210dd8d024e3e14b6159d63f53aa669"},
+		{"m/28578H/33941", "9e87fe95031f14736774cd82f25fd885065cb7c358c1edf813c72af535e83071", "092154eed4af83e078ff9b84322015aefe5769e31270f62c3f66c33888335f3a"},
+	}
+	for _, v := range retries {
+		key, err := master.Derive(v.path)
+		if err != nil || hex.EncodeToString(key.ChainCode) != v.chainCode || hex.EncodeToString(key.Key) != v.key {
+			fmt.Printf("❌ retry: %s derives key %x chain code %x (%v)\n", v.path, key.Key, key.ChainCode, err)
+			return false
+		}
+	}
+	seed, _ = hex.DecodeString("a7305bc8df8d0951f0cb224c0e95d7707cbdf2c6ce7e8d481fec69c7ff5e9446")
+	master, _ = wallet.NewMasterKey(cryptohelper.P256, seed)
+	if hex.EncodeToString(master.ChainCode) != "7762f9729fed06121fd13f326884c82f59aa95c57ac492ce8c9654e60efd130c" ||
+		hex.EncodeToString(master.Key) != "3b8c18469a4634517d6d0b65448f8e6c62091b45540a1743c5846be55d47d88f" {
+		fmt.Printf("❌ retry: master key %x chain code %x\n", master.Key, master.ChainCode)
+		return false
+	}
+	fmt.Printf("✅ %-12s child and master key derivation retried\n", "retry")
+	return true
+}
+
+// checkKeystore derives accounts from a new mnemonic, stores one in a
+// keystore and derives the next account from the loaded file.
+func checkKeystore() bool {
+	mnemonic, _ := wallet.NewMnemonic(12)
+	first, err1 := wallet.DeriveWallet(cryptohelper.P256, mnemonic, wallet.AccountPath(cryptohelper.P256, 0))
+	again, err2 := wallet.DeriveWallet(cryptohelper.P256, strings.ToUpper(mnemonic), wallet.AccountPath(cryptohelper.P256, 0))
+	second, err3 := wallet.DeriveWallet(cryptohelper.P256, mnemonic, wallet.AccountPath(cryptohelper.P256, 1))
+	if err := errors.Join(err1, err2, err3); err != nil {
+		fmt.Printf("❌ keystore: %v\n", err)
+		return false
+	}
+	if first.Address != again.Address || first.Address == second.Address {
+		fmt.Println("❌ keystore: derivation is not deterministic per index")
+		return false
+	}
+
+	data, err := wallet.EncryptKey(first, "pass")
+	if err != nil {
+		fmt.Printf("❌ keystore: %v\n", err)
+		return false
+	}
+	loaded, err := wallet.DecryptKey(data, "pass")
+	if err != nil || loaded.Address != first.Address || loaded.Mnemonic != mnemonic || loaded.Path != wallet.AccountPath(cryptohelper.P256, 0) {
+		fmt.Printf("❌ keystore: loaded %+v (%v)\n", loaded, err)
+		return false
+	}
+	next, err := wallet.DeriveWallet(loaded.Scheme(), loaded.Mnemonic, wallet.AccountPath(loaded.Scheme(), 1))
+	if err != nil || next.Address != second.Address {
+		fmt.Printf("❌ keystore: account 1 from the file is %v (%v)\n", next, err)
+		return false
+	}
+
+	// Đường dẫn trong file bị sửa thì không giải mã được
+	var ks map[string]any
+	json.Unmarshal(data, &ks)
+	ks["hd"].(map[string]any)["path"] = wallet.AccountPath(cryptohelper.P256, 1)
+	tampered, _ := json.Marshal(ks)
+	if _, err := wallet.DecryptKey(tampered, "pass"); !errors.Is(err, wallet.ErrWrongPassphrase) {
+		fmt.Printf("❌ keystore: file with an edited path gives %v\n", err)
+		return false
+	}
+	random, _ := wallet.CreateWallet()
+	data, _ = wallet.EncryptKey(random, "pass")
+	if loaded, err := wallet.DecryptKey(data, "pass"); err != nil || loaded.Mnemonic != "" || bytes.Contains(data, []byte(`"hd"`)) {
+		fmt.Printf("❌ keystore: random wallet has an hd section (%v)\n", err)
+		return false
+	}
+	fmt.Printf("✅ %-12s mnemonic and path stored and authenticated\n", "keystore")
+	return true
+}
@@ -0,0 +1,216 @@
+package main
+
+// Kiểm tra pkg/merkle với các test vector của RFC 6962 (bộ test của
+// certificate-transparency): root của 0 đến 8 lá phải khớp, audit path của
+// mọi lá phải kiểm tra được và proof bị sửa phải bị từ chối. Cuối cùng so
+// sánh với MPT cho tx root của một block.
+//
+//	go run ./cmd/test/merkle_test --txs 1000
+
+import (
+	"blockchain-go/pkg/blockchain"
+	"blockchain-go/pkg/merkle"
+	"blockchain-go/pkg/mpt"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+var leaves = []string{"", "00", "10", "2021", "3031", "40414243", "5051525354555657", "606162636465666768696a6b6c6d6e6f"}
+
+// roots[i] is the root of the first i leaves.
+var roots = []string{
+	"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+	"6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+	"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+	"aeb6bcfe274b70a14fb067a5e5578264db0fa9b51af5e0ba159158f329e06e77",
+	"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+	"4e3bbb1f7b478dcfe71fb631631519a3bca12c9aefca1612bfce4c13a86264d4",
+	"76e67dadbcdf1e10e1b74ddc608abd2f98dfb16fbce75277b5232a127f2087ef",
+	"ddb89be403809e325750d3d263cd78929c2942b7942a34b77e122c9594a74c8c",
+	"5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328",
+}
+
+// maxSize is the largest tree whose every audit path is checked.
+const maxSize = 70
+
+func main() {
+	txCount := flag.Int("txs", 1000, "number of transactions in the compared block")
+	flag.Parse()
+
+	failed := 0
+	var data [][]byte
+	for _, l := range leaves {
+		d, _ := hex.DecodeString(l)
+		data = append(data, d)
+	}
+	for n, want := range roots {
+		if root := hex.EncodeToString(merkle.Root(data[:n])); root != want {
+			fmt.Printf("❌ %d leaves: root %s, want %s\n", n, root, want)
+			failed++
+		}
+	}
+	if failed == 0 {
+		fmt.Printf("✅ %-12s roots of 0-%d leaves match RFC 6962\n", "vectors", len(leaves))
+	}
+
+	for _, check := range []func() bool{checkProofs, checkTampered, checkShape} {
+		if !check() {
+			failed++
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("\n%d check(s) failed\n", failed)
+		os.Exit(1)
+	}
+	compare(*txCount)
+}
+
+func sample(n int) [][]byte {
+	data := make([][]byte, n)
+	for i := range data {
+		h := sha256.Sum256([]byte(fmt.Sprintf("tx-%d", i)))
+		data[i] = h[:]
+	}
+	return data
+}
+
+// checkProofs proves every leaf of every tree up to maxSize leaves, through
+// Encode/DecodeProof, and checks the proof only holds at its own index.
+func checkProofs() bool {
+	for n := 1; n <= maxSize; n++ {
+		data := sample(n)
+		root := merkle.Root(data)
+		for i := range data {
+			p, err := merkle.Prove(data, i)
+			if err != nil {
+				fmt.Printf("❌ proofs: Prove(%d of %d): %v\n", i, n, err)
+				return false
+			}
+			decoded, err := merkle.DecodeProof(p.Encode())
+			if err != nil || !merkle.Verify(root, data[i], i, n, decoded) {
+				fmt.Printf("❌ proofs: leaf %d of %d does not verify (%v)\n", i, n, err)
+				return false
+			}
+			for j := range data {
+				if j != i && merkle.Verify(root, data[j], i, n, p) {
+					fmt.Printf("❌ proofs: proof of leaf %d of %d verifies leaf %d\n", i, n, j)
+					return false
+				}
+				if j != i && merkle.Verify(root, data[i], j, n, p) {
+					fmt.Printf("❌ proofs: leaf %d of %d verifies at index %d\n", i, n, j)
+					return false
+				}
+			}
+		}
+		if _, err := merkle.Prove(data, n); err == nil {
+			fmt.Printf("❌ proofs: Prove(%d of %d) succeeds\n", n, n)
+			return false
+		}
+	}
+	fmt.Printf("✅ %-12s every leaf of trees of 1-%d leaves\n", "proofs", maxSize)
+	return true
+}
+
+// checkTampered damages a proof in every way a peer could and checks that
+// none of them verifies or decodes.
+func checkTampered() bool {
+	data := sample(21)
+	root := merkle.Root(data)
+	p, _ := merkle.Prove(data, 13)
+	encoded := p.Encode()
+
+	bad := map[string]merkle.Proof{
+		"missing hash": p[:len(p)-1],
+		"extra hash":   append(append(merkle.Proof{}, p...), p[0]),
+		"short hash":   append(merkle.Proof{p[0][:31]}, p[1:]...),
+	}
+	for i := range p {
+		tampered := append(merkle.Proof{}, p...)
+		tampered[i] = append([]byte{}, p[i]...)
+		tampered[i][0] ^= 1
+		bad[fmt.Sprintf("hash %d flipped", i)] = tampered
+	}
+	for name, proof := range bad {
+		if merkle.Verify(root, data[13], 13, len(data), proof) {
+			fmt.Printf("❌ tampered: proof with %s verifies\n", name)
+			return false
+		}
+	}
+	// Cỡ cây làm đổi hình dạng đường đi của lá 13; với 17-32 lá thì đường đi
+	// giống hệt, nên số lá phải lấy từ nguồn tin cậy
+	sizes := []int{13, 16, 40}
+	for _, size := range sizes {
+		if merkle.Verify(root, data[13], 13, size, p) {
+			fmt.Printf("❌ tampered: proof verifies for %d leaves\n", size)
+			return false
+		}
+	}
+	if _, err := merkle.DecodeProof(encoded[:len(encoded)-1]); err == nil {
+		fmt.Println("❌ tampered: truncated proof decodes")
+		return false
+	}
+	if _, err := merkle.DecodeProof(make([]byte, 65*merkle.HashSize)); err == nil {
+		fmt.Println("❌ tampered: proof of 65 hashes decodes")
+		return false
+	}
+	fmt.Printf("✅ %-12s %d damaged proofs rejected\n", "tampered", len(bad)+len(sizes)+2)
+	return true
+}
+
+// checkShape checks the two weaknesses of a naive binary tree: an inner node
+// must not pass for a leaf, and duplicating the last leaf of an odd level
+// must change the root.
+func checkShape() bool {
+	data := sample(4)
+	root := merkle.Root(data)
+	// Node bên trái của tầng trên cùng, trình ra như một lá của cây 2 lá
+	left := merkle.Root(data[:2])
+	right := merkle.Root(data[2:])
+	if merkle.Verify(root, left, 0, 2, merkle.Proof{right}) {
+		fmt.Println("❌ shape: inner node verifies as a leaf")
+		return false
+	}
+	odd := sample(3)
+	if bytes.Equal(merkle.Root(odd), merkle.Root(append(odd, odd[2]))) {
+		fmt.Println("❌ shape: duplicating the last leaf keeps the root")
+		return false
+	}
+	fmt.Printf("✅ %-12s domain separation, no padding\n", "shape")
+	return true
+}
+
+// compare builds the tx root of one block both ways.
+func compare(n int) {
+	hashes := sample(n)
+	fmt.Printf("\nTx root of %d transactions\n", n)
+
+	run := func(name string, fn func()) {
+		res := testing.Benchmark(func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				fn()
+			}
+		})
+		fmt.Printf("  %-16s %12s/op  %8d allocs/op\n", name, time.Duration(res.NsPerOp()), res.AllocsPerOp())
+	}
+	run("mpt root", func() { blockchain.ComputeMerkleRoot(blockchain.TxRootMPT, hashes) })
+	run("binary root", func() { blockchain.ComputeMerkleRoot(blockchain.TxRootBinary, hashes) })
+
+	// Kích thước proof trung bình trên mọi giao dịch
+	trie, _ := mpt.BuildMPTFromTxHashes(hashes)
+	var mptBytes, binaryBytes int
+	for i, h := range hashes {
+		p, _ := trie.GenerateProof(h)
+		mptBytes += len(p.Encode())
+		b, _ := merkle.Prove(hashes, i)
+		binaryBytes += len(b.Encode())
+	}
+	fmt.Println("Average proof size")
+	fmt.Printf("  %-16s %8d bytes\n", "mpt", mptBytes/n)
+	fmt.Printf("  %-16s %8d bytes\n", "binary", binaryBytes/n)
+}
@@ -1,34 +1,566 @@
 package main
 
+// Kiểm tra pkg/mpt với các test vector đã công bố của Merkle-Patricia Trie
+// (ethereum/tests, TrieTests/trieanyorder.json): root hash phải khớp, không
+// phụ thuộc thứ tự insert, và proof của mọi key phải kiểm tra được.
+//
+//	go run ./cmd/test/mpt_test
+
 import (
 	"blockchain-go/pkg/mpt"
-	"crypto/sha256"
+	"bytes"
 	"encoding/hex"
 	"fmt"
+	"math/rand"
+	"os"
+	"strings"
 )
 
-func hashTx(data string) []byte {
-	h := sha256.Sum256([]byte(data))
-	return h[:]
+type vector struct {
+	name string
+	kv   [][2]string // "0x..." là hex, còn lại là chuỗi
+	root string
+}
+
+var vectors = []vector{
+	{"empty", nil, "56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421"},
+	{"dogs", [][2]string{{"doe", "reindeer"}, {"dog", "puppy"}, {"dogglesworth", "cat"}}, "8aad789dff2f538bca5d8ea56e8abe10f4c7ba3a5dea95fea4cd6e7c3a1168d3"},
+	{"puppy", [][2]string{{"do", "verb"}, {"horse", "stallion"}, {"doge", "coin"}, {"dog", "puppy"}}, "5991bb8c6514148a29db676a14ac506cd2cd5775ace63c30a4fe457715e9ac84"},
+	{"foo", [][2]string{{"foo", "bar"}, {"food", "bass"}}, "17beaa1648bafa633cda809c90c04af50fc8aed3cb40d16efbddee6fdf63c4c3"},
+	{"smallValues", [][2]string{{"be", "e"}, {"dog", "puppy"}, {"bed", "d"}}, "3f67c7a47520f79faa29255d2d3c084a7a6df0453116ed7232ff10277a8be68b"},
+	{"testy", [][2]string{{"test", "test"}, {"te", "testy"}}, "8452568af70d8d140f58d941338542f645fcca50094b20f3c3d8c3df49337928"},
+	{"hex", [][2]string{{"0x0045", "0x0123456789"}, {"0x4500", "0x9876543210"}}, "285505fcabe84badc8aa310e2aae17eddc7d120aabec8a476902c8184b3a3503"},
+}
+
+// sequences are applied in the given order; an empty value deletes the key
+// (TrieTests/trietest.json).
+var sequences = []vector{
+	{"emptyValues", [][2]string{{"do", "verb"}, {"ether", "wookiedoo"}, {"horse", "stallion"}, {"shaman", "horse"}, {"doge", "coin"}, {"ether", ""}, {"dog", "puppy"}, {"shaman", ""}}, "5991bb8c6514148a29db676a14ac506cd2cd5775ace63c30a4fe457715e9ac84"},
+}
+
+// orders is how many shuffled insertion orders each vector is checked with.
+const orders = 20
+
+// get and proof are for tries in memory, which can not fail to load a node.
+func get(trie *mpt.MPT, key []byte) []byte {
+	value, err := trie.Get(key)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+func proof(trie *mpt.MPT, key []byte) mpt.Proof {
+	p, err := trie.GenerateProof(key)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func decode(s string) []byte {
+	if strings.HasPrefix(s, "0x") {
+		b, err := hex.DecodeString(s[2:])
+		if err != nil {
+			panic(err)
+		}
+		return b
+	}
+	return []byte(s)
 }
 
 func main() {
-	trie := mpt.NewMPT()
+	rng := rand.New(rand.NewSource(1))
+	failed := 0
+	for _, v := range vectors {
+		want, _ := hex.DecodeString(v.root)
+		ok := true
+		for i := 0; i < orders && ok; i++ {
+			kv := append([][2]string(nil), v.kv...)
+			if i > 0 {
+				rng.Shuffle(len(kv), func(a, b int) { kv[a], kv[b] = kv[b], kv[a] })
+			}
+			trie := mpt.NewMPT()
+			for _, pair := range kv {
+				trie.Insert(decode(pair[0]), decode(pair[1]))
+			}
+			if root := trie.RootHash(); !bytes.Equal(root, want) {
+				fmt.Printf("❌ %s: root %x, want %s\n", v.name, root, v.root)
+				ok = false
+				break
+			}
+			for _, pair := range kv {
+				key, value := decode(pair[0]), decode(pair[1])
+				if got := get(trie, key); !bytes.Equal(got, value) {
+					fmt.Printf("❌ %s: Get(%q) = %q\n", v.name, pair[0], got)
+					ok = false
+				}
+				if !mpt.VerifyProof(want, key, value, proof(trie, key)) {
+					fmt.Printf("❌ %s: proof of %q does not verify\n", v.name, pair[0])
+					ok = false
+				}
+				if mpt.VerifyProof(want, key, append(value, 'x'), proof(trie, key)) {
+					fmt.Printf("❌ %s: proof of %q verifies a wrong value\n", v.name, pair[0])
+					ok = false
+				}
+				if mpt.VerifyAbsence(want, key, proof(trie, key)) {
+					fmt.Printf("❌ %s: proof shows %q absent\n", v.name, pair[0])
+					ok = false
+				}
+				// Key chưa có trong trie: proof phải cho thấy nó vắng mặt
+				missing := append(append([]byte{}, key...), 0x7f)
+				if get(trie, missing) == nil && !mpt.VerifyAbsence(want, missing, proof(trie, missing)) {
+					fmt.Printf("❌ %s: absence of %q+0x7f does not verify\n", v.name, pair[0])
+					ok = false
+				}
+			}
+		}
+		if ok {
+			fmt.Printf("✅ %-12s %s\n", v.name, v.root)
+		} else {
+			failed++
+		}
+	}
+
+	for _, v := range sequences {
+		trie := mpt.NewMPT()
+		for _, pair := range v.kv {
+			trie.Insert(decode(pair[0]), decode(pair[1]))
+		}
+		if root := hex.EncodeToString(trie.RootHash()); root != v.root {
+			fmt.Printf("❌ %s: root %s, want %s\n", v.name, root, v.root)
+			failed++
+		} else {
+			fmt.Printf("✅ %-12s %s\n", v.name, v.root)
+		}
+	}
+
+	if !checkRandom(rng) {
+		failed++
+	}
+	if !checkDelete(rng) {
+		failed++
+	}
+	if !checkDatabase(rng) {
+		failed++
+	}
+	if !checkProofFormat() {
+		failed++
+	}
+	if !checkMalformed() {
+		failed++
+	}
 
-	txs := []string{"tx1", "tx2", "tx3"}
-	for _, tx := range txs {
-		h := hashTx(tx)
-		trie.Insert(h, h)
+	if failed > 0 {
+		fmt.Printf("\n%d check(s) failed\n", failed)
+		os.Exit(1)
 	}
+	fmt.Printf("\nAll %d vectors passed in %d insertion orders\n", len(vectors), orders)
+}
 
-	fmt.Println("Root Hash:", hex.EncodeToString(trie.RootHash()))
+// checkRandom inserts random keys of varying length, with values long enough
+// to need multi-byte RLP headers, in two orders and checks that the roots
+// are equal and that every key has a valid proof.
+func checkRandom(rng *rand.Rand) bool {
+	const keys = 2000
+	kv := make([][2][]byte, keys)
+	for i := range kv {
+		key := make([]byte, 1+rng.Intn(32))
+		rng.Read(key)
+		value := make([]byte, 1+rng.Intn(300))
+		rng.Read(value)
+		kv[i] = [2][]byte{key, value}
+	}
+	build := func() *mpt.MPT {
+		trie := mpt.NewMPT()
+		for _, pair := range kv {
+			trie.Insert(pair[0], pair[1])
+		}
+		return trie
+	}
+	// Key trùng nhau thì giá trị cuối cùng được giữ: bỏ các bản trước
+	last := make(map[string][]byte)
+	for _, pair := range kv {
+		last[string(pair[0])] = pair[1]
+	}
+
+	first := build()
+	rng.Shuffle(len(kv), func(a, b int) { kv[a], kv[b] = kv[b], kv[a] })
+	for i := range kv {
+		kv[i][1] = last[string(kv[i][0])]
+	}
+	second := build()
+	root := first.RootHash()
+	if !bytes.Equal(root, second.RootHash()) {
+		fmt.Printf("❌ random: root depends on the insertion order (%x, %x)\n", root, second.RootHash())
+		return false
+	}
+	for key, value := range last {
+		if !mpt.VerifyProof(root, []byte(key), value, proof(first, []byte(key))) {
+			fmt.Printf("❌ random: proof of %x does not verify\n", key)
+			return false
+		}
+	}
+	for i := 0; i < keys; i++ {
+		key := make([]byte, 1+rng.Intn(32))
+		rng.Read(key)
+		if _, found := last[string(key)]; found {
+			continue
+		}
+		if !mpt.VerifyAbsence(root, key, proof(first, key)) {
+			fmt.Printf("❌ random: absence of %x does not verify\n", key)
+			return false
+		}
+	}
+	fmt.Printf("✅ %-12s %d keys, root %x\n", "random", len(last), root)
+	return true
+}
+
+// checkDelete deletes half of a random trie, one key at a time and as a batch,
+// and checks that the result is the trie built from the other half alone:
+// deletion must collapse the nodes it leaves behind. It also checks that a
+// fork is not affected by changes to the original, and the other way round.
+func checkDelete(rng *rand.Rand) bool {
+	const keys = 1000
+	all := make(map[string][]byte)
+	for len(all) < keys {
+		key := make([]byte, 1+rng.Intn(8))
+		rng.Read(key)
+		// Giá trị ngắn để có nhiều node được nhúng vào node cha
+		all[string(key)] = []byte{byte(1 + rng.Intn(255))}
+	}
+	kept := mpt.NewMPT()
+	full := mpt.NewMPT()
+	removed := make(map[string][]byte)
+	for key, value := range all {
+		full.Insert([]byte(key), value)
+		if len(removed) < keys/2 {
+			removed[key] = nil
+		} else {
+			kept.Insert([]byte(key), value)
+		}
+	}
+	want := kept.RootHash()
+	fullRoot := full.RootHash()
 
-	// Generate proof for tx2
-	txHash := hashTx("tx2")
-	proof := trie.GenerateProof(txHash)
+	fork := full.Fork()
+	for key := range removed {
+		if ok, _ := fork.Delete([]byte(key)); !ok {
+			fmt.Printf("❌ delete: %x was not found\n", key)
+			return false
+		}
+		if ok, _ := fork.Delete([]byte(key)); ok {
+			fmt.Printf("❌ delete: %x was deleted twice\n", key)
+			return false
+		}
+	}
+	if root := fork.RootHash(); !bytes.Equal(root, want) {
+		fmt.Printf("❌ delete: root %x, want %x\n", root, want)
+		return false
+	}
+	if !bytes.Equal(full.RootHash(), fullRoot) {
+		fmt.Println("❌ delete: deleting from a fork changed the original")
+		return false
+	}
+	for key := range removed {
+		if get(fork, []byte(key)) != nil {
+			fmt.Printf("❌ delete: %x is still there\n", key)
+			return false
+		}
+		if !mpt.VerifyAbsence(want, []byte(key), proof(fork, []byte(key))) {
+			fmt.Printf("❌ delete: absence of %x does not verify\n", key)
+			return false
+		}
+	}
+
+	batch := full.Fork()
+	batch.Update(removed)
+	if root := batch.RootHash(); !bytes.Equal(root, want) {
+		fmt.Printf("❌ batch: root %x, want %x\n", root, want)
+		return false
+	}
+	// Một batch vừa thêm vừa xoá phải cho cùng kết quả như làm từng thay đổi
+	mixed := make(map[string][]byte)
+	one := batch.Fork()
+	for key, value := range all {
+		if rng.Intn(2) == 0 {
+			mixed[key] = nil
+			one.Delete([]byte(key))
+		} else {
+			mixed[key] = append(value, 'x')
+			one.Insert([]byte(key), mixed[key])
+		}
+	}
+	batch.Update(mixed)
+	if !bytes.Equal(batch.RootHash(), one.RootHash()) {
+		fmt.Printf("❌ batch: root %x, one by one %x\n", batch.RootHash(), one.RootHash())
+		return false
+	}
+	batch.Update(removed)
+	batch.Update(map[string][]byte{})
+	if !bytes.Equal(kept.RootHash(), want) || !bytes.Equal(full.RootHash(), fullRoot) {
+		fmt.Println("❌ batch: updating a fork changed the original")
+		return false
+	}
+
+	// Xoá hết thì phải về trie rỗng
+	for key := range all {
+		full.Delete([]byte(key))
+	}
+	if !bytes.Equal(full.RootHash(), mpt.EmptyRoot) || full.Root != nil {
+		fmt.Printf("❌ delete: root of the emptied trie is %x\n", full.RootHash())
+		return false
+	}
+	fmt.Printf("✅ %-12s %d of %d keys, root %x\n", "delete", len(removed), keys, want)
+	return true
+}
+
+// memoryStore is an mpt.KeyValueStore in a map.
+type memoryStore map[string][]byte
+
+func (s memoryStore) Get(key []byte) ([]byte, error) {
+	return s[string(key)], nil
+}
+
+func (s memoryStore) Write(changes map[string][]byte) error {
+	for key, value := range changes {
+		if value == nil {
+			delete(s, key)
+		} else {
+			s[key] = append([]byte{}, value...)
+		}
+	}
+	return nil
+}
+
+// nodes returns the keys of the nodes in the store.
+func (s memoryStore) nodes() map[string]bool {
+	nodes := make(map[string]bool)
+	for key := range s {
+		if strings.HasPrefix(key, "node/") {
+			nodes[key] = true
+		}
+	}
+	return nodes
+}
+
+// checkDatabase commits tries to a store, reopens them from their root, and
+// checks that replacing or dropping a root leaves exactly the nodes that the
+// kept roots still reach.
+func checkDatabase(rng *rand.Rand) bool {
+	const keys = 500
+	all := make(map[string][]byte)
+	for len(all) < keys {
+		key := make([]byte, 1+rng.Intn(20))
+		rng.Read(key)
+		value := make([]byte, 1+rng.Intn(40))
+		rng.Read(value)
+		all[string(key)] = value
+	}
+	store := memoryStore{}
+	db := mpt.NewDatabase(store)
+	trie := mpt.OpenMPT(db, nil)
+	if err := trie.Update(all); err != nil {
+		fmt.Printf("❌ database: %v\n", err)
+		return false
+	}
+	first, err := trie.Commit()
+	if err == nil {
+		err = db.SetRoot("a", first)
+	}
+	if err == nil {
+		err = db.Flush()
+	}
+	if err != nil {
+		fmt.Printf("❌ database: %v\n", err)
+		return false
+	}
+
+	firstNodes := len(store.nodes())
+
+	// Mở lại từ root: sau Flush mọi node được nạp từ store
+	reopened := mpt.OpenMPT(db, first)
+	for key, value := range all {
+		got, err := reopened.Get([]byte(key))
+		if err != nil || !bytes.Equal(got, value) {
+			fmt.Printf("❌ database: Get(%x) = %x, %v after reopening\n", key, got, err)
+			return false
+		}
+		p, err := reopened.GenerateProof([]byte(key))
+		if err != nil || !mpt.VerifyProof(first, []byte(key), value, p) {
+			fmt.Printf("❌ database: proof of %x after reopening: %v\n", key, err)
+			return false
+		}
+	}
+
+	// Đổi một phần key rồi thay root "a": các node chỉ root cũ dùng bị xoá
+	changes := make(map[string][]byte)
+	for key := range all {
+		switch rng.Intn(10) {
+		case 0:
+			changes[key] = nil
+		case 1:
+			changes[key] = []byte("changed")
+		}
+	}
+	if err := reopened.Update(changes); err != nil {
+		fmt.Printf("❌ database: %v\n", err)
+		return false
+	}
+	second, err := reopened.Commit()
+	if err == nil {
+		err = db.SetRoot("a", second)
+	}
+	if err == nil {
+		err = db.Flush()
+	}
+	if err != nil {
+		fmt.Printf("❌ database: %v\n", err)
+		return false
+	}
+	// Cùng nội dung, dựng từ đầu trong một store khác: phải có đúng các node đó
+	for key, value := range changes {
+		if value == nil {
+			delete(all, key)
+		} else {
+			all[key] = value
+		}
+	}
+	fresh := memoryStore{}
+	freshDB := mpt.NewDatabase(fresh)
+	rebuilt := mpt.OpenMPT(freshDB, nil)
+	rebuilt.Update(all)
+	root, _ := rebuilt.Commit()
+	freshDB.SetRoot("a", root)
+	freshDB.Flush()
+	if !bytes.Equal(root, second) {
+		fmt.Printf("❌ database: root %x, rebuilt %x\n", second, root)
+		return false
+	}
+	if got, want := store.nodes(), fresh.nodes(); len(got) != len(want) {
+		fmt.Printf("❌ database: %d nodes stored, %d reachable from the root\n", len(got), len(want))
+		return false
+	}
+
+	// Root "b" giữ lại trie thứ hai khi "a" được xoá
+	if err := db.SetRoot("b", second); err != nil {
+		fmt.Printf("❌ database: %v\n", err)
+		return false
+	}
+	db.SetRoot("a", mpt.EmptyRoot)
+	db.Flush()
+	check := mpt.OpenMPT(db, second)
+	for key, value := range all {
+		if got, err := check.Get([]byte(key)); err != nil || !bytes.Equal(got, value) {
+			fmt.Printf("❌ database: Get(%x) = %x, %v with only root b kept\n", key, got, err)
+			return false
+		}
+	}
+
+	// Commit mà không giữ root: không có node nào được lưu thêm
+	before := len(store.nodes())
+	check.Insert([]byte("unkept"), []byte("value"))
+	check.Commit()
+	db.Flush()
+	if after := len(store.nodes()); after != before {
+		fmt.Printf("❌ database: %d nodes after an unkept commit, %d before\n", after, before)
+		return false
+	}
+
+	stored := len(store.nodes())
+	db.SetRoot("b", mpt.EmptyRoot)
+	db.Flush()
+	if left := len(store.nodes()); left != 0 {
+		fmt.Printf("❌ database: %d nodes left after every root was dropped\n", left)
+		return false
+	}
+	if _, err := mpt.OpenMPT(mpt.NewDatabase(store), second).Get([]byte("x")); err == nil {
+		fmt.Println("❌ database: a pruned root can still be read")
+		return false
+	}
+	fmt.Printf("✅ %-12s %d nodes, %d after replacing the root, 0 after dropping it\n", "database", firstNodes, stored)
+	return true
+}
+
+// checkProofFormat round-trips proofs through Encode/DecodeProof and checks
+// that damaged proofs are rejected rather than misread.
+func checkProofFormat() bool {
+	trie := mpt.NewMPT()
+	for i := 0; i < 200; i++ {
+		key := []byte(fmt.Sprintf("key-%03d", i))
+		trie.Insert(key, bytes.Repeat(key, 5))
+	}
+	root := trie.RootHash()
+	key := []byte("key-042")
+	value := bytes.Repeat(key, 5)
+	keyProof := proof(trie, key)
+
+	decoded, err := mpt.DecodeProof(keyProof.Encode())
+	if err != nil || !mpt.VerifyProof(root, key, value, decoded) {
+		fmt.Printf("❌ format: encoded proof does not round-trip (%v)\n", err)
+		return false
+	}
+	encoded := keyProof.Encode()
+	if _, err := mpt.DecodeProof(encoded[:len(encoded)-1]); err == nil {
+		fmt.Println("❌ format: truncated proof decodes")
+		return false
+	}
+	if mpt.VerifyProof(root, key, value, keyProof[:len(keyProof)-1]) {
+		fmt.Println("❌ format: proof missing a node verifies")
+		return false
+	}
+	if _, err := mpt.ProofValue(root, key, keyProof[:len(keyProof)-1]); err == nil {
+		fmt.Println("❌ format: proof missing a node gives an answer")
+		return false
+	}
+	// Sửa một byte trong node: hash không còn khớp với tham chiếu của node cha
+	for i := range keyProof {
+		tampered := append(mpt.Proof{}, keyProof...)
+		tampered[i] = append([]byte{}, keyProof[i]...)
+		tampered[i][len(tampered[i])-1] ^= 1
+		if mpt.VerifyProof(root, key, value, tampered) || mpt.VerifyAbsence(root, key, tampered) {
+			fmt.Printf("❌ format: proof with node %d tampered verifies\n", i)
+			return false
+		}
+	}
+	fmt.Printf("✅ %-12s %d nodes, %d bytes encoded\n", "format", len(keyProof), len(encoded))
+	return true
+}
+
+// malformed are RLP inputs from a hostile peer: lengths past the end of the
+// data, and 8 byte lengths that overflow offset+size or do not fit in an
+// int.
+var malformed = map[string][]byte{
+	"list, 8 byte length overflows":   {0xff, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00},
+	"list, 8 byte length above int":   {0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00},
+	"string, 8 byte length overflows": {0xbf, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfb, 0x00},
+	"list, length past the end":       {0xf8, 0x40, 0x80},
+	"string, length missing":          {0xb9, 0x01},
+	"nested, length past the end":     {0xc3, 0xbf, 0x7f, 0xff},
+	"empty":                           {},
+}
 
-	fmt.Println("Proof for tx2:")
-	for _, p := range proof {
-		fmt.Println(hex.EncodeToString(p))
+// checkMalformed decodes malformed proofs and proof nodes; each must be
+// rejected without a panic.
+func checkMalformed() bool {
+	for name, data := range malformed {
+		panicked := func() (p any) {
+			defer func() { p = recover() }()
+			if _, err := mpt.DecodeProof(data); err == nil {
+				p = "decodes"
+				return
+			}
+			root := mpt.Keccak256(data)
+			if mpt.VerifyProof(root, []byte("key"), []byte("value"), mpt.Proof{data}) || mpt.VerifyAbsence(root, []byte("key"), mpt.Proof{data}) {
+				p = "verifies as a proof node"
+			}
+			return
+		}()
+		if panicked != nil {
+			fmt.Printf("❌ malformed: %s: %v\n", name, panicked)
+			return false
+		}
 	}
+	fmt.Printf("✅ %-12s %d malformed inputs rejected\n", "malformed", len(malformed))
+	return true
 }
@@ -25,5 +25,5 @@ func main() {
 
 	// Tamper test
 	tx2.Amount = 1000
-	fmt.Printf("🧪 Tampered Merkle Root: %x\n", blockchain.ComputeMerkleRoot([][]byte{tx1.Hash(), tx2.Hash()}))
+	fmt.Printf("🧪 Tampered Merkle Root: %x\n", blockchain.ComputeMerkleRoot(blockchain.TxRootMPT, [][]byte{tx1.Hash(), tx2.Hash()}))
 }
@@ -0,0 +1,216 @@
+package main
+
+// Kiểm tra hồi quy cho giao dịch giả mạo người gửi: mallory ký bằng khoá
+// của chính mình nhưng ghi sender là địa chỉ của alice. Chữ ký khớp với
+// public key trong giao dịch, nên chỉ việc so sender với địa chỉ của public
+// key mới chặn được. Giao dịch như vậy phải bị từ chối ở SendTransaction,
+// ở hàng đợi của leader và khi xác thực block.
+//
+//	go run ./cmd/test/sender_spoof
+
+import (
+	"blockchain-go/pkg/blockchain"
+	"blockchain-go/pkg/consensus"
+	"blockchain-go/pkg/genesis"
+	"blockchain-go/pkg/p2p_v2"
+	"blockchain-go/pkg/state"
+	"blockchain-go/pkg/storage"
+	"blockchain-go/pkg/validation"
+	"blockchain-go/pkg/wallet"
+	"blockchain-go/proto/nodepb"
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+const chainID = "spoof-test"
+
+var alice, bob, mallory *wallet.Wallet
+
+func main() {
+	alice, _ = wallet.CreateWallet()
+	bob, _ = wallet.CreateWallet()
+	mallory, _ = wallet.CreateWallet()
+
+	failed := 0
+	for _, check := range []func() bool{checkAuthenticate, checkSend, checkValidate, checkPending} {
+		if !check() {
+			failed++
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("\n%d check(s) failed\n", failed)
+		os.Exit(1)
+	}
+}
+
+func address(w *wallet.Wallet) []byte {
+	b, _ := hex.DecodeString(w.Address)
+	return b
+}
+
+// transfer returns a transaction from sender to bob signed with key.
+func transfer(sender []byte, key *ecdsa.PrivateKey, amount float64) *blockchain.Transaction {
+	tx := &blockchain.Transaction{Sender: sender, Receiver: address(bob), Amount: amount, Timestamp: time.Now().Unix(), ChainID: chainID}
+	wallet.SignTransaction(tx, key)
+	return tx
+}
+
+// node returns a consensus manager and state with the genesis block of a
+// chain where alice holds 1000 and mallory 10.
+func node(networker consensus.Networker) (*consensus.Manager, *state.State) {
+	g, err := genesis.Parse([]byte(fmt.Sprintf(`{"chain_id":%q,"timestamp":1700000000,"validators":[{"id":"node1"}],
+		"alloc":{"%s":{"balance":1000},"%s":{"balance":10}}}`, chainID, alice.Address, mallory.Address)))
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	// State và manager dùng chung một DB, như trong node
+	db := storage.OpenMemoryDB()
+	st, err := state.NewState(db)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	m := consensus.NewManager("node1", 1, db, st, nil, networker)
+	if err := m.CommitBlock(g.Block()); err != nil {
+		log.Fatalf("❌ genesis: %v", err)
+	}
+	return m, st
+}
+
+func block(m *consensus.Manager, st *state.State, txs ...*blockchain.Transaction) *blockchain.Block {
+	root, err := st.PreviewRoot(txs)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	return blockchain.NewChainBlock(chainID, m.LatestBlock.TxRootType, txs, m.LatestBlock.CurrentBlockHash, int(m.LatestBlock.Height)+1, root, "node1")
+}
+
+func checkAuthenticate() bool {
+	tampered := transfer(address(alice), alice.PrivateKey, 5)
+	tampered.Amount = 500
+	noKey := transfer(address(alice), alice.PrivateKey, 5)
+	noKey.PublicKey = nil
+	cases := []struct {
+		name string
+		tx   *blockchain.Transaction
+		want error
+	}{
+		{"spoofed sender", transfer(address(alice), mallory.PrivateKey, 5), blockchain.ErrSenderMismatch},
+		{"GENESIS sender", transfer([]byte("GENESIS"), mallory.PrivateKey, 5), blockchain.ErrSenderMismatch},
+		{"tampered amount", tampered, blockchain.ErrInvalidSignature},
+	}
+	if err := blockchain.AuthenticateTransaction(transfer(address(alice), alice.PrivateKey, 5)); err != nil {
+		fmt.Printf("❌ authenticate: valid transaction rejected: %v\n", err)
+		return false
+	}
+	for _, c := range cases {
+		if err := blockchain.AuthenticateTransaction(c.tx); !errors.Is(err, c.want) {
+			fmt.Printf("❌ authenticate: %s gives %v, want %v\n", c.name, err, c.want)
+			return false
+		}
+	}
+	if blockchain.AuthenticateTransaction(noKey) == nil {
+		fmt.Println("❌ authenticate: transaction without public key accepted")
+		return false
+	}
+	fmt.Printf("✅ %-12s %d forged transactions rejected\n", "authenticate", len(cases)+1)
+	return true
+}
+
+func checkSend() bool {
+	m, st := node(nil)
+	server := &p2p_v2.NodeServer{NodeID: "node1", ChainID: chainID, Consensus: m, State: st}
+	ctx := context.Background()
+
+	res, _ := server.SendTransaction(ctx, blockchain.TransactionToProto(transfer(address(alice), mallory.PrivateKey, 5)))
+	if res.Success || server.PendingCount() != 0 {
+		fmt.Printf("❌ send: spoofed transaction accepted: %s\n", res.Message)
+		return false
+	}
+	res, _ = server.SendTransaction(ctx, blockchain.TransactionToProto(transfer(address(alice), alice.PrivateKey, 5)))
+	if !res.Success || server.PendingCount() != 1 {
+		fmt.Printf("❌ send: valid transaction rejected: %s\n", res.Message)
+		return false
+	}
+	fmt.Printf("✅ %-12s SendTransaction rejects a spoofed sender\n", "send")
+	return true
+}
+
+func checkValidate() bool {
+	m, st := node(nil)
+	spoofed := block(m, st, transfer(address(alice), mallory.PrivateKey, 500))
+	if err := validation.ValidateBlock(spoofed, st, m.LatestBlock); !errors.Is(err, blockchain.ErrSenderMismatch) {
+		fmt.Printf("❌ validate: block with a spoofed sender gives %v\n", err)
+		return false
+	}
+	if blockchain.ValidateBlock(spoofed, m.LatestBlock) {
+		fmt.Println("❌ validate: blockchain.ValidateBlock accepts a spoofed sender")
+		return false
+	}
+	if err := m.CommitBlock(spoofed); err == nil {
+		fmt.Println("❌ validate: block with a spoofed sender committed")
+		return false
+	}
+
+	// Giao dịch GENESIS chỉ hợp lệ trong block genesis
+	mint := &blockchain.Transaction{Sender: []byte("GENESIS"), Receiver: address(mallory), Amount: 1000000, Timestamp: time.Now().Unix(), ChainID: chainID}
+	if err := validation.ValidateBlock(block(m, st, mint), st, m.LatestBlock); err == nil {
+		fmt.Println("❌ validate: GENESIS transfer accepted after the genesis block")
+		return false
+	}
+
+	if err := m.CommitBlock(block(m, st, transfer(address(alice), alice.PrivateKey, 500))); err != nil {
+		fmt.Printf("❌ validate: valid block rejected: %v\n", err)
+		return false
+	}
+	fmt.Printf("✅ %-12s blocks with a spoofed sender or a late GENESIS transfer rejected\n", "validate")
+	return true
+}
+
+// proposals records the blocks a leader proposes.
+type proposals chan *blockchain.Block
+
+func (p proposals) BroadcastProposedBlock(b *blockchain.Block)            { p <- b }
+func (p proposals) BroadcastCommittedBlock(*blockchain.Block)             {}
+func (p proposals) SendVoteToLeader(*nodepb.Vote) error                   { return nil }
+func (p proposals) FetchBlocks(int64, int64) ([]*blockchain.Block, error) { return nil, nil }
+
+// checkPending sends a spoofed and a valid transaction to a leader and
+// checks that the block it proposes only carries the valid one.
+func checkPending() bool {
+	proposed := make(proposals, 1)
+	m, st := node(proposed)
+	server := &p2p_v2.NodeServer{NodeID: "node1", IsLeader: true, ChainID: chainID, Consensus: m, State: st, MaxBlockTxs: 1}
+	ctx := context.Background()
+
+	if res, _ := server.SendTransaction(ctx, blockchain.TransactionToProto(transfer(address(alice), mallory.PrivateKey, 5))); res.Success {
+		fmt.Println("❌ pending: leader queued a spoofed transaction")
+		return false
+	}
+	valid := transfer(address(alice), alice.PrivateKey, 5)
+	if res, _ := server.SendTransaction(ctx, blockchain.TransactionToProto(valid)); !res.Success {
+		fmt.Printf("❌ pending: valid transaction rejected: %s\n", res.Message)
+		return false
+	}
+	select {
+	case b := <-proposed:
+		if len(b.Transactions) != 1 || string(b.Transactions[0].Hash()) != string(valid.Hash()) {
+			fmt.Printf("❌ pending: proposed block has %d transactions\n", len(b.Transactions))
+			return false
+		}
+		if err := validation.ValidateBlock(b, st, m.LatestBlock); err != nil {
+			fmt.Printf("❌ pending: proposed block is invalid: %v\n", err)
+			return false
+		}
+	case <-time.After(5 * time.Second):
+		fmt.Println("❌ pending: no block proposed")
+		return false
+	}
+	fmt.Printf("✅ %-12s spoofed transaction kept out of the leader's block\n", "pending")
+	return true
+}
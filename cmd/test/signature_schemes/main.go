@@ -0,0 +1,301 @@
+package main
+
+// Kiểm tra ba scheme chữ ký P-256, secp256k1 và Ed25519: khoá công khai của
+// các vector đã biết, chữ ký luôn dài 64 byte và kiểm tra được (cả khi r
+// hoặc s có byte 0 ở đầu, lỗi cũ của r || s không đệm), chữ ký bị sửa, mã
+// hoá không chuẩn hay dùng sai scheme đều bị từ chối, chữ ký P-256 kiểu cũ
+// chỉ được nhận trong block, và keystore lưu được mọi scheme. Cuối cùng đo
+// thời gian ký và kiểm tra.
+//
+//	go run ./cmd/test/signature_schemes --sigs 2000
+
+import (
+	"blockchain-go/pkg/blockchain"
+	"blockchain-go/pkg/cryptohelper"
+	"blockchain-go/pkg/wallet"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+var schemes = []cryptohelper.SchemeID{cryptohelper.P256, cryptohelper.Secp256k1, cryptohelper.Ed25519}
+
+func main() {
+	sigs := flag.Int("sigs", 2000, "signatures checked per scheme")
+	flag.Parse()
+
+	failed := 0
+	checks := []func() bool{checkVectors, func() bool { return checkSignatures(*sigs) }, checkRejected, checkLegacy, checkKeystore}
+	for _, check := range checks {
+		if !check() {
+			failed++
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("\n%d check(s) failed\n", failed)
+		os.Exit(1)
+	}
+	bench()
+}
+
+// orders are the group orders of the ECDSA curves; s must be at most half.
+var orders = map[cryptohelper.SchemeID]*big.Int{
+	cryptohelper.P256:      elliptic.P256().Params().N,
+	cryptohelper.Secp256k1: secp256k1.Params().N,
+}
+
+func highS(id cryptohelper.SchemeID, sig []byte) bool {
+	return orders[id] != nil && new(big.Int).Lsh(new(big.Int).SetBytes(sig[32:]), 1).Cmp(orders[id]) > 0
+}
+
+func one() []byte {
+	return append(make([]byte, 31), 1)
+}
+
+func lookup(id cryptohelper.SchemeID) cryptohelper.Scheme {
+	s, err := cryptohelper.LookupScheme(id)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// checkVectors checks public keys of known private keys, and the Ed25519
+// signature of test 1 of RFC 8032.
+func checkVectors() bool {
+	pubs := map[cryptohelper.SchemeID]string{
+		// Khoá bí mật 1: public key là điểm sinh G của đường cong
+		cryptohelper.P256:      "046b17d1f2e12c4247f8bce6e563a440f277037d812deb33a0f4a13945d898c2964fe342e2fe1a7f9b8ee7eb4a7c0f9e162bce33576b315ececbb6406837bf51f5",
+		cryptohelper.Secp256k1: "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+	}
+	for id, want := range pubs {
+		key, err := lookup(id).PrivateKeyFromBytes(one())
+		if err != nil || hex.EncodeToString(key.PublicKey()) != want {
+			fmt.Printf("❌ vectors: %s public key of 1 is %x (%v)\n", id, key.PublicKey(), err)
+			return false
+		}
+	}
+
+	seed, _ := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	key, _ := lookup(cryptohelper.Ed25519).PrivateKeyFromBytes(seed)
+	sig, _ := key.Sign(nil)
+	if hex.EncodeToString(key.PublicKey()) != "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a" ||
+		hex.EncodeToString(sig) != "e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b" {
+		fmt.Printf("❌ vectors: ed25519 key %x signs %x\n", key.PublicKey(), sig)
+		return false
+	}
+
+	// Địa chỉ P-256 giữ công thức cũ, nên ví đã có không đổi địa chỉ
+	w, _ := wallet.CreateWallet()
+	address, _ := lookup(cryptohelper.P256).Address(w.Key.PublicKey())
+	if hex.EncodeToString(address) != wallet.PublicKeyToAddress(w.PublicKey) {
+		fmt.Println("❌ vectors: P-256 address changed")
+		return false
+	}
+	fmt.Printf("✅ %-12s known keys, RFC 8032 signature, P-256 addresses unchanged\n", "vectors")
+	return true
+}
+
+// checkSignatures signs n random hashes with each scheme, and one transfer.
+func checkSignatures(n int) bool {
+	for _, id := range schemes {
+		key, _ := lookup(id).GenerateKey()
+		short := 0
+		for i := 0; i < n; i++ {
+			hash := make([]byte, 32)
+			rand.Read(hash)
+			sig, err := key.Sign(hash)
+			if err != nil || len(sig) != cryptohelper.SignatureSize || !lookup(id).Verify(key.PublicKey(), hash, sig) {
+				fmt.Printf("❌ signatures: %s signature %d of %d bytes does not verify (%v)\n", id, i, len(sig), err)
+				return false
+			}
+			if highS(id, sig) {
+				fmt.Printf("❌ signatures: %s signature %d has s in the upper half\n", id, i)
+				return false
+			}
+			// Trước đây r hoặc s có byte 0 ở đầu làm chữ ký ngắn đi và bị tách sai
+			if id != cryptohelper.Ed25519 && (sig[0] == 0 || sig[32] == 0) {
+				short++
+			}
+		}
+
+		w, _ := wallet.NewWallet(id)
+		sender, _ := hex.DecodeString(w.Address)
+		tx := &blockchain.Transaction{Sender: sender, Receiver: sender, Amount: 1, Timestamp: time.Now().Unix(), ChainID: "test"}
+		if err := w.SignTransaction(tx); err != nil || tx.SignatureScheme != id {
+			fmt.Printf("❌ signatures: %s transfer: %v\n", id, err)
+			return false
+		}
+		encoded, err := tx.Encode()
+		if err != nil {
+			fmt.Printf("❌ signatures: %s transfer: %v\n", id, err)
+			return false
+		}
+		decoded, err := blockchain.DecodeTransaction(encoded)
+		if err != nil || blockchain.AuthenticateTransaction(decoded) != nil {
+			fmt.Printf("❌ signatures: %s transfer does not authenticate after encoding (%v)\n", id, err)
+			return false
+		}
+		fmt.Printf("✅ %-12s %-9s %d signatures verify, %d with a leading zero in r or s\n", "signatures", id, n, short)
+	}
+	return true
+}
+
+// checkRejected damages signed transfers of each scheme.
+func checkRejected() bool {
+	rejected := 0
+	for _, id := range schemes {
+		w, _ := wallet.NewWallet(id)
+		sender, _ := hex.DecodeString(w.Address)
+		tx := &blockchain.Transaction{Sender: sender, Receiver: sender, Amount: 1, Timestamp: time.Now().Unix(), ChainID: "test"}
+		w.SignTransaction(tx)
+
+		bad := map[string]func(tx *blockchain.Transaction){
+			"flipped bit":  func(tx *blockchain.Transaction) { tx.Signature[10] ^= 1 },
+			"63 bytes":     func(tx *blockchain.Transaction) { tx.Signature = tx.Signature[:63] },
+			"65 bytes":     func(tx *blockchain.Transaction) { tx.Signature = append(tx.Signature, 0) },
+			"no signature": func(tx *blockchain.Transaction) { tx.Signature = nil },
+			"zero r":       func(tx *blockchain.Transaction) { copy(tx.Signature, make([]byte, 32)) },
+			"unknown":      func(tx *blockchain.Transaction) { tx.SignatureScheme = 7 },
+			"other amount": func(tx *blockchain.Transaction) { tx.Amount = 2 },
+			"other scheme": func(tx *blockchain.Transaction) { tx.SignatureScheme = (id + 1) % 3 },
+			"other key": func(tx *blockchain.Transaction) {
+				other, _ := wallet.NewWallet(id)
+				tx.PublicKey = other.Key.PublicKey()
+			},
+			"truncated key": func(tx *blockchain.Transaction) { tx.PublicKey = tx.PublicKey[1:] },
+		}
+		switch id {
+		case cryptohelper.Secp256k1:
+			// Khoá không nén là một cách mã hoá khác của cùng một khoá
+			bad["uncompressed key"] = func(tx *blockchain.Transaction) {
+				pub, _ := secp256k1.ParsePubKey(tx.PublicKey)
+				tx.PublicKey = pub.SerializeUncompressed()
+			}
+		case cryptohelper.P256:
+			bad["compressed key"] = func(tx *blockchain.Transaction) {
+				tx.PublicKey = append([]byte{byte(2 + w.PublicKey.Y.Bit(0))}, w.PublicKey.X.FillBytes(make([]byte, 32))...)
+			}
+		}
+		if id != cryptohelper.Ed25519 {
+			// (r, N-s) kiểm tra được với ecdsa, nhưng chỉ dạng s thấp được nhận
+			bad["high s"] = func(tx *blockchain.Transaction) {
+				s := new(big.Int).SetBytes(tx.Signature[32:])
+				s.Sub(orders[id], s)
+				s.FillBytes(tx.Signature[32:])
+			}
+		}
+		for name, damage := range bad {
+			copied := *tx
+			copied.Signature = bytes.Clone(tx.Signature)
+			damage(&copied)
+			if blockchain.AuthenticateTransaction(&copied) == nil {
+				fmt.Printf("❌ rejected: %s transfer with %s authenticates\n", id, name)
+				return false
+			}
+			rejected++
+		}
+	}
+	if _, err := cryptohelper.ParseScheme("rsa"); !errors.Is(err, cryptohelper.ErrUnknownScheme) {
+		fmt.Printf("❌ rejected: scheme rsa parses (%v)\n", err)
+		return false
+	}
+	fmt.Printf("✅ %-12s %d damaged transfers rejected\n", "rejected", rejected)
+	return true
+}
+
+// checkLegacy signs P-256 transfers the way wallets did before signature
+// schemes: r || s without padding and s in either half. Such a transfer is
+// refused as a new transaction but still validates in a block, so chains
+// made before keep validating.
+func checkLegacy() bool {
+	w, _ := wallet.NewWallet(cryptohelper.P256)
+	sender, _ := hex.DecodeString(w.Address)
+	prev := &blockchain.Block{}
+	prev.CurrentBlockHash = make([]byte, 32)
+
+	// Một chữ ký có s ở nửa trên và một chữ ký có r chỉ 31 byte
+	var txs []*blockchain.Transaction
+	for len(txs) < 2 {
+		tx := &blockchain.Transaction{Sender: sender, Receiver: sender, Amount: 1, Timestamp: time.Now().Unix(), ChainID: "test", PublicKey: w.Key.PublicKey()}
+		r, s, _ := ecdsa.Sign(rand.Reader, w.PrivateKey, tx.Hash())
+		high := new(big.Int).Lsh(s, 1).Cmp(orders[cryptohelper.P256]) > 0
+		switch {
+		case len(txs) == 0 && high && len(r.Bytes()) == 32:
+		case len(txs) == 1 && len(r.Bytes()) == 31 && len(s.Bytes()) == 32:
+		default:
+			continue
+		}
+		tx.Signature = append(r.Bytes(), s.Bytes()...)
+		txs = append(txs, tx)
+	}
+	for i, tx := range txs {
+		if blockchain.AuthenticateTransaction(tx) == nil {
+			fmt.Printf("❌ legacy: legacy signature %d accepted for a new transaction\n", i)
+			return false
+		}
+		if err := blockchain.AuthenticateBlockTransaction(tx); err != nil {
+			fmt.Printf("❌ legacy: legacy signature %d rejected in a block: %v\n", i, err)
+			return false
+		}
+	}
+	block := blockchain.NewChainBlock("test", blockchain.TxRootMPT, txs, prev.CurrentBlockHash, 1, nil, "node1")
+	if !blockchain.ValidateBlock(block, prev) {
+		fmt.Println("❌ legacy: block with legacy signatures does not validate")
+		return false
+	}
+	fmt.Printf("✅ %-12s old P-256 signatures refused for new transactions, accepted in blocks\n", "legacy")
+	return true
+}
+
+// checkKeystore stores a wallet of each scheme and loads it back.
+func checkKeystore() bool {
+	for _, id := range schemes {
+		w, _ := wallet.NewWallet(id)
+		data, err := wallet.EncryptKey(w, "pass")
+		if err != nil {
+			fmt.Printf("❌ keystore: %s: %v\n", id, err)
+			return false
+		}
+		loaded, err := wallet.DecryptKey(data, "pass")
+		if err != nil || loaded.Address != w.Address || loaded.Scheme() != id || !bytes.Equal(loaded.Key.Bytes(), w.Key.Bytes()) {
+			fmt.Printf("❌ keystore: %s wallet loads as %v (%v)\n", id, loaded, err)
+			return false
+		}
+	}
+	fmt.Printf("✅ %-12s wallets of every scheme stored and loaded\n", "keystore")
+	return true
+}
+
+func bench() {
+	fmt.Println("\nSign and verify one transaction hash")
+	hash := sha256.Sum256([]byte("tx"))
+	for _, id := range schemes {
+		key, _ := lookup(id).GenerateKey()
+		sig, _ := key.Sign(hash[:])
+		sign := testing.Benchmark(func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				key.Sign(hash[:])
+			}
+		})
+		verify := testing.Benchmark(func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				lookup(id).Verify(key.PublicKey(), hash[:], sig)
+			}
+		})
+		fmt.Printf("  %-10s sign %10s/op  verify %10s/op  public key %2d bytes\n", id,
+			time.Duration(sign.NsPerOp()), time.Duration(verify.NsPerOp()), len(key.PublicKey()))
+	}
+}
@@ -0,0 +1,313 @@
+package main
+
+// Kiểm tra hợp đồng của storage.Store trên cả ba engine (memory, LevelDB,
+// bolt): Get/Has/Put/Delete, batch được áp dụng nguyên khối và theo thứ tự,
+// iterator đi theo thứ tự byte trong đúng range (kể cả qua nhiều trang của
+// bolt), snapshot không thấy ghi sau nó, và dữ liệu còn sau khi mở lại. Các
+// package khác dùng memory store trong kiểm tra của mình, nên nó phải cư xử
+// giống hệt hai engine trên đĩa.
+//
+//	go run ./cmd/test/store_contract
+
+import (
+	"blockchain-go/pkg/storage"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+type check struct {
+	name string
+	run  func(s storage.Store) error
+}
+
+var checks = []check{
+	{"get-put", checkGetPut},
+	{"delete", checkDelete},
+	{"batch", checkBatch},
+	{"iterator", checkIterator},
+	{"snapshot", checkSnapshot},
+}
+
+func main() {
+	dir, err := os.MkdirTemp("", "store-contract")
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	defer os.RemoveAll(dir)
+
+	failed := 0
+	for _, engine := range []storage.Engine{storage.Memory, storage.LevelDB, storage.Bolt} {
+		path := filepath.Join(dir, string(engine))
+		for _, c := range checks {
+			if err := runCheck(engine, filepath.Join(path, c.name), c.run); err != nil {
+				fmt.Printf("❌ %-8s %-10s %v\n", engine, c.name, err)
+				failed++
+			}
+		}
+		if engine != storage.Memory {
+			if err := checkReopen(engine, filepath.Join(path, "reopen")); err != nil {
+				fmt.Printf("❌ %-8s %-10s %v\n", engine, "reopen", err)
+				failed++
+			}
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("\n%d check(s) failed\n", failed)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ %d checks passed on memory, leveldb and bolt\n", len(checks)*3+2)
+}
+
+// runCheck runs fn on a fresh store of engine in path.
+func runCheck(engine storage.Engine, path string, fn func(s storage.Store) error) error {
+	s, err := storage.OpenStore(engine, path)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	return fn(s)
+}
+
+// expect checks the value stored under key; a nil want means absent.
+func expect(r storage.Reader, key string, want []byte) error {
+	value, err := r.Get([]byte(key))
+	has, hasErr := r.Has([]byte(key))
+	if hasErr != nil {
+		return fmt.Errorf("Has(%q): %w", key, hasErr)
+	}
+	if want == nil {
+		if !errors.Is(err, storage.ErrNotFound) || has {
+			return fmt.Errorf("%q: got %q, %v, has %v, want ErrNotFound", key, value, err, has)
+		}
+		return nil
+	}
+	if err != nil || !bytes.Equal(value, want) || !has {
+		return fmt.Errorf("%q: got %q, %v, has %v, want %q", key, value, err, has, want)
+	}
+	return nil
+}
+
+func checkGetPut(s storage.Store) error {
+	if err := expect(s, "a", nil); err != nil {
+		return err
+	}
+	value := []byte("one")
+	if err := s.Put([]byte("a"), value); err != nil {
+		return err
+	}
+	// Store giữ bản sao: sửa slice đã ghi hay slice đọc ra không đổi dữ liệu
+	value[0] = 'X'
+	got, _ := s.Get([]byte("a"))
+	got[0] = 'Y'
+	if err := expect(s, "a", []byte("one")); err != nil {
+		return fmt.Errorf("value not copied: %w", err)
+	}
+	if err := s.Put([]byte("a"), []byte("two")); err != nil {
+		return err
+	}
+	if err := s.Put([]byte("empty"), []byte{}); err != nil {
+		return err
+	}
+	if err := expect(s, "a", []byte("two")); err != nil {
+		return err
+	}
+	return expect(s, "empty", []byte{})
+}
+
+func checkDelete(s storage.Store) error {
+	s.Put([]byte("a"), []byte("1"))
+	if err := s.Delete([]byte("a")); err != nil {
+		return err
+	}
+	if err := s.Delete([]byte("missing")); err != nil {
+		return fmt.Errorf("deleting a missing key: %w", err)
+	}
+	return expect(s, "a", nil)
+}
+
+func checkBatch(s storage.Store) error {
+	s.Put([]byte("gone"), []byte("1"))
+	batch := s.NewBatch()
+	batch.Put([]byte("a"), []byte("1"))
+	batch.Put([]byte("b"), []byte("1"))
+	batch.Delete([]byte("b"))
+	batch.Delete([]byte("c"))
+	batch.Put([]byte("c"), []byte("2"))
+	batch.Delete([]byte("gone"))
+	if batch.Len() != 6 {
+		return fmt.Errorf("batch has %d operations, want 6", batch.Len())
+	}
+	if err := expect(s, "a", nil); err != nil {
+		return fmt.Errorf("batch visible before Write: %w", err)
+	}
+	if err := s.Write(batch); err != nil {
+		return err
+	}
+	for key, want := range map[string][]byte{"a": []byte("1"), "b": nil, "c": []byte("2"), "gone": nil} {
+		if err := expect(s, key, want); err != nil {
+			return fmt.Errorf("operations not applied in order: %w", err)
+		}
+	}
+
+	batch.Reset()
+	if batch.Len() != 0 {
+		return errors.New("Reset left operations in the batch")
+	}
+	batch.Put([]byte("d"), []byte("3"))
+	if err := s.Write(batch); err != nil {
+		return err
+	}
+	if err := expect(s, "a", []byte("1")); err != nil {
+		return fmt.Errorf("reset batch wrote old operations: %w", err)
+	}
+	return nil
+}
+
+// iteratorKeys are more than two bolt pages, with bytes 0x00 and 0xff at the
+// edges of the ranges.
+func iteratorKeys() [][]byte {
+	var keys [][]byte
+	for i := 0; i < 600; i++ {
+		keys = append(keys, []byte(fmt.Sprintf("k/%04d", i)))
+	}
+	return append(keys, []byte("k"), []byte("k/\xff"), []byte("k0"), []byte("j\xff\xff"), []byte{0})
+}
+
+func collect(r storage.Reader, rng *storage.Range) ([]string, error) {
+	it := r.NewIterator(rng)
+	defer it.Release()
+	var keys []string
+	for it.Next() {
+		keys = append(keys, string(it.Key()))
+		if !bytes.Equal(it.Value(), append([]byte("v"), it.Key()...)) {
+			return nil, fmt.Errorf("value of %q is %q", it.Key(), it.Value())
+		}
+	}
+	if it.Next() {
+		return nil, errors.New("Next is true after the end")
+	}
+	return keys, it.Error()
+}
+
+// wantKeys returns the keys of rng in byte order.
+func wantKeys(rng *storage.Range) []string {
+	var keys []string
+	for _, k := range iteratorKeys() {
+		if (rng == nil || rng.Start == nil || bytes.Compare(k, rng.Start) >= 0) &&
+			(rng == nil || rng.Limit == nil || bytes.Compare(k, rng.Limit) < 0) {
+			keys = append(keys, string(k))
+		}
+	}
+	// Chuỗi Go được so sánh theo byte
+	sort.Strings(keys)
+	return keys
+}
+
+func checkIterator(s storage.Store) error {
+	batch := s.NewBatch()
+	for _, k := range iteratorKeys() {
+		batch.Put(k, append([]byte("v"), k...))
+	}
+	if err := s.Write(batch); err != nil {
+		return err
+	}
+
+	ranges := map[string]*storage.Range{
+		"all":          nil,
+		"open":         {},
+		"prefix k/":    storage.PrefixRange([]byte("k/")),
+		"start only":   {Start: []byte("k/0590")},
+		"limit only":   {Limit: []byte("k/0003")},
+		"start, limit": {Start: []byte("k/0100"), Limit: []byte("k/0400")},
+		"empty":        {Start: []byte("k/0300"), Limit: []byte("k/0300")},
+		"after last":   {Start: []byte("z")},
+	}
+	for name, rng := range ranges {
+		got, err := collect(s, rng)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		want := wantKeys(rng)
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			return fmt.Errorf("%s: got %d keys, want %d (first %q)", name, len(got), len(want), first(got))
+		}
+	}
+	return nil
+}
+
+func first(keys []string) string {
+	if len(keys) == 0 {
+		return ""
+	}
+	return keys[0]
+}
+
+func checkSnapshot(s storage.Store) error {
+	s.Put([]byte("a"), []byte("va"))
+	s.Put([]byte("b"), []byte("vb"))
+	snap, err := s.NewSnapshot()
+	if err != nil {
+		return err
+	}
+	defer snap.Release()
+
+	s.Put([]byte("a"), []byte("changed"))
+	s.Delete([]byte("b"))
+	s.Put([]byte("c"), []byte("vc"))
+	batch := s.NewBatch()
+	batch.Put([]byte("d"), []byte("vd"))
+	if err := s.Write(batch); err != nil {
+		return err
+	}
+
+	for key, want := range map[string][]byte{"a": []byte("va"), "b": []byte("vb"), "c": nil, "d": nil} {
+		if err := expect(snap, key, want); err != nil {
+			return fmt.Errorf("snapshot sees a later write: %w", err)
+		}
+	}
+	it := snap.NewIterator(nil)
+	defer it.Release()
+	var keys []string
+	for it.Next() {
+		keys = append(keys, string(it.Key()))
+	}
+	if fmt.Sprint(keys) != "[a b]" {
+		return fmt.Errorf("snapshot iterator sees %v, want [a b]", keys)
+	}
+	return expect(s, "a", []byte("changed"))
+}
+
+// checkReopen closes a store on disk and checks its data survived.
+func checkReopen(engine storage.Engine, path string) error {
+	s, err := storage.OpenStore(engine, path)
+	if err != nil {
+		return err
+	}
+	s.Put([]byte("a"), []byte("1"))
+	batch := s.NewBatch()
+	batch.Put([]byte("b"), []byte("2"))
+	s.Write(batch)
+	s.Delete([]byte("a"))
+	if err := s.Compact(); err != nil {
+		s.Close()
+		return fmt.Errorf("compact: %w", err)
+	}
+	if err := s.Close(); err != nil {
+		return err
+	}
+
+	s, err = storage.OpenStore(engine, path)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	if err := expect(s, "a", nil); err != nil {
+		return err
+	}
+	return expect(s, "b", []byte("2"))
+}
@@ -0,0 +1,173 @@
+package main
+
+// Đo thời gian băm và commit Merkle-Patricia Trie từ 10 nghìn đến 1 triệu
+// key: băm toàn bộ trie trên 1 CPU và trên mọi CPU, băm lại sau khi đổi một
+// vài key (chỉ các node trên đường đi phải băm lại), và commit vào database.
+//
+//	go run ./cmd/test/trie_bench --sizes 10000,100000,1000000
+
+import (
+	"blockchain-go/pkg/mpt"
+	"crypto/sha256"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"log"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memoryStore is a KeyValueStore in memory, so commit is measured without
+// disk writes.
+type memoryStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func (s *memoryStore) Get(key []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data[string(key)], nil
+}
+
+func (s *memoryStore) Write(changes map[string][]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, v := range changes {
+		if v == nil {
+			delete(s.data, k)
+		} else {
+			s.data[k] = v
+		}
+	}
+	return nil
+}
+
+func main() {
+	sizes := flag.String("sizes", "10000,100000,1000000", "comma separated numbers of keys")
+	changes := flag.Int("changes", 1000, "keys changed before a rehash")
+	flag.Parse()
+
+	cpus := runtime.GOMAXPROCS(0)
+	if cpus == 1 {
+		fmt.Print("Only 1 CPU: the parallel rows are skipped\n\n")
+	}
+	for _, s := range strings.Split(*sizes, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || n <= 0 {
+			log.Fatalf("❌ invalid size %q", s)
+		}
+		bench(n, *changes, cpus)
+	}
+}
+
+// entries returns n keys with their values; seed changes the values only.
+func entries(n, seed int) map[string][]byte {
+	kv := make(map[string][]byte, n)
+	for i := 0; i < n; i++ {
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], uint64(i))
+		key := sha256.Sum256(b[:])
+		value := sha256.Sum256(append(key[:], byte(seed)))
+		kv[string(key[:])] = value[:]
+	}
+	return kv
+}
+
+func build(kv map[string][]byte) *mpt.MPT {
+	trie := mpt.NewMPT()
+	if err := trie.Update(kv); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	return trie
+}
+
+// timeOn runs fn with GOMAXPROCS set to procs.
+func timeOn(procs int, fn func()) time.Duration {
+	old := runtime.GOMAXPROCS(procs)
+	defer runtime.GOMAXPROCS(old)
+	runtime.GC()
+	start := time.Now()
+	fn()
+	return time.Since(start)
+}
+
+func bench(n, changes, cpus int) {
+	kv := entries(n, 0)
+	reps := 3
+	if n >= 1000000 {
+		reps = 1
+	}
+	fmt.Printf("Trie with %d keys (%d CPUs)\n", n, cpus)
+
+	// Băm toàn bộ: mỗi lần đo dùng một trie mới dựng, chưa có hash nào
+	var root []byte
+	full := func(procs int) time.Duration {
+		best := time.Duration(0)
+		for i := 0; i < reps; i++ {
+			trie := build(kv)
+			d := timeOn(procs, func() { root = trie.RootHash() })
+			if best == 0 || d < best {
+				best = d
+			}
+		}
+		return best
+	}
+	serial := full(1)
+	fmt.Printf("  %-28s %12s\n", "full hash, 1 CPU", serial)
+	if cpus > 1 {
+		parallel := full(cpus)
+		fmt.Printf("  %-28s %12s  (%.1fx)\n", fmt.Sprintf("full hash, %d CPUs", cpus), parallel, float64(serial)/float64(parallel))
+	}
+
+	// Băm lại sau khi đổi một key và sau khi đổi nhiều key
+	trie := build(kv)
+	trie.RootHash()
+	changed := entries(changes, 1)
+	const single = 100
+	i := 0
+	var one time.Duration
+	for key, value := range changed {
+		if i == single {
+			break
+		}
+		trie.Insert([]byte(key), value)
+		one += timeOn(cpus, func() { trie.RootHash() })
+		i++
+	}
+	one /= single
+	fmt.Printf("  %-28s %12s  (%.0fx faster than a full hash)\n", "rehash after 1 change", one, float64(serial)/float64(one))
+	trie.Update(entries(changes, 2))
+	many := timeOn(cpus, func() { trie.RootHash() })
+	fmt.Printf("  %-28s %12s\n", fmt.Sprintf("rehash after %d changes", changes), many)
+
+	// Commit vào database trong bộ nhớ, gồm cả việc băm
+	commit := func(procs int) time.Duration {
+		db := mpt.NewDatabase(&memoryStore{data: make(map[string][]byte)})
+		trie := mpt.OpenMPT(db, nil)
+		if err := trie.Update(kv); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		var committed []byte
+		d := timeOn(procs, func() {
+			var err error
+			if committed, err = trie.Commit(); err != nil {
+				log.Fatalf("❌ %v", err)
+			}
+		})
+		if string(committed) != string(root) {
+			log.Fatalf("❌ committed root %x, hashed root %x", committed, root)
+		}
+		return d
+	}
+	committed := commit(1)
+	fmt.Printf("  %-28s %12s\n", "commit, 1 CPU", committed)
+	if cpus > 1 {
+		parallel := commit(cpus)
+		fmt.Printf("  %-28s %12s  (%.1fx)\n", fmt.Sprintf("commit, %d CPUs", cpus), parallel, float64(committed)/float64(parallel))
+	}
+	fmt.Println()
+}
@@ -4,14 +4,19 @@ go 1.24.2
 
 require (
 	github.com/syndtr/goleveldb v1.0.0
+	go.etcd.io/bbolt v1.4.3
+	golang.org/x/crypto v0.39.0
+	golang.org/x/term v0.32.0
 	google.golang.org/grpc v1.73.0
 )
 
+require github.com/decred/dcrd/dcrec/secp256k1/v4 v4.4.1
+
 require (
 	github.com/golang/snappy v0.0.0-20180518054509-2e65f85255db // indirect
 	golang.org/x/net v0.38.0 // indirect
 	golang.org/x/sys v0.33.0 // indirect
-	golang.org/x/text v0.26.0 // indirect
+	golang.org/x/text v0.26.0
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20250324211829-b45e905df463 // indirect
 	google.golang.org/protobuf v1.36.6
 )
@@ -0,0 +1,241 @@
+package archive
+
+import (
+	"blockchain-go/pkg/blockchain"
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// An archive holds a contiguous range of blocks:
+//
+//	magic "BCGOARCH" | version byte | flags byte | records...
+//
+// With FlagGzip everything after the flags byte is one gzip stream. Each
+// record is a big-endian uint32 length, the payload and the CRC-32C of the
+// payload. The first record is the JSON Header; every following record is a
+// block as encoded by blockchain.Block.Encode, in height order.
+
+var magic = []byte("BCGOARCH")
+
+// FormatVersion is the archive layout written by this code.
+const FormatVersion = 1
+
+// FlagGzip marks an archive whose records are gzip-compressed.
+const FlagGzip = 1 << 0
+
+// maxRecordSize rejects corrupted lengths before allocating.
+const maxRecordSize = 64 << 20
+
+// BlockEncoding names the encoding of the block records in the header.
+const BlockEncoding = "nodepb.Block/deterministic-protobuf"
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ErrCorrupted is returned when an archive fails a format or checksum check.
+var ErrCorrupted = errors.New("corrupted archive")
+
+// Header describes the blocks in an archive.
+type Header struct {
+	Version     int    `json:"version"`
+	Encoding    string `json:"encoding"`
+	GenesisHash []byte `json:"genesis_hash"`
+	From        int64  `json:"from"`
+	To          int64  `json:"to"`
+	CreatedAt   int64  `json:"created_at"`
+}
+
+// Blocks returns the number of blocks in the archive.
+func (h *Header) Blocks() int64 {
+	return h.To - h.From + 1
+}
+
+// Writer writes an archive. Blocks must be written in order from
+// Header.From to Header.To; Close fails if any are missing.
+type Writer struct {
+	out    io.Writer
+	buf    *bufio.Writer
+	gz     *gzip.Writer
+	header Header
+	next   int64
+}
+
+// NewWriter writes the archive preamble and header to w.
+func NewWriter(w io.Writer, header Header, compress bool) (*Writer, error) {
+	if header.To < header.From {
+		return nil, fmt.Errorf("empty block range %d..%d", header.From, header.To)
+	}
+	header.Version = FormatVersion
+	header.Encoding = BlockEncoding
+
+	flags := byte(0)
+	if compress {
+		flags |= FlagGzip
+	}
+	buf := bufio.NewWriter(w)
+	buf.Write(magic)
+	buf.WriteByte(FormatVersion)
+	buf.WriteByte(flags)
+
+	aw := &Writer{out: buf, buf: buf, header: header, next: header.From}
+	if compress {
+		aw.gz = gzip.NewWriter(buf)
+		aw.out = aw.gz
+	}
+	data, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	if err := aw.writeRecord(data); err != nil {
+		return nil, err
+	}
+	return aw, nil
+}
+
+// WriteBlock appends the next block of the range.
+func (w *Writer) WriteBlock(block *blockchain.Block) error {
+	if w.next > w.header.To {
+		return fmt.Errorf("block %d is outside the range %d..%d", block.Height, w.header.From, w.header.To)
+	}
+	if block.Height != w.next {
+		return fmt.Errorf("expected block %d, got %d", w.next, block.Height)
+	}
+	data, err := block.Encode()
+	if err != nil {
+		return err
+	}
+	if err := w.writeRecord(data); err != nil {
+		return fmt.Errorf("failed to write block %d: %w", block.Height, err)
+	}
+	w.next++
+	return nil
+}
+
+// Close flushes the archive. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if w.next != w.header.To+1 {
+		return fmt.Errorf("archive ends at block %d, expected %d", w.next-1, w.header.To)
+	}
+	if w.gz != nil {
+		if err := w.gz.Close(); err != nil {
+			return err
+		}
+	}
+	return w.buf.Flush()
+}
+
+func (w *Writer) writeRecord(data []byte) error {
+	var prefix [4]byte
+	binary.BigEndian.PutUint32(prefix[:], uint32(len(data)))
+	if _, err := w.out.Write(prefix[:]); err != nil {
+		return err
+	}
+	if _, err := w.out.Write(data); err != nil {
+		return err
+	}
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc32.Checksum(data, crcTable))
+	_, err := w.out.Write(sum[:])
+	return err
+}
+
+// Reader reads an archive written by Writer.
+type Reader struct {
+	in     io.Reader
+	header Header
+	next   int64
+}
+
+// NewReader checks the preamble and reads the header.
+func NewReader(r io.Reader) (*Reader, error) {
+	buf := bufio.NewReader(r)
+	preamble := make([]byte, len(magic)+2)
+	if _, err := io.ReadFull(buf, preamble); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+	if string(preamble[:len(magic)]) != string(magic) {
+		return nil, fmt.Errorf("%w: not a block archive", ErrCorrupted)
+	}
+	if version := preamble[len(magic)]; version != FormatVersion {
+		return nil, fmt.Errorf("unsupported archive version %d", version)
+	}
+
+	ar := &Reader{in: buf}
+	if preamble[len(magic)+1]&FlagGzip != 0 {
+		gz, err := gzip.NewReader(buf)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCorrupted, err)
+		}
+		ar.in = gz
+	}
+
+	data, err := ar.readRecord()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	if err := json.Unmarshal(data, &ar.header); err != nil {
+		return nil, fmt.Errorf("%w: bad header: %v", ErrCorrupted, err)
+	}
+	if ar.header.Encoding != BlockEncoding {
+		return nil, fmt.Errorf("unsupported block encoding %q", ar.header.Encoding)
+	}
+	if ar.header.To < ar.header.From {
+		return nil, fmt.Errorf("%w: empty block range", ErrCorrupted)
+	}
+	ar.next = ar.header.From
+	return ar, nil
+}
+
+// Header returns the archive header.
+func (r *Reader) Header() Header {
+	return r.header
+}
+
+// Next returns the next block, or io.EOF after the last one.
+func (r *Reader) Next() (*blockchain.Block, error) {
+	if r.next > r.header.To {
+		var b [1]byte
+		if n, _ := r.in.Read(b[:]); n > 0 {
+			return nil, fmt.Errorf("%w: data after block %d", ErrCorrupted, r.header.To)
+		}
+		return nil, io.EOF
+	}
+	data, err := r.readRecord()
+	if err != nil {
+		return nil, fmt.Errorf("block %d: %w", r.next, err)
+	}
+	block, err := blockchain.DecodeBlock(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: block %d: %v", ErrCorrupted, r.next, err)
+	}
+	if block.Height != r.next {
+		return nil, fmt.Errorf("%w: expected block %d, got %d", ErrCorrupted, r.next, block.Height)
+	}
+	r.next++
+	return block, nil
+}
+
+func (r *Reader) readRecord() ([]byte, error) {
+	var prefix [4]byte
+	if _, err := io.ReadFull(r.in, prefix[:]); err != nil {
+		return nil, fmt.Errorf("%w: truncated: %v", ErrCorrupted, err)
+	}
+	size := binary.BigEndian.Uint32(prefix[:])
+	if size > maxRecordSize {
+		return nil, fmt.Errorf("%w: record of %d bytes", ErrCorrupted, size)
+	}
+	data := make([]byte, size+4)
+	if _, err := io.ReadFull(r.in, data); err != nil {
+		return nil, fmt.Errorf("%w: truncated: %v", ErrCorrupted, err)
+	}
+	payload, sum := data[:size], binary.BigEndian.Uint32(data[size:])
+	if crc32.Checksum(payload, crcTable) != sum {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrCorrupted)
+	}
+	return payload, nil
+}
@@ -1,50 +1,92 @@
 package blockchain
 
 import (
-	"blockchain-go/pkg/cryptohelper"
-	"blockchain-go/pkg/mpt"
 	"blockchain-go/proto/nodepb"
 	"bytes"
-	"crypto/sha256"
-	"encoding/json"
 	"fmt"
 	"time"
 )
 
-type Block struct {
+// BlockHeader holds everything that identifies a block. The block hash is the
+// hash of the header alone; the transactions are committed to through
+// MerkleRoot, so chain linkage can be checked without the bodies.
+type BlockHeader struct {
 	Height            int64
-	Transactions      []*Transaction
-	MerkleRoot        []byte
 	PreviousBlockHash []byte
-	CurrentBlockHash  []byte
-	Timestamp         int64
+	// MerkleRoot is the root of the block's transaction hashes, built as
+	// TxRootType says.
+	MerkleRoot []byte
+	TxRootType TxRootType
+	// StateRoot is the root of the balances after the block is applied.
+	StateRoot []byte
+	Timestamp int64
+	// Proposer is the ID of the node that created the block.
+	Proposer string
+	// ChainID is the chain the block belongs to; it is set by the genesis
+	// block and every block after it must carry the same one.
+	ChainID string
+	// ConfigHash is set only on the genesis block and commits to the chain
+	// parameters and validators, so they are part of the genesis hash.
+	ConfigHash []byte
+}
+
+type Block struct {
+	BlockHeader
+	Transactions     []*Transaction
+	CurrentBlockHash []byte
 }
 
+// NewBlock creates a block without a state root or proposer. Blocks proposed
+// to the network are built with NewBlockWithState.
 func NewBlock(transactions []*Transaction, previousBlockHash []byte, height int) *Block {
-	var txHashes [][]byte
-	for _, tx := range transactions {
-		txHashes = append(txHashes, tx.Hash())
-	}
-	_, mptRoot := mpt.BuildMPTFromTxHashes(txHashes)
+	return NewBlockWithState(transactions, previousBlockHash, height, nil, "")
+}
+
+// NewBlockWithState creates a block whose header commits to stateRoot, the
+// balances after its transactions are applied.
+func NewBlockWithState(transactions []*Transaction, previousBlockHash []byte, height int, stateRoot []byte, proposer string) *Block {
+	return NewChainBlock("", TxRootMPT, transactions, previousBlockHash, height, stateRoot, proposer)
+}
 
+// NewChainBlock is NewBlockWithState for a block of the chain chainID, whose
+// transaction root is built as rootType says.
+func NewChainBlock(chainID string, rootType TxRootType, transactions []*Transaction, previousBlockHash []byte, height int, stateRoot []byte, proposer string) *Block {
 	block := &Block{
-		Height:            int64(height),
-		Transactions:      transactions,
-		MerkleRoot:        mptRoot,
-		PreviousBlockHash: previousBlockHash,
-		Timestamp:         time.Now().Unix(),
+		BlockHeader: BlockHeader{
+			Height:            int64(height),
+			PreviousBlockHash: previousBlockHash,
+			MerkleRoot:        TxRoot(rootType, transactions),
+			TxRootType:        rootType,
+			StateRoot:         stateRoot,
+			Timestamp:         time.Now().Unix(),
+			Proposer:          proposer,
+			ChainID:           chainID,
+		},
+		Transactions: transactions,
 	}
 
 	block.CurrentBlockHash = block.Hash()
 	return block
 }
 
+// Hash is the sha256 of the encoding of the header.
+func (h *BlockHeader) Hash() []byte {
+	return hashProto(&nodepb.BlockHeader{
+		Height:            h.Height,
+		MerkleRoot:        h.MerkleRoot,
+		TxRootType:        int32(h.TxRootType),
+		PreviousBlockHash: h.PreviousBlockHash,
+		Timestamp:         h.Timestamp,
+		StateRoot:         h.StateRoot,
+		Proposer:          h.Proposer,
+		ChainId:           h.ChainID,
+		ConfigHash:        h.ConfigHash,
+	})
+}
+
+// Hash returns the hash of the block header.
 func (b *Block) Hash() []byte {
-	copyBlock := *b
-	copyBlock.CurrentBlockHash = nil // Tránh tự tham chiếu khi băm
-	data, _ := json.Marshal(copyBlock)
-	hash := sha256.Sum256(data)
-	return hash[:]
+	return b.BlockHeader.Hash()
 }
 
 func ValidateBlock(block *Block, prevBlock *Block) bool {
@@ -54,27 +96,21 @@ func ValidateBlock(block *Block, prevBlock *Block) bool {
 		return false
 	}
 
-	// 2. Validate each transaction's signature
+	// 2. Validate each transaction's signature and sender
 	for _, tx := range block.Transactions {
-		pubKey, err := cryptohelper.BytesToPublicKey(tx.PublicKey)
-		if err != nil {
-			fmt.Println("❌ Invalid public key in tx")
-			return false
-		}
-		if !VerifyTransaction(tx, pubKey) {
-			fmt.Println("❌ Invalid signature in tx")
+		if err := AuthenticateBlockTransaction(tx); err != nil {
+			fmt.Println("❌ Invalid tx:", err)
 			return false
 		}
 	}
 
-	// 3. Rebuild MPT and check root
-	var txHashes [][]byte
-	for _, tx := range block.Transactions {
-		txHashes = append(txHashes, tx.Hash())
+	// 3. Rebuild the transaction root and check it
+	if !block.TxRootType.Valid() {
+		fmt.Println("❌ Unknown tx root type")
+		return false
 	}
-	_, computedRoot := mpt.BuildMPTFromTxHashes(txHashes)
-	if !bytes.Equal(block.MerkleRoot, computedRoot) {
-		fmt.Println("❌ Invalid MPT Merkle root")
+	if !bytes.Equal(block.MerkleRoot, TxRoot(block.TxRootType, block.Transactions)) {
+		fmt.Println("❌ Invalid Merkle root")
 		return false
 	}
 
@@ -84,47 +120,78 @@ func ValidateBlock(block *Block, prevBlock *Block) bool {
 func ProtoToBlock(pb *nodepb.Block) *Block {
 	var txs []*Transaction
 	for _, ptx := range pb.Transactions {
-		tx := &Transaction{
-			Sender:    ptx.Sender,
-			Receiver:  ptx.Receiver,
-			Amount:    ptx.Amount,
-			Timestamp: ptx.Timestamp,
-			Signature: ptx.Signature,
-			PublicKey: ptx.PublicKey,
-		}
-		txs = append(txs, tx)
+		txs = append(txs, ProtoToTransaction(ptx))
 	}
 
 	return &Block{
-		Height:            pb.Height,
-		Transactions:      txs,
-		MerkleRoot:        pb.MerkleRoot,
-		PreviousBlockHash: pb.PreviousBlockHash,
-		CurrentBlockHash:  pb.CurrentBlockHash,
-		Timestamp:         pb.Timestamp,
+		BlockHeader: BlockHeader{
+			Height:            pb.Height,
+			PreviousBlockHash: pb.PreviousBlockHash,
+			MerkleRoot:        pb.MerkleRoot,
+			TxRootType:        TxRootType(pb.TxRootType),
+			StateRoot:         pb.StateRoot,
+			Timestamp:         pb.Timestamp,
+			Proposer:          pb.Proposer,
+			ChainID:           pb.ChainId,
+			ConfigHash:        pb.ConfigHash,
+		},
+		Transactions:     txs,
+		CurrentBlockHash: pb.CurrentBlockHash,
 	}
 }
 
 func BlockToProto(b *Block) *nodepb.Block {
 	var ptxs []*nodepb.Transaction
 	for _, tx := range b.Transactions {
-		ptx := &nodepb.Transaction{
-			Sender:    tx.Sender,
-			Receiver:  tx.Receiver,
-			Amount:    tx.Amount,
-			Timestamp: tx.Timestamp,
-			Signature: tx.Signature,
-			PublicKey: tx.PublicKey,
-		}
-		ptxs = append(ptxs, ptx)
+		ptxs = append(ptxs, TransactionToProto(tx))
 	}
 
 	return &nodepb.Block{
 		Height:            b.Height,
 		Transactions:      ptxs,
 		MerkleRoot:        b.MerkleRoot,
+		TxRootType:        int32(b.TxRootType),
+		PreviousBlockHash: b.PreviousBlockHash,
+		CurrentBlockHash:  b.CurrentBlockHash,
+		Timestamp:         b.Timestamp,
+		StateRoot:         b.StateRoot,
+		Proposer:          b.Proposer,
+		ChainId:           b.ChainID,
+		ConfigHash:        b.ConfigHash,
+	}
+}
+
+// BlockToHeaderProto builds the header-only view of a block.
+func BlockToHeaderProto(b *Block) *nodepb.BlockHeader {
+	return &nodepb.BlockHeader{
+		Height:            b.Height,
+		MerkleRoot:        b.MerkleRoot,
+		TxRootType:        int32(b.TxRootType),
 		PreviousBlockHash: b.PreviousBlockHash,
 		CurrentBlockHash:  b.CurrentBlockHash,
 		Timestamp:         b.Timestamp,
+		TxCount:           int32(len(b.Transactions)),
+		StateRoot:         b.StateRoot,
+		Proposer:          b.Proposer,
+		ChainId:           b.ChainID,
+		ConfigHash:        b.ConfigHash,
+	}
+}
+
+// ProtoToHeader converts a header back into a Block without transactions.
+func ProtoToHeader(pb *nodepb.BlockHeader) *Block {
+	return &Block{
+		BlockHeader: BlockHeader{
+			Height:            pb.Height,
+			PreviousBlockHash: pb.PreviousBlockHash,
+			MerkleRoot:        pb.MerkleRoot,
+			TxRootType:        TxRootType(pb.TxRootType),
+			StateRoot:         pb.StateRoot,
+			Timestamp:         pb.Timestamp,
+			Proposer:          pb.Proposer,
+			ChainID:           pb.ChainId,
+			ConfigHash:        pb.ConfigHash,
+		},
+		CurrentBlockHash: pb.CurrentBlockHash,
 	}
 }
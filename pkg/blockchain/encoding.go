@@ -0,0 +1,87 @@
+package blockchain
+
+import (
+	"blockchain-go/proto/nodepb"
+	"crypto/sha256"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Blocks and transactions are encoded as deterministic protobuf, the same
+// messages used on the wire. Deterministic only means that one binary always
+// writes the same bytes for the same message: protobuf has no canonical
+// encoding, and another implementation or another version of
+// google.golang.org/protobuf may write the same fields differently. Every
+// node recomputes block and transaction hashes from the decoded fields, so
+// all nodes must produce the same bytes. The protobuf module is therefore
+// pinned in go.mod, and cmd/test/encoding_pin checks that fixed blocks and
+// transactions still encode to the recorded bytes; run it before changing
+// that version.
+var deterministic = proto.MarshalOptions{Deterministic: true}
+
+// Encode returns the binary encoding of the block.
+func (b *Block) Encode() ([]byte, error) {
+	return deterministic.Marshal(BlockToProto(b))
+}
+
+// DecodeBlock parses a block produced by Block.Encode.
+func DecodeBlock(data []byte) (*Block, error) {
+	var pb nodepb.Block
+	if err := proto.Unmarshal(data, &pb); err != nil {
+		return nil, fmt.Errorf("failed to decode block: %w", err)
+	}
+	return ProtoToBlock(&pb), nil
+}
+
+// EncodeBody returns the encoding of the block's transactions,
+// which is what is stored apart from the header.
+func (b *Block) EncodeBody() ([]byte, error) {
+	body := &nodepb.BlockBody{}
+	for _, tx := range b.Transactions {
+		body.Transactions = append(body.Transactions, TransactionToProto(tx))
+	}
+	return deterministic.Marshal(body)
+}
+
+// DecodeBody parses a body produced by Block.EncodeBody. Fields other than
+// the transactions are ignored, so a full encoded Block is accepted too.
+func DecodeBody(data []byte) ([]*Transaction, error) {
+	var body nodepb.BlockBody
+	if err := (proto.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, &body); err != nil {
+		return nil, fmt.Errorf("failed to decode block body: %w", err)
+	}
+	var txs []*Transaction
+	for _, ptx := range body.Transactions {
+		txs = append(txs, ProtoToTransaction(ptx))
+	}
+	return txs, nil
+}
+
+// Encode returns the binary encoding of the transaction.
+func (tx *Transaction) Encode() ([]byte, error) {
+	return deterministic.Marshal(TransactionToProto(tx))
+}
+
+// DecodeTransaction parses a transaction produced by Transaction.Encode.
+func DecodeTransaction(data []byte) (*Transaction, error) {
+	var pb nodepb.Transaction
+	if err := proto.Unmarshal(data, &pb); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction: %w", err)
+	}
+	return ProtoToTransaction(&pb), nil
+}
+
+// hashProto hashes the deterministic encoding of m. Marshalling fails, and
+// hashProto panics, only when a string field (chain ID, proposer) is not
+// valid UTF-8. Those come from the genesis, whose Validate checks them, from
+// NODE_ID, checked by cmd/node, or from decoded protobuf, which rejects
+// invalid UTF-8.
+func hashProto(m proto.Message) []byte {
+	data, err := deterministic.Marshal(m)
+	if err != nil {
+		panic(fmt.Sprintf("encoding failed: %v", err))
+	}
+	hash := sha256.Sum256(data)
+	return hash[:]
+}
@@ -1,17 +1,26 @@
 package blockchain
 
 import (
+	"blockchain-go/pkg/cryptohelper"
 	"blockchain-go/pkg/mpt"
 
-	"crypto/ecdsa"
-	"crypto/sha256"
+	"blockchain-go/proto/nodepb"
+	"bytes"
 	"encoding/binary"
-	"encoding/json"
 	"errors"
-	"math/big"
+	"fmt"
 	"time"
 )
 
+var (
+	// ErrInvalidSignature is returned for a transaction whose signature does
+	// not match its public key.
+	ErrInvalidSignature = errors.New("invalid transaction signature")
+	// ErrSenderMismatch is returned for a transaction whose sender is not the
+	// address of the key that signed it.
+	ErrSenderMismatch = errors.New("sender is not the address of the signing key")
+)
+
 type Transaction struct {
 	Sender    []byte
 	Receiver  []byte
@@ -19,6 +28,11 @@ type Transaction struct {
 	Timestamp int64
 	Signature []byte
 	PublicKey []byte
+	// ChainID is the chain the transaction is signed for (see pkg/genesis).
+	ChainID string
+	// SignatureScheme is the scheme of Signature and PublicKey; it is signed
+	// with the transaction.
+	SignatureScheme cryptohelper.SchemeID
 }
 
 func NewTransaction(sender, receiver []byte, amount float64) *Transaction {
@@ -30,20 +44,87 @@ func NewTransaction(sender, receiver []byte, amount float64) *Transaction {
 	}
 }
 
+// Hash is the sha256 of the encoding of the transaction without its
+// signature and public key; it is what the sender signs. The chain ID is
+// included, so a signature is only valid on one network.
 func (tx *Transaction) Hash() []byte {
 	txCopy := *tx
 	txCopy.Signature = nil
 	txCopy.PublicKey = nil
-	data, _ := json.Marshal(txCopy)
-	hash := sha256.Sum256(data)
-	return hash[:]
+	return hashProto(TransactionToProto(&txCopy))
+}
+
+func TransactionToProto(tx *Transaction) *nodepb.Transaction {
+	return &nodepb.Transaction{
+		Sender:          tx.Sender,
+		Receiver:        tx.Receiver,
+		Amount:          tx.Amount,
+		Timestamp:       tx.Timestamp,
+		Signature:       tx.Signature,
+		PublicKey:       tx.PublicKey,
+		ChainId:         tx.ChainID,
+		SignatureScheme: int32(tx.SignatureScheme),
+	}
 }
 
-func VerifyTransaction(tx *Transaction, pubKey *ecdsa.PublicKey) bool {
-	hash := tx.Hash()
-	r := new(big.Int).SetBytes(tx.Signature[:len(tx.Signature)/2])
-	s := new(big.Int).SetBytes(tx.Signature[len(tx.Signature)/2:])
-	return ecdsa.Verify(pubKey, hash, r, s)
+func ProtoToTransaction(ptx *nodepb.Transaction) *Transaction {
+	return &Transaction{
+		Sender:          ptx.Sender,
+		Receiver:        ptx.Receiver,
+		Amount:          ptx.Amount,
+		Timestamp:       ptx.Timestamp,
+		Signature:       ptx.Signature,
+		PublicKey:       ptx.PublicKey,
+		ChainID:         ptx.ChainId,
+		SignatureScheme: cryptohelper.SchemeID(ptx.SignatureScheme),
+	}
+}
+
+// VerifyTransaction reports whether tx.Signature is a signature of the
+// transaction by tx.PublicKey under tx.SignatureScheme.
+func VerifyTransaction(tx *Transaction) bool {
+	scheme, err := cryptohelper.LookupScheme(tx.SignatureScheme)
+	if err != nil {
+		return false
+	}
+	return scheme.Verify(tx.PublicKey, tx.Hash(), tx.Signature)
+}
+
+// AuthenticateTransaction checks that tx is signed by the key in
+// tx.PublicKey and that this key owns tx.Sender. A valid signature alone is
+// not enough: anyone can sign with their own key and name another sender.
+func AuthenticateTransaction(tx *Transaction) error {
+	return authenticate(tx, false)
+}
+
+// AuthenticateBlockTransaction is AuthenticateTransaction for a transaction
+// carried in a block. It also accepts the P-256 signatures written before
+// they were padded and low-S, so blocks committed before then still
+// validate. New transactions only enter a block through
+// AuthenticateTransaction, so honest leaders never propose such a signature.
+func AuthenticateBlockTransaction(tx *Transaction) error {
+	return authenticate(tx, true)
+}
+
+func authenticate(tx *Transaction, legacy bool) error {
+	scheme, err := cryptohelper.LookupScheme(tx.SignatureScheme)
+	if err != nil {
+		return err
+	}
+	address, err := scheme.Address(tx.PublicKey)
+	if err != nil {
+		return fmt.Errorf("invalid public key: %w", err)
+	}
+	if !bytes.Equal(tx.Sender, address) {
+		return fmt.Errorf("%w: sender %x, key address %x", ErrSenderMismatch, tx.Sender, address)
+	}
+	if VerifyTransaction(tx) {
+		return nil
+	}
+	if legacy && tx.SignatureScheme == cryptohelper.P256 && cryptohelper.VerifyLegacyP256(tx.PublicKey, tx.Hash(), tx.Signature) {
+		return nil
+	}
+	return ErrInvalidSignature
 }
 
 // Chuyển tiền và cập nhật MPT trạng thái
@@ -0,0 +1,123 @@
+package blockchain
+
+import (
+	"blockchain-go/pkg/merkle"
+	"blockchain-go/pkg/mpt"
+	"fmt"
+)
+
+// TxRootType is how a block's transaction hashes are committed to by its
+// MerkleRoot. It is part of the header, so a block says how to check it.
+type TxRootType int32
+
+const (
+	// TxRootMPT puts the transaction hashes in a Merkle-Patricia Trie, each
+	// hash under itself. It is the zero value, so blocks made before the
+	// type was recorded keep their hashes.
+	TxRootMPT TxRootType = 0
+	// TxRootBinary is an ordered binary Merkle tree (RFC 6962) of the
+	// transaction hashes: smaller proofs that also give the position of
+	// the transaction in the block.
+	TxRootBinary TxRootType = 1
+)
+
+func (t TxRootType) String() string {
+	switch t {
+	case TxRootMPT:
+		return "mpt"
+	case TxRootBinary:
+		return "binary"
+	}
+	return fmt.Sprintf("TxRootType(%d)", int32(t))
+}
+
+// ParseTxRootType parses the name of a root type; an empty name is TxRootMPT.
+func ParseTxRootType(name string) (TxRootType, error) {
+	switch name {
+	case "", "mpt":
+		return TxRootMPT, nil
+	case "binary":
+		return TxRootBinary, nil
+	}
+	return 0, fmt.Errorf("unknown tx root type %q (want mpt or binary)", name)
+}
+
+// Valid reports whether t is a known root type.
+func (t TxRootType) Valid() bool {
+	return t == TxRootMPT || t == TxRootBinary
+}
+
+func txHashes(transactions []*Transaction) [][]byte {
+	var hashes [][]byte
+	for _, tx := range transactions {
+		hashes = append(hashes, tx.Hash())
+	}
+	return hashes
+}
+
+// TxRoot returns the Merkle root of the transaction hashes.
+func TxRoot(rootType TxRootType, transactions []*Transaction) []byte {
+	return ComputeMerkleRoot(rootType, txHashes(transactions))
+}
+
+// ComputeMerkleRoot returns the root of a list of transaction hashes. An
+// unknown type has no root.
+func ComputeMerkleRoot(rootType TxRootType, hashes [][]byte) []byte {
+	switch rootType {
+	case TxRootMPT:
+		_, root := mpt.BuildMPTFromTxHashes(hashes)
+		return root
+	case TxRootBinary:
+		return merkle.Root(hashes)
+	}
+	return nil
+}
+
+// ProveTransaction returns the encoded proof that the transaction at index
+// is committed to by the Merkle root of transactions: the trie nodes on its
+// path for TxRootMPT, its audit path for TxRootBinary.
+func ProveTransaction(rootType TxRootType, transactions []*Transaction, index int) ([]byte, error) {
+	if index < 0 || index >= len(transactions) {
+		return nil, fmt.Errorf("transaction index %d out of range (%d transactions)", index, len(transactions))
+	}
+	hashes := txHashes(transactions)
+	switch rootType {
+	case TxRootMPT:
+		trie, _ := mpt.BuildMPTFromTxHashes(hashes)
+		proof, err := trie.GenerateProof(hashes[index])
+		if err != nil {
+			return nil, err
+		}
+		return proof.Encode(), nil
+	case TxRootBinary:
+		proof, err := merkle.Prove(hashes, index)
+		if err != nil {
+			return nil, err
+		}
+		return proof.Encode(), nil
+	}
+	return nil, fmt.Errorf("unknown tx root type %d", int32(rootType))
+}
+
+// VerifyTransactionProof checks that proof, as made by ProveTransaction,
+// shows the transaction with hash txHash under merkleRoot. A binary proof
+// also shows that it is the transaction at index of the block, provided
+// txCount is the block's real number of transactions; an MPT proof does not
+// depend on the position.
+func VerifyTransactionProof(rootType TxRootType, merkleRoot, txHash []byte, index, txCount int, proof []byte) bool {
+	switch rootType {
+	case TxRootMPT:
+		p, err := mpt.DecodeProof(proof)
+		if err != nil {
+			return false
+		}
+		return mpt.VerifyProof(merkleRoot, txHash, txHash, p)
+	case TxRootBinary:
+		p, err := merkle.DecodeProof(proof)
+		if err != nil {
+			return false
+		}
+		return merkle.Verify(merkleRoot, txHash, index, txCount, p)
+	}
+	return false
+}
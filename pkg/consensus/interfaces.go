@@ -9,4 +9,8 @@ type Networker interface {
 	BroadcastProposedBlock(block *blockchain.Block)
 	BroadcastCommittedBlock(block *blockchain.Block)
 	SendVoteToLeader(vote *nodepb.Vote) error
+	// FetchBlocks returns up to maxBlocks committed blocks starting at
+	// fromHeight from any reachable peer. An empty result means no peer is
+	// ahead of fromHeight.
+	FetchBlocks(fromHeight int64, maxBlocks int64) ([]*blockchain.Block, error)
 }
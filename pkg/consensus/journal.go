@@ -0,0 +1,243 @@
+package consensus
+
+import (
+	"blockchain-go/pkg/blockchain"
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// JournalKind is the type of a consensus journal record.
+type JournalKind byte
+
+const (
+	// JournalVote records the block this node voted for at a height.
+	JournalVote JournalKind = 1
+	// JournalProposal records the block the leader proposed at a height.
+	JournalProposal JournalKind = 2
+	// JournalCommit records a block the network agreed on, before it is
+	// saved and applied.
+	JournalCommit JournalKind = 3
+)
+
+// JournalRecord is one entry of the journal. Vote records carry only the
+// height and hash; the others carry the whole block.
+type JournalRecord struct {
+	Kind   JournalKind
+	Height int64
+	Hash   []byte
+	Block  *blockchain.Block
+}
+
+var journalCRC = crc32.MakeTable(crc32.Castagnoli)
+
+// Journal is an append-only, fsynced log of the consensus decisions a node
+// has made for heights it has not committed yet. Each record is framed as a
+// big-endian uint32 length, the payload and its CRC-32C:
+//
+//	payload = kind byte | height int64 | hash (vote) or encoded block
+type Journal struct {
+	mu      sync.Mutex
+	path    string
+	file    *os.File
+	records []JournalRecord
+}
+
+// OpenJournal opens or creates the journal at path and loads its records. A
+// torn record at the end, left by a crash in the middle of an append, is
+// dropped.
+func OpenJournal(path string) (*Journal, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open consensus journal: %w", err)
+	}
+	j := &Journal{path: path, file: file}
+
+	valid, err := j.load()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info, err := file.Stat(); err == nil && info.Size() > valid {
+		log.Printf("⚠️ Consensus journal: dropping %d bytes of a torn record", info.Size()-valid)
+		if err := file.Truncate(valid); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	if _, err := file.Seek(0, io.SeekEnd); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return j, nil
+}
+
+// load reads every complete record and returns the offset after the last.
+func (j *Journal) load() (int64, error) {
+	r := bufio.NewReader(j.file)
+	var offset int64
+	for {
+		var frame [4]byte
+		if _, err := io.ReadFull(r, frame[:]); err != nil {
+			return offset, nil
+		}
+		size := binary.BigEndian.Uint32(frame[:])
+		data := make([]byte, int(size)+4)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return offset, nil
+		}
+		payload := data[:size]
+		if crc32.Checksum(payload, journalCRC) != binary.BigEndian.Uint32(data[size:]) {
+			return offset, nil
+		}
+		rec, err := decodeJournalRecord(payload)
+		if err != nil {
+			return 0, err
+		}
+		j.records = append(j.records, rec)
+		offset += int64(len(frame) + len(data))
+	}
+}
+
+// Records returns the records in the order they were appended.
+func (j *Journal) Records() []JournalRecord {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]JournalRecord(nil), j.records...)
+}
+
+// Append writes rec and syncs it to disk before returning.
+func (j *Journal) Append(rec JournalRecord) error {
+	data, err := encodeJournalRecord(rec)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, err := j.file.Write(data); err != nil {
+		return fmt.Errorf("failed to write consensus journal: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync consensus journal: %w", err)
+	}
+	j.records = append(j.records, rec)
+	return nil
+}
+
+// Prune drops the records at or below height, which the committed chain
+// has made obsolete.
+func (j *Journal) Prune(height int64) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var keep []JournalRecord
+	for _, rec := range j.records {
+		if rec.Height > height {
+			keep = append(keep, rec)
+		}
+	}
+	if len(keep) == len(j.records) {
+		return nil
+	}
+	if len(keep) == 0 {
+		if err := j.file.Truncate(0); err != nil {
+			return err
+		}
+		if _, err := j.file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		j.records = nil
+		return j.file.Sync()
+	}
+
+	// Rewrite the live records to a new file and swap it in, so a crash
+	// leaves either the old or the new journal.
+	tmpPath := j.path + ".tmp"
+	tmp, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	for _, rec := range keep {
+		data, err := encodeJournalRecord(rec)
+		if err == nil {
+			_, err = tmp.Write(data)
+		}
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := os.Rename(tmpPath, j.path); err != nil {
+		tmp.Close()
+		return err
+	}
+	if dir, err := os.Open(filepath.Dir(j.path)); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+	j.file.Close()
+	j.file = tmp
+	j.records = keep
+	return nil
+}
+
+// Close closes the journal file.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
+
+func encodeJournalRecord(rec JournalRecord) ([]byte, error) {
+	payload := make([]byte, 9)
+	payload[0] = byte(rec.Kind)
+	binary.BigEndian.PutUint64(payload[1:], uint64(rec.Height))
+	switch rec.Kind {
+	case JournalVote:
+		payload = append(payload, rec.Hash...)
+	case JournalProposal, JournalCommit:
+		data, err := rec.Block.Encode()
+		if err != nil {
+			return nil, err
+		}
+		payload = append(payload, data...)
+	default:
+		return nil, fmt.Errorf("unknown journal record kind %d", rec.Kind)
+	}
+
+	frame := make([]byte, 4, 4+len(payload)+4)
+	binary.BigEndian.PutUint32(frame, uint32(len(payload)))
+	frame = append(frame, payload...)
+	return binary.BigEndian.AppendUint32(frame, crc32.Checksum(payload, journalCRC)), nil
+}
+
+func decodeJournalRecord(payload []byte) (JournalRecord, error) {
+	if len(payload) < 9 {
+		return JournalRecord{}, errors.New("corrupted consensus journal record")
+	}
+	rec := JournalRecord{Kind: JournalKind(payload[0]), Height: int64(binary.BigEndian.Uint64(payload[1:9]))}
+	switch rec.Kind {
+	case JournalVote:
+		rec.Hash = append([]byte(nil), payload[9:]...)
+	case JournalProposal, JournalCommit:
+		block, err := blockchain.DecodeBlock(payload[9:])
+		if err != nil {
+			return JournalRecord{}, fmt.Errorf("corrupted consensus journal record: %w", err)
+		}
+		rec.Block = block
+		rec.Hash = block.CurrentBlockHash
+	default:
+		return JournalRecord{}, fmt.Errorf("unknown consensus journal record kind %d", rec.Kind)
+	}
+	return rec, nil
+}
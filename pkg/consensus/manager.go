@@ -6,6 +6,8 @@ import (
 	"blockchain-go/pkg/storage"
 	"blockchain-go/pkg/validation"
 	"blockchain-go/proto/nodepb"
+	"bytes"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
@@ -25,8 +27,49 @@ type Manager struct {
 	networker      Networker
 	voteMutex      sync.Mutex
 	netWorker      Networker
+
+	// chainMutex serialises commits and reads of LatestBlock/State made
+	// while validating, since blocks can now arrive from RPCs and from the
+	// background catch-up at the same time.
+	chainMutex sync.Mutex
+	// futureBlocks holds committed blocks that arrived ahead of the local
+	// tip, keyed by height, until the gap before them is filled.
+	futureBlocks map[int64]*blockchain.Block
+	catchUpMutex sync.Mutex
+	catchingUp   bool
+
+	// OnCommit, when set, is called after a block and its state changes are
+	// persisted, while the chain is still locked.
+	OnCommit func(block *blockchain.Block)
+	// IsValidator, when set, tells whether a node may vote; votes of other
+	// nodes are dropped.
+	IsValidator func(nodeID string) bool
+
+	// journal, when set, records votes, proposals and commit decisions
+	// before they are sent, so a restarted node keeps its word.
+	journal *Journal
+	// votedAt is the block hash this node voted for at each height above
+	// the tip; it never votes for another block at that height.
+	votedAt map[int64][]byte
+	// proposal is the leader's block that is still waiting for votes.
+	proposal *blockchain.Block
+	// voters records who voted for each block, so a repeated vote counts
+	// once.
+	voters map[string]map[string]bool
 }
 
+// ErrProposalPending is returned by CreateAndProposeBlock while the previous
+// proposal at the same height has not been committed.
+var ErrProposalPending = errors.New("previous proposal is still waiting for votes")
+
+const (
+	// maxFutureBlocks bounds the out-of-order buffer; blocks beyond it are
+	// dropped and fetched again by the catch-up.
+	maxFutureBlocks = 512
+	// catchUpBatch is the number of blocks requested per catch-up round.
+	catchUpBatch = 128
+)
+
 func NewManager(nodeID string, totalNodes int, db *storage.DB, s *state.State, latestBlock *blockchain.Block, networker Networker) *Manager {
 	return &Manager{
 		NodeID:         nodeID,
@@ -38,19 +81,114 @@ func NewManager(nodeID string, totalNodes int, db *storage.DB, s *state.State, l
 		PendingBlocks:  make(map[string]*blockchain.Block),
 		VoteCount:      make(map[string]int),
 		BlockCommitted: make(map[string]bool),
+		futureBlocks:   make(map[int64]*blockchain.Block),
+		votedAt:        make(map[int64][]byte),
+		voters:         make(map[string]map[string]bool),
 	}
 }
 
+// RecoverFromJournal replays the decisions recorded in j and keeps j for
+// the ones to come: committed blocks that were never applied are committed,
+// and earlier votes and the pending proposal are restored so the node does
+// not vote or propose twice at the same height.
+func (m *Manager) RecoverFromJournal(j *Journal) error {
+	m.chainMutex.Lock()
+	defer m.chainMutex.Unlock()
+	m.journal = j
+
+	tip := int64(-1)
+	if m.LatestBlock != nil {
+		tip = m.LatestBlock.Height
+	}
+	for _, rec := range j.Records() {
+		if rec.Height <= tip {
+			continue
+		}
+		switch rec.Kind {
+		case JournalVote:
+			m.votedAt[rec.Height] = rec.Hash
+			log.Printf("📒 Journal: already voted for block %x at height %d", rec.Hash, rec.Height)
+		case JournalProposal:
+			m.restoreProposal(rec.Block)
+			log.Printf("📒 Journal: proposal %x at height %d is still waiting for votes", rec.Hash, rec.Height)
+		case JournalCommit:
+			if rec.Height != tip+1 {
+				continue
+			}
+			log.Printf("📒 Journal: committing block %d that was agreed on before the restart", rec.Height)
+			if err := m.commitBlockLocked(rec.Block); err != nil {
+				return fmt.Errorf("can not replay the commit of block %d: %w", rec.Height, err)
+			}
+			tip = rec.Height
+		}
+	}
+	return j.Prune(tip)
+}
+
+func (m *Manager) restoreProposal(block *blockchain.Block) {
+	blockHashKey := string(block.CurrentBlockHash)
+	m.voteMutex.Lock()
+	defer m.voteMutex.Unlock()
+	m.proposal = block
+	m.PendingBlocks[blockHashKey] = block
+	m.voters[blockHashKey] = map[string]bool{m.NodeID: true} // Leader tự động vote cho chính mình
+	m.VoteCount[blockHashKey] = 1
+}
+
+// ResumeProposal broadcasts the leader's pending proposal again, for example
+// after a restart, so followers that missed it can vote.
+func (m *Manager) ResumeProposal() {
+	m.voteMutex.Lock()
+	block := m.proposal
+	m.voteMutex.Unlock()
+	if block != nil {
+		log.Printf("📦 Leader: proposing block %d again", block.Height)
+		m.networker.BroadcastProposedBlock(block)
+	}
+}
+
+func (m *Manager) appendJournal(rec JournalRecord) error {
+	if m.journal == nil {
+		return nil
+	}
+	return m.journal.Append(rec)
+}
+
 func (m *Manager) HandleProposedBlock(block *blockchain.Block) error {
 	log.Printf("📦 Validating proposed block at height %d", block.Height)
 
-	if err := validation.ValidateBlock(block, m.State, m.LatestBlock); err != nil {
+	m.chainMutex.Lock()
+	if gap := m.heightGap(block); gap > 0 {
+		latest := m.LatestBlock.Height
+		m.chainMutex.Unlock()
+		m.requestCatchUp()
+		return fmt.Errorf("node is behind (local height %d, proposal height %d), catching up", latest, block.Height)
+	}
+	err := validation.ValidateBlock(block, m.State, m.LatestBlock)
+	m.chainMutex.Unlock()
+	if err != nil {
 		return fmt.Errorf("validate block fail: %w", err)
 	}
 
 	log.Println("✅ Block pass all validation.")
 	blockHash := string(block.CurrentBlockHash)
+
+	// Một node không bao giờ vote cho hai block khác nhau ở cùng một height.
+	m.voteMutex.Lock()
+	voted, ok := m.votedAt[block.Height]
+	if ok && !bytes.Equal(voted, block.CurrentBlockHash) {
+		m.voteMutex.Unlock()
+		return fmt.Errorf("already voted for block %x at height %d", voted, block.Height)
+	}
+	if !ok {
+		if err := m.appendJournal(JournalRecord{Kind: JournalVote, Height: block.Height, Hash: block.CurrentBlockHash}); err != nil {
+			m.voteMutex.Unlock()
+			return fmt.Errorf("can not record vote: %w", err)
+		}
+		m.votedAt[block.Height] = block.CurrentBlockHash
+	}
 	m.PendingBlocks[blockHash] = block
+	m.voteMutex.Unlock()
 
 	// Gửi phiếu bầu cho leader
 	go func() {
@@ -58,6 +196,7 @@ func (m *Manager) HandleProposedBlock(block *blockchain.Block) error {
 			VoterId:   m.NodeID,
 			BlockHash: block.CurrentBlockHash,
 			Approved:  true,
+			ChainId:   block.ChainID,
 		}
 		if err := m.networker.SendVoteToLeader(vote); err != nil {
 			log.Printf("❌ can not send vote to leader : %v", err)
@@ -74,27 +213,38 @@ func (m *Manager) HandleVote(vote *nodepb.Vote) {
 	if !vote.Approved {
 		return // Bỏ qua các vote không đồng ý
 	}
+	if m.IsValidator != nil && !m.IsValidator(vote.VoterId) {
+		log.Printf("⚠️ Vote from %s dropped: not a genesis validator", vote.VoterId)
+		return
+	}
 
 	blockHashKey := string(vote.BlockHash)
 
 	m.voteMutex.Lock()
-	m.VoteCount[blockHashKey]++
+	// Chỉ đếm vote cho block mà leader đang chờ, nên một vote không thể
+	// đẩy một block lạ lên đủ số phiếu
+	block := m.PendingBlocks[blockHashKey]
+	if block == nil {
+		m.voteMutex.Unlock()
+		log.Printf("⚠️ Vote from %s dropped: no pending block %x", vote.VoterId, vote.BlockHash)
+		return
+	}
+	if m.voters[blockHashKey] == nil {
+		m.voters[blockHashKey] = make(map[string]bool)
+	}
+	m.voters[blockHashKey][vote.VoterId] = true
+	m.VoteCount[blockHashKey] = len(m.voters[blockHashKey])
 	voteCount := m.VoteCount[blockHashKey]
 	m.voteMutex.Unlock()
 
 	needed := m.TotalNodes/2 + 1
 	log.Printf("🗳️  Block %x có %d/%d vote.", vote.BlockHash, voteCount, needed)
 
-	if voteCount >= needed && !m.BlockCommitted[blockHashKey] {
+	if voteCount >= needed && !m.isCommitted(blockHashKey) {
 		log.Printf("🎉 Get enough votes for the block %x. Start commit...", vote.BlockHash)
-		block := m.PendingBlocks[blockHashKey]
-		if block == nil {
-			log.Printf("⚠️ No pending block found %x to commit", vote.BlockHash)
-			return
-		}
 
 		// Leader tự commit trước
-		if err := m.CommitBlock(block); err != nil {
+		if err := m.HandleCommittedBlock(block); err != nil {
 			log.Printf("🔥 Fatal error when Leader commit block: %v", err)
 			return
 		}
@@ -104,34 +254,94 @@ func (m *Manager) HandleVote(vote *nodepb.Vote) {
 	}
 }
 
-func (m *Manager) CreateAndProposeBlock(txs []*blockchain.Transaction) {
+// CreateAndProposeBlock builds the next block from txs and broadcasts it.
+// While an earlier proposal is waiting for votes no other block is proposed
+// at its height: the earlier one is broadcast again and ErrProposalPending
+// is returned, and the caller keeps txs for a later block.
+func (m *Manager) CreateAndProposeBlock(txs []*blockchain.Transaction) error {
 	prevHash := []byte{}
 	height := 0
+	chainID := ""
+	rootType := blockchain.TxRootMPT
+	m.chainMutex.Lock()
 	if m.LatestBlock != nil {
 		prevHash = m.LatestBlock.CurrentBlockHash
 		height = int(m.LatestBlock.Height) + 1
+		// Chain ID và kiểu tx root được giữ nguyên từ genesis
+		chainID = m.LatestBlock.ChainID
+		rootType = m.LatestBlock.TxRootType
+	}
+	m.voteMutex.Lock()
+	pending := m.proposal
+	m.voteMutex.Unlock()
+	if pending != nil && pending.Height == int64(height) {
+		m.chainMutex.Unlock()
+		m.ResumeProposal()
+		return ErrProposalPending
+	}
+	stateRoot, err := m.State.PreviewRoot(txs)
+	m.chainMutex.Unlock()
+	if err != nil {
+		return fmt.Errorf("can not compute state root for block %d: %w", height, err)
 	}
 
-	block := blockchain.NewBlock(txs, prevHash, height)
-	blockHashKey := string(block.CurrentBlockHash)
+	block := blockchain.NewChainBlock(chainID, rootType, txs, prevHash, height, stateRoot, m.NodeID)
 	log.Printf("📦 Leader: creating block at height %d with %d transactions", block.Height, len(txs))
 
-	m.PendingBlocks[blockHashKey] = block
-
-	m.voteMutex.Lock()
-	m.VoteCount[blockHashKey] = 1 // Leader tự động vote cho chính mình
-	m.voteMutex.Unlock()
+	if err := m.appendJournal(JournalRecord{Kind: JournalProposal, Height: block.Height, Block: block}); err != nil {
+		return fmt.Errorf("can not record proposal: %w", err)
+	}
+	m.restoreProposal(block)
 
 	m.networker.BroadcastProposedBlock(block)
+	return nil
 }
 
+// HandleCommittedBlock commits a block the network agreed on, recording the
+// decision in the journal first so it survives a crash before the block is
+// applied.
+func (m *Manager) HandleCommittedBlock(block *blockchain.Block) error {
+	if err := m.appendJournal(JournalRecord{Kind: JournalCommit, Height: block.Height, Block: block}); err != nil {
+		return fmt.Errorf("can not record commit: %w", err)
+	}
+	return m.CommitBlock(block)
+}
+
+// CommitBlock commits a block agreed on by the network. A block ahead of the
+// local tip is buffered and a background catch-up is started to fill the gap;
+// it is committed as soon as its parent is.
 func (m *Manager) CommitBlock(block *blockchain.Block) error {
+	m.chainMutex.Lock()
+	defer m.chainMutex.Unlock()
+
+	if gap := m.heightGap(block); gap > 0 {
+		m.bufferFutureBlock(block)
+		m.requestCatchUp()
+		return nil
+	}
+
+	if err := m.commitBlockLocked(block); err != nil {
+		return err
+	}
+	m.drainFutureBlocks()
+	return nil
+}
+
+func (m *Manager) commitBlockLocked(block *blockchain.Block) error {
 	blockHash := string(block.CurrentBlockHash)
 
 	if m.BlockCommitted[blockHash] {
 		log.Printf("⚠️ Block %d has been committed before", block.Height)
 		return nil
 	}
+	if m.LatestBlock != nil && block.Height <= m.LatestBlock.Height {
+		stored, _, err := m.DB.GetHeaderByHeight(int(block.Height))
+		if err == nil && bytes.Equal(stored.CurrentBlockHash, block.CurrentBlockHash) {
+			log.Printf("⚠️ Block %d has been committed before", block.Height)
+			return nil
+		}
+		return fmt.Errorf("block %d conflicts with the committed chain (local height %d)", block.Height, m.LatestBlock.Height)
+	}
 
 	// Xác thực lại lần cuối trước khi commit
 	if err := validation.ValidateBlock(block, m.State, m.LatestBlock); err != nil {
@@ -143,16 +353,147 @@ func (m *Manager) CommitBlock(block *blockchain.Block) error {
 	}
 
 	// Cập nhật State
-	for _, tx := range block.Transactions {
-		if err := m.State.ApplyTransaction(tx); err != nil {
-			log.Printf("🔥 Fatal error: Cannot apply transaction in committed block: %v", err)
+	// ApplyBlock ghi số dư, height và state trie trong một batch: nếu lỗi thì
+	// state vẫn ở trước block, tip không tiến, và lần commit sau (hoặc lần
+	// khởi động lại) áp dụng lại block này từ đầu
+	if err := m.State.ApplyBlock(block); err != nil {
+		if !errors.Is(err, state.ErrTransactionFailed) {
+			return fmt.Errorf("apply block %d to state: %w", block.Height, err)
 		}
+		log.Printf("⚠️ Skipped failing transactions in committed block %d: %v", block.Height, err)
 	}
 	log.Println("💰 Balance updated.")
 
 	m.LatestBlock = block
 	m.BlockCommitted[blockHash] = true
 	log.Printf("✅ Block %d has been committed successfully", block.Height)
+	m.forgetRound(block.Height)
+
+	if m.OnCommit != nil {
+		m.OnCommit(block)
+	}
 
 	return nil
 }
+
+// forgetRound drops the votes and proposal of heights up to the committed
+// height, which can no longer be voted on.
+func (m *Manager) forgetRound(height int64) {
+	m.voteMutex.Lock()
+	for h := range m.votedAt {
+		if h <= height {
+			delete(m.votedAt, h)
+		}
+	}
+	if m.proposal != nil && m.proposal.Height <= height {
+		m.proposal = nil
+	}
+	for key := range m.voters {
+		if block := m.PendingBlocks[key]; block == nil || block.Height <= height {
+			delete(m.voters, key)
+		}
+	}
+	m.voteMutex.Unlock()
+
+	if m.journal != nil {
+		if err := m.journal.Prune(height); err != nil {
+			log.Printf("⚠️ Could not prune consensus journal: %v", err)
+		}
+	}
+}
+
+// heightGap returns how many blocks are missing between the local tip and
+// block. It must be called with chainMutex held.
+func (m *Manager) heightGap(block *blockchain.Block) int64 {
+	if m.LatestBlock == nil {
+		return 0
+	}
+	return block.Height - m.LatestBlock.Height - 1
+}
+
+func (m *Manager) bufferFutureBlock(block *blockchain.Block) {
+	if _, ok := m.futureBlocks[block.Height]; !ok && len(m.futureBlocks) >= maxFutureBlocks {
+		log.Printf("⚠️ Out-of-order buffer full, dropping block %d", block.Height)
+		return
+	}
+	m.futureBlocks[block.Height] = block
+	log.Printf("⏸️ Block %d is ahead of local height %d, buffered until the gap is filled", block.Height, m.LatestBlock.Height)
+}
+
+// drainFutureBlocks commits buffered blocks that now extend the tip and drops
+// the ones that fell behind it. It must be called with chainMutex held.
+func (m *Manager) drainFutureBlocks() {
+	for height := range m.futureBlocks {
+		if height <= m.LatestBlock.Height {
+			delete(m.futureBlocks, height)
+		}
+	}
+	for {
+		block, ok := m.futureBlocks[m.LatestBlock.Height+1]
+		if !ok {
+			return
+		}
+		delete(m.futureBlocks, block.Height)
+		if err := m.commitBlockLocked(block); err != nil {
+			log.Printf("❌ Buffered block %d rejected: %v", block.Height, err)
+			return
+		}
+	}
+}
+
+func (m *Manager) isCommitted(blockHash string) bool {
+	m.chainMutex.Lock()
+	defer m.chainMutex.Unlock()
+	return m.BlockCommitted[blockHash]
+}
+
+// requestCatchUp starts a background sync from the peers unless one is
+// already running.
+func (m *Manager) requestCatchUp() {
+	if m.networker == nil {
+		return
+	}
+	m.catchUpMutex.Lock()
+	if m.catchingUp {
+		m.catchUpMutex.Unlock()
+		return
+	}
+	m.catchingUp = true
+	m.catchUpMutex.Unlock()
+
+	go m.catchUp()
+}
+
+func (m *Manager) catchUp() {
+	defer func() {
+		m.catchUpMutex.Lock()
+		m.catchingUp = false
+		m.catchUpMutex.Unlock()
+	}()
+
+	for {
+		m.chainMutex.Lock()
+		from := int64(0)
+		if m.LatestBlock != nil {
+			from = m.LatestBlock.Height + 1
+		}
+		m.chainMutex.Unlock()
+
+		log.Printf("🔄 Catching up from height %d...", from)
+		blocks, err := m.networker.FetchBlocks(from, catchUpBatch)
+		if err != nil {
+			log.Printf("❌ Catch-up failed at height %d: %v", from, err)
+			return
+		}
+		if len(blocks) == 0 {
+			log.Printf("✅ Catch-up done at height %d", from-1)
+			return
+		}
+		for _, block := range blocks {
+			if err := m.CommitBlock(block); err != nil {
+				log.Printf("❌ Catch-up could not commit block %d: %v", block.Height, err)
+				return
+			}
+		}
+	}
+}
@@ -3,6 +3,7 @@ package cryptohelper
 import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
+	"crypto/sha256"
 	"errors"
 )
 
@@ -20,3 +21,11 @@ func BytesToPublicKey(pubKeyBytes []byte) (*ecdsa.PublicKey, error) {
 
 	return pubKey, nil
 }
+
+// PublicKeyToAddress returns the 20 byte address of a public key: the last
+// 20 bytes of the sha256 of X || Y.
+func PublicKeyToAddress(pub *ecdsa.PublicKey) []byte {
+	pubBytes := append(pub.X.Bytes(), pub.Y.Bytes()...)
+	hash := sha256.Sum256(pubBytes)
+	return hash[len(hash)-20:]
+}
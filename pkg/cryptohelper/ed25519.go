@@ -0,0 +1,61 @@
+package cryptohelper
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+)
+
+// Ed25519: public key 32 byte, chữ ký 64 byte theo RFC 8032. Khoá bí mật
+// lưu trong keystore là seed 32 byte.
+
+type ed25519Scheme struct{}
+
+type ed25519Key struct {
+	key ed25519.PrivateKey
+}
+
+func (ed25519Scheme) ID() SchemeID { return Ed25519 }
+
+func (ed25519Scheme) GenerateKey() (PrivateKey, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return ed25519Key{key: key}, nil
+}
+
+func (ed25519Scheme) PrivateKeyFromBytes(b []byte) (PrivateKey, error) {
+	if len(b) != ed25519.SeedSize {
+		return nil, errors.New("invalid Ed25519 private key")
+	}
+	return ed25519Key{key: ed25519.NewKeyFromSeed(b)}, nil
+}
+
+func (ed25519Scheme) Verify(pub, hash, sig []byte) bool {
+	if len(pub) != ed25519.PublicKeySize || len(sig) != SignatureSize {
+		return false
+	}
+	return ed25519.Verify(pub, hash, sig)
+}
+
+func (ed25519Scheme) Address(pub []byte) ([]byte, error) {
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, errors.New("Ed25519 public key must be 32 bytes")
+	}
+	return schemeAddress(Ed25519, pub), nil
+}
+
+func (k ed25519Key) Scheme() SchemeID { return Ed25519 }
+
+func (k ed25519Key) Bytes() []byte {
+	return k.key.Seed()
+}
+
+func (k ed25519Key) PublicKey() []byte {
+	return []byte(k.key.Public().(ed25519.PublicKey))
+}
+
+func (k ed25519Key) Sign(hash []byte) ([]byte, error) {
+	return ed25519.Sign(k.key, hash), nil
+}
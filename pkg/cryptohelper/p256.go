@@ -0,0 +1,107 @@
+package cryptohelper
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+// P256: ECDSA trên NIST P-256, public key không nén 65 byte (0x04 || X || Y)
+// và chữ ký r || s, mỗi phần đệm đủ 32 byte, với s ở nửa dưới (s <= N/2) để
+// mỗi chữ ký chỉ có một dạng. Địa chỉ giữ nguyên công thức cũ của
+// PublicKeyToAddress.
+
+type p256Scheme struct{}
+
+// P256PrivateKey is the PrivateKey of an ECDSA key on P-256.
+type P256PrivateKey struct {
+	Key *ecdsa.PrivateKey
+}
+
+func (p256Scheme) ID() SchemeID { return P256 }
+
+func (p256Scheme) GenerateKey() (PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return P256PrivateKey{Key: key}, nil
+}
+
+func (p256Scheme) PrivateKeyFromBytes(b []byte) (PrivateKey, error) {
+	curve := elliptic.P256()
+	d := new(big.Int).SetBytes(b)
+	if len(b) != 32 || d.Sign() == 0 || d.Cmp(curve.Params().N) >= 0 {
+		return nil, errors.New("invalid P-256 private key")
+	}
+	key := &ecdsa.PrivateKey{D: d}
+	key.PublicKey.Curve = curve
+	key.PublicKey.X, key.PublicKey.Y = curve.ScalarBaseMult(b)
+	return P256PrivateKey{Key: key}, nil
+}
+
+// p256HalfOrder is N/2; a signature whose s is above it is the high-S twin
+// of a valid one.
+var p256HalfOrder = new(big.Int).Rsh(elliptic.P256().Params().N, 1)
+
+func (p256Scheme) Verify(pub, hash, sig []byte) bool {
+	key, err := BytesToPublicKey(pub)
+	if err != nil || len(sig) != SignatureSize {
+		return false
+	}
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:])
+	if s.Cmp(p256HalfOrder) > 0 {
+		return false
+	}
+	return ecdsa.Verify(key, hash, r, s)
+}
+
+// VerifyLegacyP256 checks a P-256 signature in the encoding written before
+// signature schemes: r || s without padding, split in the middle, and s in
+// either half. Only transactions already in blocks may use it.
+func VerifyLegacyP256(pub, hash, sig []byte) bool {
+	key, err := BytesToPublicKey(pub)
+	if err != nil || len(sig) == 0 {
+		return false
+	}
+	r := new(big.Int).SetBytes(sig[:len(sig)/2])
+	s := new(big.Int).SetBytes(sig[len(sig)/2:])
+	return ecdsa.Verify(key, hash, r, s)
+}
+
+func (p256Scheme) Address(pub []byte) ([]byte, error) {
+	key, err := BytesToPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	return PublicKeyToAddress(key), nil
+}
+
+func (k P256PrivateKey) Scheme() SchemeID { return P256 }
+
+func (k P256PrivateKey) Bytes() []byte {
+	return k.Key.D.FillBytes(make([]byte, 32))
+}
+
+func (k P256PrivateKey) PublicKey() []byte {
+	return elliptic.Marshal(k.Key.Curve, k.Key.X, k.Key.Y)
+}
+
+func (k P256PrivateKey) Sign(hash []byte) ([]byte, error) {
+	r, s, err := ecdsa.Sign(rand.Reader, k.Key, hash)
+	if err != nil {
+		return nil, err
+	}
+	// (r, N-s) cũng là chữ ký hợp lệ; luôn chọn s ở nửa dưới
+	if s.Cmp(p256HalfOrder) > 0 {
+		s.Sub(k.Key.Curve.Params().N, s)
+	}
+	// r và s có thể ngắn hơn 32 byte; đệm số 0 để chữ ký luôn tách đúng ở giữa
+	sig := make([]byte, SignatureSize)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return sig, nil
+}
@@ -0,0 +1,100 @@
+package cryptohelper
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+// SchemeID identifies the signature scheme of a transaction. It is part of
+// the signed hash, so a signature can not be checked under another scheme.
+// P256 is 0, so transactions made before schemes existed keep their hash.
+type SchemeID int32
+
+const (
+	P256 SchemeID = iota
+	Secp256k1
+	Ed25519
+)
+
+// Độ dài cố định của chữ ký ở mọi scheme: r || s 32 byte mỗi phần với
+// ECDSA, và chữ ký 64 byte của Ed25519
+const SignatureSize = 64
+
+// ErrUnknownScheme is returned for a scheme ID or name that is not
+// supported.
+var ErrUnknownScheme = errors.New("unknown signature scheme")
+
+// PrivateKey is the private key of a scheme.
+type PrivateKey interface {
+	Scheme() SchemeID
+	// Bytes returns the 32 byte private key, as stored in keystores.
+	Bytes() []byte
+	// PublicKey returns the encoded public key carried in transactions.
+	PublicKey() []byte
+	// Sign returns the SignatureSize byte signature of hash.
+	Sign(hash []byte) ([]byte, error)
+}
+
+// Scheme is a signature scheme: its keys, signature and address.
+type Scheme interface {
+	ID() SchemeID
+	GenerateKey() (PrivateKey, error)
+	// PrivateKeyFromBytes parses a key returned by PrivateKey.Bytes.
+	PrivateKeyFromBytes(b []byte) (PrivateKey, error)
+	// Verify reports whether sig is a signature of hash by the encoded
+	// public key pub. Encodings other than the canonical ones are rejected.
+	Verify(pub, hash, sig []byte) bool
+	// Address returns the 20 byte address of the encoded public key pub.
+	Address(pub []byte) ([]byte, error)
+}
+
+var schemes = map[SchemeID]Scheme{
+	P256:      p256Scheme{},
+	Secp256k1: secp256k1Scheme{},
+	Ed25519:   ed25519Scheme{},
+}
+
+var schemeNames = map[SchemeID]string{
+	P256:      "p256",
+	Secp256k1: "secp256k1",
+	Ed25519:   "ed25519",
+}
+
+// LookupScheme returns the scheme with the given ID.
+func LookupScheme(id SchemeID) (Scheme, error) {
+	s, ok := schemes[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownScheme, id)
+	}
+	return s, nil
+}
+
+// ParseScheme returns the scheme ID of a name as returned by String; an
+// empty name is P256.
+func ParseScheme(name string) (SchemeID, error) {
+	if name == "" {
+		return P256, nil
+	}
+	for id, n := range schemeNames {
+		if n == name {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("%w %q (p256, secp256k1 or ed25519)", ErrUnknownScheme, name)
+}
+
+func (id SchemeID) String() string {
+	if name, ok := schemeNames[id]; ok {
+		return name
+	}
+	return fmt.Sprintf("scheme(%d)", id)
+}
+
+// schemeAddress is the address of a key of the schemes added after P256:
+// the last 20 bytes of sha256(scheme ID || public key), so equal key bytes
+// of two schemes never share an address.
+func schemeAddress(id SchemeID, key []byte) []byte {
+	hash := sha256.Sum256(append([]byte{byte(id)}, key...))
+	return hash[len(hash)-20:]
+}
@@ -0,0 +1,85 @@
+package cryptohelper
+
+import (
+	"errors"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+// Secp256k1: ECDSA trên đường cong của Bitcoin, public key nén 33 byte và
+// chữ ký r || s 64 byte. Nonce được sinh theo RFC 6979 và s luôn ở nửa dưới,
+// nên mỗi giao dịch chỉ có một chữ ký hợp lệ.
+
+type secp256k1Scheme struct{}
+
+type secp256k1Key struct {
+	key *secp256k1.PrivateKey
+}
+
+func (secp256k1Scheme) ID() SchemeID { return Secp256k1 }
+
+func (secp256k1Scheme) GenerateKey() (PrivateKey, error) {
+	key, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	return secp256k1Key{key: key}, nil
+}
+
+func (secp256k1Scheme) PrivateKeyFromBytes(b []byte) (PrivateKey, error) {
+	var d secp256k1.ModNScalar
+	if len(b) != 32 || d.SetByteSlice(b) || d.IsZero() {
+		return nil, errors.New("invalid secp256k1 private key")
+	}
+	return secp256k1Key{key: secp256k1.NewPrivateKey(&d)}, nil
+}
+
+// parsePublicKey only accepts the compressed encoding, so a key has one
+// encoding.
+func (secp256k1Scheme) parsePublicKey(pub []byte) (*secp256k1.PublicKey, error) {
+	if len(pub) != secp256k1.PubKeyBytesLenCompressed {
+		return nil, errors.New("secp256k1 public key must be 33 bytes, compressed")
+	}
+	return secp256k1.ParsePubKey(pub)
+}
+
+func (s secp256k1Scheme) Verify(pub, hash, sig []byte) bool {
+	key, err := s.parsePublicKey(pub)
+	if err != nil || len(sig) != SignatureSize {
+		return false
+	}
+	var r, sv secp256k1.ModNScalar
+	if r.SetByteSlice(sig[:32]) || sv.SetByteSlice(sig[32:]) || r.IsZero() || sv.IsZero() || sv.IsOverHalfOrder() {
+		return false
+	}
+	return ecdsa.NewSignature(&r, &sv).Verify(hash, key)
+}
+
+func (s secp256k1Scheme) Address(pub []byte) ([]byte, error) {
+	key, err := s.parsePublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	// X || Y không nén, 32 byte mỗi phần
+	return schemeAddress(Secp256k1, key.SerializeUncompressed()[1:]), nil
+}
+
+func (k secp256k1Key) Scheme() SchemeID { return Secp256k1 }
+
+func (k secp256k1Key) Bytes() []byte {
+	return k.key.Serialize()
+}
+
+func (k secp256k1Key) PublicKey() []byte {
+	return k.key.PubKey().SerializeCompressed()
+}
+
+func (k secp256k1Key) Sign(hash []byte) ([]byte, error) {
+	sig := ecdsa.Sign(k.key, hash)
+	r, s := sig.R(), sig.S()
+	out := make([]byte, SignatureSize)
+	r.PutBytesUnchecked(out[:32])
+	s.PutBytesUnchecked(out[32:])
+	return out, nil
+}
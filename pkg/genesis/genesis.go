@@ -0,0 +1,246 @@
+package genesis
+
+import (
+	"blockchain-go/pkg/blockchain"
+	"blockchain-go/pkg/state"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// genesis.json describes the first block of a chain:
+//
+//	{
+//	  "chain_id": "bcgo-local",
+//	  "timestamp": 1700000000,
+//	  "validators": [{ "id": "node1", "address": "node1:50051" }, ...],
+//	  "params": { "max_block_txs": 10, "block_interval_ms": 5000, "tx_root": "binary" },
+//	  "alloc": { "<address>": { "balance": 1000.0 }, ... }
+//	}
+//
+// Every node of a network must use the same file: the genesis block built
+// from it (and so its hash) only depends on its content, not on the order of
+// the JSON keys or on when it is built.
+
+// Default values of the chain parameters left out of genesis.json.
+const (
+	DefaultMaxBlockTxs     = 10
+	DefaultBlockIntervalMs = 5000
+)
+
+// AddressLength is the length in bytes of an account address.
+const AddressLength = 20
+
+var chainIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// Genesis is the content of genesis.json.
+type Genesis struct {
+	ChainID    string             `json:"chain_id"`
+	Timestamp  int64              `json:"timestamp"`
+	Validators []Validator        `json:"validators"`
+	Params     Params             `json:"params"`
+	Alloc      map[string]Account `json:"alloc"`
+}
+
+// Validator is a node taking part in consensus from the first block.
+type Validator struct {
+	ID      string `json:"id"`
+	Address string `json:"address,omitempty"`
+}
+
+// Params are the chain parameters every node must agree on.
+type Params struct {
+	// MaxBlockTxs is the largest number of transactions in a block.
+	MaxBlockTxs int `json:"max_block_txs"`
+	// BlockIntervalMs is how long the leader waits for more transactions
+	// before proposing a block that is not full.
+	BlockIntervalMs int64 `json:"block_interval_ms"`
+	// TxRoot is how blocks commit to their transactions: "mpt" (the
+	// default) or "binary". See blockchain.TxRootType.
+	TxRoot string `json:"tx_root,omitempty"`
+}
+
+// TxRootType returns the parsed TxRoot; Validate has checked it.
+func (p Params) TxRootType() blockchain.TxRootType {
+	t, _ := blockchain.ParseTxRootType(p.TxRoot)
+	return t
+}
+
+// BlockInterval returns BlockIntervalMs as a duration.
+func (p Params) BlockInterval() time.Duration {
+	return time.Duration(p.BlockIntervalMs) * time.Millisecond
+}
+
+// Account is the starting balance of an address.
+type Account struct {
+	Balance float64 `json:"balance"`
+}
+
+// Load reads and validates a genesis file.
+func Load(path string) (*Genesis, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read genesis file: %w", err)
+	}
+	g, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return g, nil
+}
+
+// Parse decodes and validates the content of a genesis file. Unknown fields
+// are rejected, so a typo does not silently fall back to a default.
+func Parse(data []byte) (*Genesis, error) {
+	var g Genesis
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&g); err != nil {
+		return nil, fmt.Errorf("invalid genesis JSON: %w", err)
+	}
+	if g.Params.MaxBlockTxs == 0 {
+		g.Params.MaxBlockTxs = DefaultMaxBlockTxs
+	}
+	if g.Params.BlockIntervalMs == 0 {
+		g.Params.BlockIntervalMs = DefaultBlockIntervalMs
+	}
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// Validate checks the fields of the genesis and normalizes the addresses to
+// lower-case hex.
+func (g *Genesis) Validate() error {
+	if g.ChainID == "" {
+		return errors.New("chain_id is required")
+	}
+	if !chainIDPattern.MatchString(g.ChainID) {
+		return fmt.Errorf("chain_id %q must be 1-64 letters, digits, '.', '_' or '-'", g.ChainID)
+	}
+	if g.Timestamp < 0 {
+		return fmt.Errorf("timestamp %d is negative", g.Timestamp)
+	}
+
+	ids := make(map[string]bool)
+	for i, v := range g.Validators {
+		if v.ID == "" {
+			return fmt.Errorf("validator %d has no id", i)
+		}
+		// ID là Proposer của block, một trường string của protobuf
+		if !utf8.ValidString(v.ID) {
+			return fmt.Errorf("validator %d id %q is not valid UTF-8", i, v.ID)
+		}
+		if ids[v.ID] {
+			return fmt.Errorf("validator %q is listed twice", v.ID)
+		}
+		ids[v.ID] = true
+	}
+
+	if g.Params.MaxBlockTxs <= 0 {
+		return fmt.Errorf("params.max_block_txs must be positive, got %d", g.Params.MaxBlockTxs)
+	}
+	if g.Params.BlockIntervalMs <= 0 {
+		return fmt.Errorf("params.block_interval_ms must be positive, got %d", g.Params.BlockIntervalMs)
+	}
+	if _, err := blockchain.ParseTxRootType(g.Params.TxRoot); err != nil {
+		return fmt.Errorf("params.tx_root: %w", err)
+	}
+
+	if len(g.Alloc) == 0 {
+		return errors.New("alloc must fund at least one account")
+	}
+	alloc := make(map[string]Account, len(g.Alloc))
+	for addr, acc := range g.Alloc {
+		raw, err := hex.DecodeString(addr)
+		if err != nil || len(raw) != AddressLength {
+			return fmt.Errorf("alloc address %q is not %d bytes of hex", addr, AddressLength)
+		}
+		if math.IsNaN(acc.Balance) || math.IsInf(acc.Balance, 0) || acc.Balance <= 0 {
+			return fmt.Errorf("alloc balance of %s must be a positive number", addr)
+		}
+		normalized := strings.ToLower(addr)
+		if _, ok := alloc[normalized]; ok {
+			return fmt.Errorf("alloc address %s is listed twice", normalized)
+		}
+		alloc[normalized] = acc
+	}
+	g.Alloc = alloc
+	return nil
+}
+
+// Addresses returns the funded addresses in ascending order.
+func (g *Genesis) Addresses() []string {
+	addrs := make([]string, 0, len(g.Alloc))
+	for addr := range g.Alloc {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	return addrs
+}
+
+// ConfigHash is the sha256 of everything in the genesis except the balances,
+// which the genesis block already commits to through its transactions.
+func (g *Genesis) ConfigHash() []byte {
+	data, err := json.Marshal(struct {
+		ChainID    string      `json:"chain_id"`
+		Timestamp  int64       `json:"timestamp"`
+		Validators []Validator `json:"validators"`
+		Params     Params      `json:"params"`
+	}{g.ChainID, g.Timestamp, g.Validators, g.Params})
+	if err != nil {
+		panic(fmt.Sprintf("genesis config encoding failed: %v", err))
+	}
+	hash := sha256.Sum256(data)
+	return hash[:]
+}
+
+// Block builds the genesis block: one GENESIS transfer per funded address,
+// in address order, at the genesis timestamp.
+func (g *Genesis) Block() *blockchain.Block {
+	var txs []*blockchain.Transaction
+	var accounts []state.Account
+	for _, addr := range g.Addresses() {
+		receiver, _ := hex.DecodeString(addr)
+		balance := g.Alloc[addr].Balance
+		txs = append(txs, &blockchain.Transaction{
+			Sender:   []byte("GENESIS"),
+			Receiver: receiver,
+			Amount:   balance,
+			ChainID:  g.ChainID,
+		})
+		accounts = append(accounts, state.Account{Address: addr, Balance: balance})
+	}
+
+	// Header của genesis cam kết state root sau khi cấp vốn
+	block := blockchain.NewChainBlock(g.ChainID, g.Params.TxRootType(), txs, []byte{}, 0, state.ComputeStateRoot(accounts), "")
+	block.Timestamp = g.Timestamp
+	block.ConfigHash = g.ConfigHash()
+	block.CurrentBlockHash = block.Hash()
+	return block
+}
+
+// IsValidator reports whether nodeID is one of the genesis validators. With
+// no validators listed every node is accepted.
+func (g *Genesis) IsValidator(nodeID string) bool {
+	if len(g.Validators) == 0 {
+		return true
+	}
+	for _, v := range g.Validators {
+		if v.ID == nodeID {
+			return true
+		}
+	}
+	return false
+}
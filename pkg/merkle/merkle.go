@@ -0,0 +1,156 @@
+package merkle
+
+// Ordered binary Merkle tree as in RFC 6962 (section 2.1): leaves and inner
+// nodes are hashed with different prefixes, so an inner node can never be
+// passed off as a leaf (second-preimage attack), and a level with an odd
+// number of nodes is not padded by duplicating the last one, so two
+// different lists never have the same root.
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+// HashSize is the size of every hash in the tree.
+const HashSize = sha256.Size
+
+const (
+	leafPrefix  = 0x00
+	innerPrefix = 0x01
+)
+
+// maxProofHashes bounds a decoded proof: a tree of 2^64 leaves is 64 levels.
+const maxProofHashes = 64
+
+// ErrInvalidProof is returned when an encoded proof can not be decoded.
+var ErrInvalidProof = errors.New("invalid merkle proof")
+
+// EmptyRoot is the root of a tree with no leaves, the hash of no data.
+var EmptyRoot = func() []byte { h := sha256.Sum256(nil); return h[:] }()
+
+// LeafHash is the hash of a leaf: sha256(0x00 || data).
+func LeafHash(data []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// innerHash is the hash of an inner node: sha256(0x01 || left || right).
+func innerHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{innerPrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// Root returns the root of the tree whose leaves are data, in order.
+func Root(data [][]byte) []byte {
+	if len(data) == 0 {
+		return EmptyRoot
+	}
+	return subtreeRoot(leafHashes(data))
+}
+
+func leafHashes(data [][]byte) [][]byte {
+	hashes := make([][]byte, len(data))
+	for i, d := range data {
+		hashes[i] = LeafHash(d)
+	}
+	return hashes
+}
+
+// subtreeRoot hashes a non-empty run of leaf hashes. The left subtree holds
+// the largest power of two smaller than the number of leaves.
+func subtreeRoot(hashes [][]byte) []byte {
+	if len(hashes) == 1 {
+		return hashes[0]
+	}
+	k := split(len(hashes))
+	return innerHash(subtreeRoot(hashes[:k]), subtreeRoot(hashes[k:]))
+}
+
+// split returns the largest power of two smaller than n, for n > 1.
+func split(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+// Proof is the audit path of a leaf: the hashes of the sibling subtrees from
+// the leaf up to the root. With the leaf's index and the number of leaves it
+// is enough to recompute the root.
+type Proof [][]byte
+
+// Prove returns the audit path of the leaf at index.
+func Prove(data [][]byte, index int) (Proof, error) {
+	if index < 0 || index >= len(data) {
+		return nil, fmt.Errorf("leaf index %d out of range (%d leaves)", index, len(data))
+	}
+	return path(leafHashes(data), index), nil
+}
+
+func path(hashes [][]byte, index int) Proof {
+	if len(hashes) == 1 {
+		return nil
+	}
+	k := split(len(hashes))
+	if index < k {
+		return append(path(hashes[:k], index), subtreeRoot(hashes[k:]))
+	}
+	return append(path(hashes[k:], index-k), subtreeRoot(hashes[:k]))
+}
+
+// Verify checks that proof shows data as the leaf at index of a tree of size
+// leaves with the given root (RFC 9162, section 2.1.3.2).
+func Verify(root, data []byte, index, size int, proof Proof) bool {
+	if index < 0 || index >= size {
+		return false
+	}
+	fn, sn := index, size-1
+	r := LeafHash(data)
+	for _, p := range proof {
+		if len(p) != HashSize || sn == 0 {
+			return false
+		}
+		if fn&1 == 1 || fn == sn {
+			r = innerHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = innerHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 && bytes.Equal(r, root)
+}
+
+// Encode serializes the proof as its hashes one after the other; the index
+// and the number of leaves are known to the verifier.
+func (p Proof) Encode() []byte {
+	out := make([]byte, 0, len(p)*HashSize)
+	for _, h := range p {
+		out = append(out, h...)
+	}
+	return out
+}
+
+// DecodeProof parses a proof produced by Proof.Encode.
+func DecodeProof(data []byte) (Proof, error) {
+	if len(data)%HashSize != 0 || len(data)/HashSize > maxProofHashes {
+		return nil, fmt.Errorf("%w: %d bytes", ErrInvalidProof, len(data))
+	}
+	proof := make(Proof, len(data)/HashSize)
+	for i := range proof {
+		proof[i] = append([]byte{}, data[i*HashSize:(i+1)*HashSize]...)
+	}
+	return proof, nil
+}
@@ -0,0 +1,379 @@
+package mpt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// KeyValueStore is the storage a Database keeps its nodes in. Get returns a
+// nil value, not an error, for a missing key. Write applies every change
+// atomically; a nil value deletes the key.
+type KeyValueStore interface {
+	Get(key []byte) ([]byte, error)
+	Write(changes map[string][]byte) error
+}
+
+// MissingNodeError is returned when a trie needs a node its database does
+// not have, e.g. because the root it was opened at has been pruned.
+type MissingNodeError struct {
+	Hash []byte
+}
+
+func (e *MissingNodeError) Error() string {
+	return fmt.Sprintf("trie node %x is missing", e.Hash)
+}
+
+var errNoDatabase = errors.New("trie has no database")
+
+// Database stores the nodes of persistent tries by hash. Every stored node
+// has a reference count: one per stored parent that refers to it by hash and
+// one per named root (SetRoot) that is the node. When a count drops to zero
+// the node is deleted and its children are released in turn, so the nodes
+// only reachable from roots that are no longer kept are pruned. Nodes shorter
+// than 32 bytes are embedded in their parent and not stored on their own.
+//
+// Changes are kept in memory until Flush writes them in one atomic batch.
+type Database struct {
+	store KeyValueStore
+
+	mu    sync.Mutex
+	dirty map[string]*dbNode // nodes changed since the last Flush
+	roots map[string][]byte  // named roots changed since the last Flush
+}
+
+type dbNode struct {
+	enc  []byte
+	refs uint32
+	// freed is set once refs dropped to zero and the children have been
+	// released; the node is deleted by the next Flush.
+	freed bool
+}
+
+func NewDatabase(store KeyValueStore) *Database {
+	return &Database{store: store, dirty: make(map[string]*dbNode), roots: make(map[string][]byte)}
+}
+
+// Stored records: node/<hash> → reference count (4 bytes) and encoding,
+// root/<name> → root hash.
+func nodeKey(hash []byte) string { return "node/" + string(hash) }
+func rootKey(name string) string { return "root/" + name }
+
+// node loads and decodes the node with the given hash.
+func (d *Database) node(hash []byte) (Node, error) {
+	d.mu.Lock()
+	e, err := d.lookup(hash, false)
+	d.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return nil, &MissingNodeError{Hash: hash}
+	}
+	n, err := decodeNode(e.enc)
+	if err != nil {
+		return nil, fmt.Errorf("trie node %x is corrupted: %w", hash, err)
+	}
+	if flags := n.cache(); flags != nil && len(e.enc) >= 32 {
+		*flags = nodeFlag{hash: hashNode(hash), clean: true}
+	}
+	return n, nil
+}
+
+// lookup returns the entry of a node, nil if it is not stored. With keep,
+// an entry read from the store is kept in the dirty set so it can be
+// changed. The caller holds mu.
+func (d *Database) lookup(hash []byte, keep bool) (*dbNode, error) {
+	if e, ok := d.dirty[string(hash)]; ok {
+		if e.freed {
+			return nil, nil
+		}
+		return e, nil
+	}
+	data, err := d.store.Get([]byte(nodeKey(hash)))
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, nil
+	}
+	if len(data) < 4 {
+		return nil, fmt.Errorf("trie node %x is corrupted", hash)
+	}
+	e := &dbNode{refs: binary.BigEndian.Uint32(data), enc: data[4:]}
+	if keep {
+		d.dirty[string(hash)] = e
+	}
+	return e, nil
+}
+
+// commit stores n and the nodes below it that are referenced by hash, and
+// returns what its parent should hold instead: a hashNode, or n itself when
+// it is embedded. Clean nodes are already stored and are not visited. With
+// parallel, the children of the branch at the top of n are committed in
+// their own goroutines.
+func (d *Database) commit(n Node, parallel bool) (Node, error) {
+	switch n := n.(type) {
+	case nil, hashNode:
+		return n, nil
+	}
+	flags := n.cache()
+	if flags.clean {
+		return flags.hash, nil
+	}
+
+	var committed Node
+	switch n := n.(type) {
+	case *LeafNode:
+		committed = n
+	case *ExtensionNode:
+		child, err := d.commit(n.Child, parallel)
+		if err != nil {
+			return nil, err
+		}
+		committed = &ExtensionNode{Key: n.Key, Child: child}
+	case *BranchNode:
+		branch := n.copy()
+		if err := d.commitChildren(branch, parallel); err != nil {
+			return nil, err
+		}
+		committed = branch
+	}
+
+	// Node đã commit mã hoá giống hệt n, nên dùng lại được hash đã cache
+	enc := committed.encode()
+	if len(enc) < 32 {
+		return committed, nil
+	}
+	if flags.hash == nil {
+		flags.hash = Keccak256(enc)
+	}
+	if err := d.insert(flags.hash, enc, hashedChildren(committed)); err != nil {
+		return nil, err
+	}
+	return flags.hash, nil
+}
+
+// commitChildren replaces the children of branch, a copy, by what commit
+// returns for them. Subtrees share no node, so they can be committed at the
+// same time; insert takes the lock for each node it stores.
+func (d *Database) commitChildren(branch *BranchNode, parallel bool) error {
+	if !parallel {
+		for i, child := range branch.Children {
+			c, err := d.commit(child, false)
+			if err != nil {
+				return err
+			}
+			branch.Children[i] = c
+		}
+		return nil
+	}
+	var wg sync.WaitGroup
+	errs := make([]error, len(branch.Children))
+	for i, child := range branch.Children {
+		wg.Add(1)
+		go func(i int, child Node) {
+			defer wg.Done()
+			branch.Children[i], errs[i] = d.commit(child, false)
+		}(i, child)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// insert stores a node that is not referenced yet. A node that is already
+// stored is left alone: its children are counted once, whatever the number
+// of parents it has.
+func (d *Database) insert(hash, enc []byte, children [][]byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	e, err := d.lookup(hash, true)
+	if err != nil || e != nil {
+		return err
+	}
+	for _, child := range children {
+		c, err := d.lookup(child, true)
+		if err != nil {
+			return err
+		}
+		if c == nil {
+			return &MissingNodeError{Hash: child}
+		}
+		c.refs++
+	}
+	d.dirty[string(hash)] = &dbNode{enc: enc}
+	return nil
+}
+
+// hashedChildren returns the hashes of the children n refers to by hash.
+func hashedChildren(n Node) [][]byte {
+	var hashes [][]byte
+	switch n := n.(type) {
+	case *ExtensionNode:
+		if h, ok := n.Child.(hashNode); ok {
+			hashes = append(hashes, h)
+		}
+	case *BranchNode:
+		for _, child := range n.Children {
+			if h, ok := child.(hashNode); ok {
+				hashes = append(hashes, h)
+			}
+		}
+	}
+	return hashes
+}
+
+// Reference adds a reference to the node with the given hash, so it and the
+// nodes below it are kept until a matching Dereference.
+func (d *Database) Reference(hash []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.reference(hash)
+}
+
+func (d *Database) reference(hash []byte) error {
+	if bytes.Equal(hash, EmptyRoot) {
+		return nil
+	}
+	e, err := d.lookup(hash, true)
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		return &MissingNodeError{Hash: hash}
+	}
+	e.refs++
+	return nil
+}
+
+// Dereference drops a reference added by Reference, pruning the nodes that
+// are no longer referenced.
+func (d *Database) Dereference(hash []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.dereference(hash)
+}
+
+func (d *Database) dereference(hash []byte) error {
+	if bytes.Equal(hash, EmptyRoot) {
+		return nil
+	}
+	e, err := d.lookup(hash, true)
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		return &MissingNodeError{Hash: hash}
+	}
+	if e.refs == 0 {
+		return fmt.Errorf("trie node %x is not referenced", hash)
+	}
+	e.refs--
+	if e.refs == 0 {
+		return d.free(hash, e)
+	}
+	return nil
+}
+
+// free releases the children of a node whose count dropped to zero.
+func (d *Database) free(hash []byte, e *dbNode) error {
+	e.freed = true
+	n, err := decodeNode(e.enc)
+	if err != nil {
+		return fmt.Errorf("trie node %x is corrupted: %w", hash, err)
+	}
+	for _, child := range hashedChildren(n) {
+		if err := d.dereference(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetRoot keeps root under name, replacing the root kept there before. The
+// old root's nodes that the new root does not share are pruned.
+func (d *Database) SetRoot(name string, root []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	old, err := d.root(name)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(old, root) {
+		return nil
+	}
+	// Tham chiếu root mới trước để các node dùng chung không bị xoá
+	if err := d.reference(root); err != nil {
+		return err
+	}
+	if len(old) > 0 {
+		if err := d.dereference(old); err != nil {
+			return err
+		}
+	}
+	d.roots[name] = append([]byte{}, root...)
+	return nil
+}
+
+// Root returns the root kept under name, nil if there is none.
+func (d *Database) Root(name string) ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.root(name)
+}
+
+func (d *Database) root(name string) ([]byte, error) {
+	if root, ok := d.roots[name]; ok {
+		return root, nil
+	}
+	return d.store.Get([]byte(rootKey(name)))
+}
+
+// Flush writes the changes since the last Flush to the store. Nodes that
+// were committed but never referenced are dropped.
+func (d *Database) Flush() error {
+	return d.FlushWith(d.store.Write)
+}
+
+// FlushWith is Flush with the changes passed to write instead of the store,
+// so a caller can write them in the same batch as its own data. The keys are
+// those of the store. When write fails the changes are kept for the next
+// flush.
+func (d *Database) FlushWith(write func(changes map[string][]byte) error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for garbage := true; garbage; {
+		garbage = false
+		for hash, e := range d.dirty {
+			if e.refs == 0 && !e.freed {
+				garbage = true
+				if err := d.free([]byte(hash), e); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	changes := make(map[string][]byte, len(d.dirty)+len(d.roots))
+	for hash, e := range d.dirty {
+		if e.freed {
+			changes[nodeKey([]byte(hash))] = nil
+			continue
+		}
+		value := make([]byte, 4+len(e.enc))
+		binary.BigEndian.PutUint32(value, e.refs)
+		copy(value[4:], e.enc)
+		changes[nodeKey([]byte(hash))] = value
+	}
+	for name, root := range d.roots {
+		changes[rootKey(name)] = root
+	}
+	if err := write(changes); err != nil {
+		return err
+	}
+	d.dirty = make(map[string]*dbNode)
+	d.roots = make(map[string][]byte)
+	return nil
+}
@@ -1,32 +1,225 @@
 package mpt
 
+import (
+	"bytes"
+	"sort"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// MPT is a Merkle-Patricia Trie as specified in the Ethereum Yellow Paper
+// (appendix D): leaf, extension and branch nodes, hex-prefix encoded paths,
+// RLP-encoded nodes, Keccak-256 hashes and nodes shorter than 32 bytes
+// embedded in their parent. The root hash depends only on the key/value
+// pairs, not on the order they were inserted in.
+//
+// A trie made by NewMPT lives in memory. A trie opened on a Database loads
+// its nodes from there as they are needed, and Commit writes the new ones
+// back.
 type MPT struct {
 	Root Node
+	db   *Database
+	// unhashed counts the keys changed since the root was last hashed.
+	unhashed int
 }
 
+// parallelHashing is the number of changed keys from which the subtrees
+// below the root are hashed in parallel; for fewer, starting the goroutines
+// costs more than it saves.
+const parallelHashing = 100
+
+// Keccak256 returns the Keccak-256 hash of the concatenated inputs. This is
+// the original Keccak padding, not the FIPS-202 SHA3-256, so the trie roots
+// match the published test vectors.
+func Keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// EmptyRoot is the root hash of a trie with no keys.
+var EmptyRoot = Keccak256(rlpEmpty)
+
 func NewMPT() *MPT {
-	return &MPT{
-		Root: NewBranchNode(),
+	return &MPT{}
+}
+
+// OpenMPT returns the trie with the given root in db. Nothing is read until
+// the trie is used, so a missing root is only reported then.
+func OpenMPT(db *Database, root []byte) *MPT {
+	m := &MPT{db: db}
+	if len(root) > 0 && !bytes.Equal(root, EmptyRoot) {
+		m.Root = hashNode(append([]byte{}, root...))
 	}
+	return m
 }
 
-func (m *MPT) Insert(key []byte, value []byte) {
+// Insert sets key to value. The value is copied. As in the specification,
+// an empty value is the same as no value: inserting one deletes the key.
+func (m *MPT) Insert(key []byte, value []byte) error {
+	if len(value) == 0 {
+		_, err := m.Delete(key)
+		return err
+	}
 	nibbles := BytesToNibbles(key)
-	m.Root = m.Root.Insert(nibbles, value)
+	root, err := m.insert(m.Root, nibbles, append([]byte{}, value...))
+	if err != nil {
+		return err
+	}
+	m.Root = root
+	m.unhashed++
+	return nil
+}
+
+// Delete removes key and reports whether it was in the trie.
+func (m *MPT) Delete(key []byte) (bool, error) {
+	root, ok, err := m.remove(m.Root, BytesToNibbles(key))
+	if err != nil {
+		return false, err
+	}
+	m.Root = root
+	if ok {
+		m.unhashed++
+	}
+	return ok, nil
+}
+
+// Update applies a batch of changes, key -> value; a nil or empty value
+// deletes the key. The result is the same as applying them one by one, but
+// the nodes shared by several keys are rebuilt only once.
+func (m *MPT) Update(changes map[string][]byte) error {
+	batch := make([]change, 0, len(changes))
+	for key, value := range changes {
+		c := change{path: BytesToNibbles([]byte(key))}
+		if len(value) > 0 {
+			c.value = append([]byte{}, value...)
+		}
+		batch = append(batch, c)
+	}
+	sort.Slice(batch, func(i, j int) bool { return bytes.Compare(batch[i].path, batch[j].path) < 0 })
+	root, err := m.update(m.Root, batch)
+	if err != nil {
+		return err
+	}
+	m.Root = root
+	m.unhashed += len(batch)
+	return nil
 }
 
-func (m *MPT) Get(key []byte) ([]byte, bool) {
+// Get returns the value of key, or nil if the key is not in the trie.
+func (m *MPT) Get(key []byte) ([]byte, error) {
 	nibbles := BytesToNibbles(key)
-	return m.Root.Get(nibbles)
+	return m.get(m.Root, nibbles)
 }
 
+// Fork returns a trie with the same contents. Nodes are never modified, so
+// the fork shares all of them and costs nothing; changes to either trie are
+// not seen by the other. A fork is how a block is executed speculatively: it
+// is updated and thrown away if the block turns out to be invalid.
+//
+// The trie is hashed first, so the nodes the two tries share already have
+// their hashes cached and the tries can then be used from different
+// goroutines.
+func (m *MPT) Fork() *MPT {
+	m.RootHash()
+	return &MPT{Root: m.Root, db: m.db}
+}
+
+// RootHash is the Keccak-256 of the root node's encoding. Hashes are cached
+// in the nodes, so only the nodes changed since the last call are hashed
+// again, and when many keys have changed the subtrees below the root are
+// hashed in parallel.
 func (m *MPT) RootHash() []byte {
-	return m.Root.Hash()
+	switch root := m.Root.(type) {
+	case nil:
+		return EmptyRoot
+	case hashNode:
+		return append([]byte{}, root...)
+	}
+	if m.unhashed >= parallelHashing {
+		hashChildren(m.Root)
+	}
+	m.unhashed = 0
+	flags := m.Root.cache()
+	if flags.hash == nil {
+		enc := m.Root.encode()
+		if len(enc) < 32 {
+			// Root ngắn được nhúng nếu là node con, nên không lưu hash
+			return Keccak256(enc)
+		}
+		flags.hash = Keccak256(enc)
+	}
+	return append([]byte{}, flags.hash...)
 }
 
-func (m *MPT) GenerateProof(key []byte) [][]byte {
-	nibbles := BytesToNibbles(key)
-	return m.Root.GenerateProof(nibbles)
+// Commit writes the nodes changed since the trie was opened to its database
+// and returns the root hash. The nodes are kept in the database's dirty cache
+// until it is flushed, and are only kept at all if the root is referenced,
+// e.g. with Database.SetRoot. The trie then loads its nodes from the
+// database again, so memory is only used by the paths that change.
+func (m *MPT) Commit() ([]byte, error) {
+	if m.db == nil {
+		return nil, errNoDatabase
+	}
+	if m.Root == nil {
+		return EmptyRoot, nil
+	}
+	root, err := m.db.commit(m.Root, m.unhashed >= parallelHashing)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := root.(hashNode); !ok {
+		// Root ngắn hơn 32 byte vẫn được lưu theo hash để mở lại được
+		enc := root.encode()
+		root = hashNode(Keccak256(enc))
+		if err := m.db.insert(root.(hashNode), enc, nil); err != nil {
+			return nil, err
+		}
+	}
+	m.Root = root
+	m.unhashed = 0
+	return m.RootHash(), nil
+}
+
+// GenerateProof returns the encodings of the nodes on the path of key that
+// are referenced by hash, starting with the root. It proves the value of key
+// (VerifyProof) or that key is absent (VerifyAbsence).
+func (m *MPT) GenerateProof(key []byte) (Proof, error) {
+	n, err := m.resolve(m.Root)
+	if err != nil {
+		return nil, err
+	}
+	if n == nil {
+		return Proof{rlpEmpty}, nil
+	}
+	path := BytesToNibbles(key)
+	proof := Proof{n.encode()}
+	for {
+		switch node := n.(type) {
+		case *ExtensionNode:
+			if !hasPrefix(path, node.Key) {
+				return proof, nil
+			}
+			path = path[len(node.Key):]
+			n = node.Child
+		case *BranchNode:
+			if len(path) == 0 || node.Children[path[0]] == nil {
+				return proof, nil
+			}
+			n = node.Children[path[0]]
+			path = path[1:]
+		default:
+			return proof, nil
+		}
+		if n, err = m.resolve(n); err != nil {
+			return nil, err
+		}
+		if enc := n.encode(); len(enc) >= 32 {
+			proof = append(proof, enc)
+		}
+	}
 }
 
 func BuildMPTFromTxHashes(txHashes [][]byte) (*MPT, []byte) {
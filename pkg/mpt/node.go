@@ -1,121 +1,458 @@
 package mpt
 
-import "crypto/sha256"
+import "sync"
 
+// Node is a node of the trie. A node is never modified once it is part of a
+// trie: an update builds new nodes along the changed path, so an old root
+// keeps describing the old contents.
 type Node interface {
-	Hash() []byte
-	Insert([]byte, []byte) Node
-	Get(path []byte) ([]byte, bool)
-	GenerateProof([]byte) [][]byte
+	// encode returns the RLP encoding of the node.
+	encode() []byte
+	// cache returns what the node caches about itself, nil for a hashNode.
+	cache() *nodeFlag
 }
 
+// nodeFlag is what a node caches about itself. The contents of a node never
+// change, so the cached hash stays valid; a node built from another one
+// starts with an empty flag. A node built in memory is dirty: it has no
+// cached hash until the trie is hashed, and is not in a Database until the
+// trie is committed. A node loaded from a Database is clean.
+type nodeFlag struct {
+	// hash is the hash of the node's encoding, set once computed. Nodes
+	// embedded in their parent (shorter than 32 bytes) are never given one.
+	hash hashNode
+	// clean is set on nodes loaded from a Database, which are stored there
+	// under hash.
+	clean bool
+}
+
+// LeafNode holds the rest of a key and its value.
 type LeafNode struct {
 	Key   []byte // Nibbles
 	Value []byte
+	flags nodeFlag
+}
+
+// ExtensionNode is a shared run of nibbles leading to a single child, which
+// is always a branch.
+type ExtensionNode struct {
+	Key   []byte // Nibbles
+	Child Node
+	flags nodeFlag
+}
+
+// BranchNode has one child per next nibble and the value of the key that
+// ends here, if any.
+type BranchNode struct {
+	Children [16]Node
+	Value    []byte
+	flags    nodeFlag
+}
+
+// hashNode stands for a node that is stored in a Database and has not been
+// loaded; it is resolved when a lookup or an update goes through it.
+type hashNode []byte
+
+func (n *LeafNode) encode() []byte {
+	return rlpList(rlpString(hexPrefix(n.Key, true)), rlpString(n.Value))
 }
 
-func (l *LeafNode) Hash() []byte {
-	data := append(append([]byte{0x00}, l.Key...), l.Value...)
-	h := sha256.Sum256(data)
-	return h[:]
+func (n *ExtensionNode) encode() []byte {
+	return rlpList(rlpString(hexPrefix(n.Key, false)), ref(n.Child))
 }
 
-func (l *LeafNode) Insert(path []byte, value []byte) Node {
-	// Replace this with full split logic if needed
-	if Equal(path, l.Key) {
-		l.Value = value
-		return l
+func (n *BranchNode) encode() []byte {
+	items := make([][]byte, 17)
+	for i, child := range n.Children {
+		if child == nil {
+			items[i] = rlpEmpty
+		} else {
+			items[i] = ref(child)
+		}
 	}
-	branch := NewBranchNode()
-	branch.Insert(l.Key, l.Value)
-	return branch.Insert(path, value)
+	items[16] = rlpString(n.Value)
+	return rlpList(items...)
+}
+
+func (n hashNode) encode() []byte {
+	panic("mpt: encode of a node that is not loaded")
 }
 
-func (l *LeafNode) Get(path []byte) ([]byte, bool) {
-	if Equal(path, l.Key) {
-		return l.Value, true
+func (n *LeafNode) cache() *nodeFlag      { return &n.flags }
+func (n *ExtensionNode) cache() *nodeFlag { return &n.flags }
+func (n *BranchNode) cache() *nodeFlag    { return &n.flags }
+func (n hashNode) cache() *nodeFlag       { return nil }
+
+// copy returns a branch with the same contents that can be changed. Its flag
+// is empty, as the caller is about to make it a different node.
+func (n *BranchNode) copy() *BranchNode {
+	branch := *n
+	branch.flags = nodeFlag{}
+	return &branch
+}
+
+// ref is how a parent refers to a child: a child whose encoding is shorter
+// than 32 bytes is embedded, any other is referenced by its hash. The hash is
+// cached, so a subtree is only hashed again when it has changed.
+func ref(n Node) []byte {
+	if h, ok := n.(hashNode); ok {
+		return rlpString(h)
+	}
+	flags := n.cache()
+	if flags.hash != nil {
+		return rlpString(flags.hash)
+	}
+	enc := n.encode()
+	if len(enc) < 32 {
+		return enc
 	}
-	return nil, false
+	flags.hash = Keccak256(enc)
+	return rlpString(flags.hash)
 }
 
-func (l *LeafNode) GenerateProof(path []byte) [][]byte {
-	if Equal(path, l.Key) {
-		return [][]byte{l.Hash()}
+// hashChildren computes the hashes of the children of the branch at the top
+// of n, each subtree in its own goroutine. The subtrees share no node, so
+// their caches can be filled at the same time; n itself is left to the
+// caller.
+func hashChildren(n Node) {
+	if ext, ok := n.(*ExtensionNode); ok {
+		n = ext.Child
 	}
-	return nil
+	branch, ok := n.(*BranchNode)
+	if !ok {
+		return
+	}
+	var wg sync.WaitGroup
+	for _, child := range branch.Children {
+		if child == nil || child.cache() == nil || child.cache().hash != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(child Node) {
+			defer wg.Done()
+			ref(child)
+		}(child)
+	}
+	wg.Wait()
 }
 
-type BranchNode struct {
-	Branches [16]Node
-	Value    []byte // Optional value at this node
+// resolve loads n from the database if it is a hashNode.
+func (m *MPT) resolve(n Node) (Node, error) {
+	h, ok := n.(hashNode)
+	if !ok {
+		return n, nil
+	}
+	if m.db == nil {
+		return nil, &MissingNodeError{Hash: h}
+	}
+	return m.db.node(h)
 }
 
-func NewBranchNode() *BranchNode {
-	return &BranchNode{}
+// insert returns the node that replaces n once path is set to value.
+func (m *MPT) insert(n Node, path, value []byte) (Node, error) {
+	n, err := m.resolve(n)
+	if err != nil {
+		return nil, err
+	}
+	switch n := n.(type) {
+	case nil:
+		return &LeafNode{Key: path, Value: value}, nil
+
+	case *LeafNode:
+		p := commonPrefix(path, n.Key)
+		if p == len(path) && p == len(n.Key) {
+			return &LeafNode{Key: n.Key, Value: value}, nil
+		}
+		branch := &BranchNode{}
+		branch.put(n.Key[p:], n.Value)
+		branch.put(path[p:], value)
+		return extend(path[:p], branch), nil
+
+	case *ExtensionNode:
+		p := commonPrefix(path, n.Key)
+		if p == len(n.Key) {
+			child, err := m.insert(n.Child, path[p:], value)
+			if err != nil {
+				return nil, err
+			}
+			return &ExtensionNode{Key: n.Key, Child: child}, nil
+		}
+		// Tách extension tại nibble đầu tiên khác nhau
+		branch := &BranchNode{}
+		branch.Children[n.Key[p]] = extend(n.Key[p+1:], n.Child)
+		branch.put(path[p:], value)
+		return extend(path[:p], branch), nil
+
+	case *BranchNode:
+		branch := n.copy()
+		if len(path) == 0 {
+			branch.Value = value
+		} else {
+			child, err := m.insert(n.Children[path[0]], path[1:], value)
+			if err != nil {
+				return nil, err
+			}
+			branch.Children[path[0]] = child
+		}
+		return branch, nil
+	}
+	panic("mpt: unknown node type")
 }
 
-func (b *BranchNode) Hash() []byte {
-	data := []byte{0x01}
-	for _, n := range b.Branches {
-		if n != nil {
-			data = append(data, n.Hash()...)
+// remove returns the node that replaces n once path is deleted, and whether
+// path was there. A branch left with a single entry is collapsed into its
+// parent's path, so the trie has the same shape as one built without the key.
+func (m *MPT) remove(n Node, path []byte) (Node, bool, error) {
+	resolved, err := m.resolve(n)
+	if err != nil {
+		return nil, false, err
+	}
+	switch r := resolved.(type) {
+	case nil:
+		return nil, false, nil
+
+	case *LeafNode:
+		if !Equal(path, r.Key) {
+			return n, false, nil
+		}
+		return nil, true, nil
+
+	case *ExtensionNode:
+		if !hasPrefix(path, r.Key) {
+			return n, false, nil
+		}
+		child, ok, err := m.remove(r.Child, path[len(r.Key):])
+		if err != nil || !ok {
+			return n, false, err
+		}
+		joined, err := m.join(r.Key, child)
+		return joined, true, err
+
+	case *BranchNode:
+		branch := r.copy()
+		if len(path) == 0 {
+			if r.Value == nil {
+				return n, false, nil
+			}
+			branch.Value = nil
 		} else {
-			data = append(data, make([]byte, 32)...) // empty hash
+			child, ok, err := m.remove(r.Children[path[0]], path[1:])
+			if err != nil || !ok {
+				return n, false, err
+			}
+			branch.Children[path[0]] = child
+		}
+		collapsed, err := m.collapse(branch)
+		return collapsed, true, err
+	}
+	panic("mpt: unknown node type")
+}
+
+// collapse returns the node for a branch that may have lost entries: nothing
+// when it is empty, a leaf when only its value is left, and its only child
+// moved up one nibble when only one child is left.
+func (m *MPT) collapse(b *BranchNode) (Node, error) {
+	only, count := -1, 0
+	for i, child := range b.Children {
+		if child != nil {
+			only, count = i, count+1
 		}
 	}
-	if b.Value != nil {
-		data = append(data, b.Value...)
+	switch {
+	case count == 0 && b.Value == nil:
+		return nil, nil
+	case count == 0:
+		return &LeafNode{Key: []byte{}, Value: b.Value}, nil
+	case count == 1 && b.Value == nil:
+		return m.join([]byte{byte(only)}, b.Children[only])
 	}
-	h := sha256.Sum256(data)
-	return h[:]
+	return b, nil
 }
 
-func (b *BranchNode) Insert(path []byte, value []byte) Node {
-	if len(path) == 0 {
-		b.Value = value
-		return b
+// join puts child behind the nibbles of prefix, merging the prefix into the
+// key of a leaf or an extension.
+func (m *MPT) join(prefix []byte, child Node) (Node, error) {
+	child, err := m.resolve(child)
+	if err != nil {
+		return nil, err
+	}
+	switch child := child.(type) {
+	case nil:
+		return nil, nil
+	case *LeafNode:
+		return &LeafNode{Key: concat(prefix, child.Key), Value: child.Value}, nil
+	case *ExtensionNode:
+		return &ExtensionNode{Key: concat(prefix, child.Key), Child: child.Child}, nil
+	}
+	return extend(prefix, child), nil
+}
+
+// change is one entry of a batch: a nil value deletes the path.
+type change struct {
+	path  []byte
+	value []byte
+}
+
+// update applies changes, sorted by path and relative to n, and returns the
+// node that replaces n. Changes under the same node are applied together, so
+// each node on the touched paths is copied once for the whole batch.
+func (m *MPT) update(n Node, changes []change) (Node, error) {
+	switch len(changes) {
+	case 0:
+		return n, nil
+	case 1:
+		if changes[0].value == nil {
+			n, _, err := m.remove(n, changes[0].path)
+			return n, err
+		}
+		return m.insert(n, changes[0].path, changes[0].value)
 	}
-	index := path[0]
-	child := b.Branches[index]
-	if child == nil {
-		b.Branches[index] = &LeafNode{
-			Key:   path[1:], // Rest of the path
-			Value: value,
+
+	n, err := m.resolve(n)
+	if err != nil {
+		return nil, err
+	}
+	branch := expand(n)
+	for len(changes) > 0 && len(changes[0].path) == 0 {
+		branch.Value = changes[0].value
+		changes = changes[1:]
+	}
+	for len(changes) > 0 {
+		nibble := changes[0].path[0]
+		end := 1
+		for end < len(changes) && changes[end].path[0] == nibble {
+			end++
+		}
+		group := make([]change, end)
+		for i, c := range changes[:end] {
+			group[i] = change{path: c.path[1:], value: c.value}
+		}
+		child, err := m.update(branch.Children[nibble], group)
+		if err != nil {
+			return nil, err
 		}
-	} else {
-		b.Branches[index] = child.Insert(path[1:], value)
+		branch.Children[nibble] = child
+		changes = changes[end:]
 	}
-	return b
+	return m.collapse(branch)
 }
 
-func (b *BranchNode) Get(path []byte) ([]byte, bool) {
+// expand returns n as a new branch with the same contents, which collapse
+// turns back into n if nothing is changed. n must be resolved.
+func expand(n Node) *BranchNode {
+	switch n := n.(type) {
+	case nil:
+		return &BranchNode{}
+	case *LeafNode:
+		branch := &BranchNode{}
+		branch.put(n.Key, n.Value)
+		return branch
+	case *ExtensionNode:
+		branch := &BranchNode{}
+		branch.Children[n.Key[0]] = extend(n.Key[1:], n.Child)
+		return branch
+	case *BranchNode:
+		return n.copy()
+	}
+	panic("mpt: unknown node type")
+}
+
+// put stores value under path in a branch that is being built.
+func (b *BranchNode) put(path, value []byte) {
 	if len(path) == 0 {
-		if b.Value != nil {
-			return b.Value, true
+		b.Value = value
+		return
+	}
+	b.Children[path[0]] = &LeafNode{Key: path[1:], Value: value}
+}
+
+// extend puts child behind an extension of key, or returns it as is when the
+// key is empty.
+func extend(key []byte, child Node) Node {
+	if len(key) == 0 {
+		return child
+	}
+	return &ExtensionNode{Key: key, Child: child}
+}
+
+// get returns the value stored under path, nil if there is none.
+func (m *MPT) get(n Node, path []byte) ([]byte, error) {
+	for {
+		resolved, err := m.resolve(n)
+		if err != nil {
+			return nil, err
+		}
+		switch node := resolved.(type) {
+		case nil:
+			return nil, nil
+		case *LeafNode:
+			if Equal(path, node.Key) {
+				return node.Value, nil
+			}
+			return nil, nil
+		case *ExtensionNode:
+			if !hasPrefix(path, node.Key) {
+				return nil, nil
+			}
+			path = path[len(node.Key):]
+			n = node.Child
+		case *BranchNode:
+			if len(path) == 0 {
+				return node.Value, nil
+			}
+			n = node.Children[path[0]]
+			path = path[1:]
 		}
-		return nil, false
 	}
-	index := path[0]
-	child := b.Branches[index]
-	if child != nil {
-		return child.Get(path[1:])
+}
+
+// decodeNode is the inverse of encode. Children referenced by hash become
+// hashNodes; embedded children are decoded with their parent.
+func decodeNode(enc []byte) (Node, error) {
+	item, err := rlpDecode(enc)
+	if err != nil {
+		return nil, err
 	}
-	return nil, false
+	return nodeFromItem(item)
 }
 
-func (b *BranchNode) GenerateProof(path []byte) [][]byte {
-	var proof [][]byte
-	proof = append(proof, b.Hash())
-	if len(path) == 0 {
-		return proof
+func nodeFromItem(item rlpItem) (Node, error) {
+	if !item.isList {
+		switch len(item.str) {
+		case 0:
+			return nil, nil
+		case 32:
+			return hashNode(append([]byte{}, item.str...)), nil
+		}
+		return nil, errBadRLP
 	}
-	index := path[0]
-	child := b.Branches[index]
-	if child != nil {
-		childProof := child.GenerateProof(path[1:])
-		if childProof != nil {
-			proof = append(proof, childProof...)
+	switch len(item.list) {
+	case 2:
+		nibbles, leaf, err := decodeHexPrefix(item.list[0].str)
+		if err != nil {
+			return nil, err
+		}
+		if leaf {
+			return &LeafNode{Key: nibbles, Value: append([]byte{}, item.list[1].str...)}, nil
+		}
+		child, err := nodeFromItem(item.list[1])
+		if err != nil {
+			return nil, err
+		}
+		return &ExtensionNode{Key: nibbles, Child: child}, nil
+	case 17:
+		branch := &BranchNode{}
+		for i := 0; i < 16; i++ {
+			child, err := nodeFromItem(item.list[i])
+			if err != nil {
+				return nil, err
+			}
+			branch.Children[i] = child
+		}
+		if value := item.list[16].str; len(value) > 0 {
+			branch.Value = append([]byte{}, value...)
 		}
+		return branch, nil
 	}
-	return proof
+	return nil, errBadRLP
 }
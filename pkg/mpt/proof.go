@@ -0,0 +1,133 @@
+package mpt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// Proof is the list of node encodings on the path of a key, root first, as
+// returned by GenerateProof. Nodes embedded in their parent are not listed
+// separately. The same proof shows either the value of the key or, when the
+// path ends early, that the key is not in the trie.
+type Proof [][]byte
+
+// ErrInvalidProof is returned when a proof does not lead from the root to an
+// answer: a node is missing, does not decode, or does not hash to the
+// reference its parent holds.
+var ErrInvalidProof = errors.New("invalid proof")
+
+// maxProofNodes bounds a decoded proof; a path has at most 65 nodes.
+const maxProofNodes = 128
+
+// Encode serializes the proof as an RLP list of the node encodings.
+func (p Proof) Encode() []byte {
+	items := make([][]byte, len(p))
+	for i, node := range p {
+		items[i] = rlpString(node)
+	}
+	return rlpList(items...)
+}
+
+// DecodeProof parses a proof produced by Proof.Encode.
+func DecodeProof(data []byte) (Proof, error) {
+	item, err := rlpDecode(data)
+	if err != nil || !item.isList {
+		return nil, fmt.Errorf("%w: not an RLP list", ErrInvalidProof)
+	}
+	if len(item.list) > maxProofNodes {
+		return nil, fmt.Errorf("%w: %d nodes", ErrInvalidProof, len(item.list))
+	}
+	proof := make(Proof, len(item.list))
+	for i, node := range item.list {
+		if node.isList {
+			return nil, fmt.Errorf("%w: node %d is not a byte string", ErrInvalidProof, i)
+		}
+		proof[i] = append([]byte{}, node.str...)
+	}
+	return proof, nil
+}
+
+// VerifyProof checks if a key-value pair is included in the MPT with the given root hash.
+func VerifyProof(rootHash, key, expectedValue []byte, proof Proof) bool {
+	value, err := ProofValue(rootHash, key, proof)
+	return err == nil && value != nil && bytes.Equal(value, expectedValue)
+}
+
+// VerifyAbsence checks that the proof shows key is not in the trie with the
+// given root hash.
+func VerifyAbsence(rootHash, key []byte, proof Proof) bool {
+	value, err := ProofValue(rootHash, key, proof)
+	return err == nil && value == nil
+}
+
+// ProofValue walks the proof from the root along key and returns the value
+// it leads to, or nil when the proof shows the key is absent.
+func ProofValue(rootHash, key []byte, proof Proof) ([]byte, error) {
+	nodes := make(map[string][]byte, len(proof))
+	for _, enc := range proof {
+		nodes[string(Keccak256(enc))] = enc
+	}
+	enc, ok := nodes[string(rootHash)]
+	if !ok {
+		return nil, fmt.Errorf("%w: root node is missing", ErrInvalidProof)
+	}
+
+	path := BytesToNibbles(key)
+	for {
+		item, err := rlpDecode(enc)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+		}
+		if !item.isList {
+			if len(item.str) == 0 {
+				return nil, nil // trie rỗng
+			}
+			return nil, fmt.Errorf("%w: node is not a list", ErrInvalidProof)
+		}
+
+		var child rlpItem
+		switch len(item.list) {
+		case 2:
+			nibbles, leaf, err := decodeHexPrefix(item.list[0].str)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+			}
+			if leaf {
+				if Equal(path, nibbles) {
+					return item.list[1].str, nil
+				}
+				return nil, nil
+			}
+			if !hasPrefix(path, nibbles) {
+				return nil, nil
+			}
+			path = path[len(nibbles):]
+			child = item.list[1]
+		case 17:
+			if len(path) == 0 {
+				if len(item.list[16].str) == 0 {
+					return nil, nil
+				}
+				return item.list[16].str, nil
+			}
+			child = item.list[path[0]]
+			path = path[1:]
+		default:
+			return nil, fmt.Errorf("%w: node has %d items", ErrInvalidProof, len(item.list))
+		}
+
+		switch {
+		case child.isList:
+			enc = child.raw // node nhỏ được nhúng trong node cha
+		case len(child.str) == 0:
+			return nil, nil
+		case len(child.str) == 32:
+			if enc, ok = nodes[string(child.str)]; !ok {
+				return nil, fmt.Errorf("%w: node %x is missing", ErrInvalidProof, child.str)
+			}
+		default:
+			return nil, fmt.Errorf("%w: bad child reference", ErrInvalidProof)
+		}
+	}
+}
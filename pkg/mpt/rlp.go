@@ -0,0 +1,166 @@
+package mpt
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// Minimal RLP (Recursive Length Prefix) encoding: the trie nodes are RLP
+// lists of byte strings and of embedded nodes.
+
+var errBadRLP = errors.New("invalid RLP")
+
+// rlpEmpty is the encoding of the empty string.
+var rlpEmpty = []byte{0x80}
+
+func rlpString(b []byte) []byte {
+	if len(b) == 1 && b[0] < 0x80 {
+		return []byte{b[0]}
+	}
+	return append(rlpHeader(0x80, len(b)), b...)
+}
+
+// rlpList wraps items that are already RLP-encoded.
+func rlpList(items ...[]byte) []byte {
+	size := 0
+	for _, item := range items {
+		size += len(item)
+	}
+	out := rlpHeader(0xc0, size)
+	for _, item := range items {
+		out = append(out, item...)
+	}
+	return out
+}
+
+func rlpHeader(offset byte, size int) []byte {
+	if size < 56 {
+		return []byte{offset + byte(size)}
+	}
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(size))
+	i := 0
+	for buf[i] == 0 {
+		i++
+	}
+	return append([]byte{offset + 55 + byte(8-i)}, buf[i:]...)
+}
+
+// rlpItem is a decoded RLP value: a byte string, or a list whose elements
+// keep their raw encoding (an embedded trie node is used as-is).
+type rlpItem struct {
+	isList bool
+	str    []byte
+	list   []rlpItem
+	raw    []byte
+}
+
+func rlpDecode(data []byte) (rlpItem, error) {
+	item, rest, err := rlpSplit(data)
+	if err != nil {
+		return rlpItem{}, err
+	}
+	if len(rest) != 0 {
+		return rlpItem{}, errBadRLP
+	}
+	return item, nil
+}
+
+func rlpSplit(data []byte) (rlpItem, []byte, error) {
+	if len(data) == 0 {
+		return rlpItem{}, nil, errBadRLP
+	}
+	prefix := data[0]
+	var isList bool
+	var offset, size int
+	switch {
+	case prefix < 0x80:
+		return rlpItem{str: data[:1], raw: data[:1]}, data[1:], nil
+	case prefix < 0xb8:
+		offset, size = 1, int(prefix-0x80)
+	case prefix < 0xc0:
+		n := int(prefix - 0xb7)
+		offset, size = 1+n, readSize(data[1:], n)
+	case prefix < 0xf8:
+		isList, offset, size = true, 1, int(prefix-0xc0)
+	default:
+		n := int(prefix - 0xf7)
+		isList, offset, size = true, 1+n, readSize(data[1:], n)
+	}
+	// So sánh size với phần còn lại, để offset+size không thể tràn số
+	if size < 0 || offset > len(data) || size > len(data)-offset {
+		return rlpItem{}, nil, errBadRLP
+	}
+	item := rlpItem{isList: isList, raw: data[:offset+size]}
+	content := data[offset : offset+size]
+	if !isList {
+		item.str = content
+		return item, data[offset+size:], nil
+	}
+	for len(content) > 0 {
+		child, rest, err := rlpSplit(content)
+		if err != nil {
+			return rlpItem{}, nil, err
+		}
+		item.list = append(item.list, child)
+		content = rest
+	}
+	return item, data[offset+size:], nil
+}
+
+// readSize reads the n byte big-endian length of a long item; it returns -1
+// when the length is missing or does not fit in an int.
+func readSize(data []byte, n int) int {
+	if n > 8 || len(data) < n {
+		return -1
+	}
+	var size uint64
+	for _, b := range data[:n] {
+		size = size<<8 | uint64(b)
+	}
+	if size > math.MaxInt {
+		return -1
+	}
+	return int(size)
+}
+
+// hexPrefix encodes a nibble path with the leaf flag and the parity of its
+// length in the first nibble (Yellow Paper, appendix C).
+func hexPrefix(nibbles []byte, leaf bool) []byte {
+	flag := byte(0)
+	if leaf {
+		flag = 2
+	}
+	var out []byte
+	if len(nibbles)%2 == 1 {
+		out = append(out, (flag+1)<<4|nibbles[0])
+		nibbles = nibbles[1:]
+	} else {
+		out = append(out, flag<<4)
+	}
+	for i := 0; i < len(nibbles); i += 2 {
+		out = append(out, nibbles[i]<<4|nibbles[i+1])
+	}
+	return out
+}
+
+// decodeHexPrefix is the inverse of hexPrefix.
+func decodeHexPrefix(b []byte) (nibbles []byte, leaf bool, err error) {
+	if len(b) == 0 {
+		return nil, false, errBadRLP
+	}
+	flag := b[0] >> 4
+	if flag > 3 {
+		return nil, false, errBadRLP
+	}
+	leaf = flag >= 2
+	all := BytesToNibbles(b)
+	if flag%2 == 1 {
+		return all[1:], leaf, nil
+	}
+	if all[1] != 0 {
+		return nil, false, errBadRLP
+	}
+	return all[2:], leaf, nil
+}
@@ -1,10 +1,5 @@
 package mpt
 
-import (
-	"bytes"
-	"crypto/sha256"
-)
-
 func BytesToNibbles(b []byte) []byte {
 	nibbles := make([]byte, len(b)*2)
 	for i, v := range b {
@@ -26,45 +21,20 @@ func Equal(a, b []byte) bool {
 	return true
 }
 
-// VerifyProof checks if a key-value pair is included in the MPT with the given root hash.
-func VerifyProof(rootHash, key, expectedValue []byte, proof [][]byte) bool {
-	if len(proof) == 0 {
-		return false
-	}
-
-	// Step 1: Recompute the hashes from leaf to root
-	nibbles := BytesToNibbles(key)
-	currentHash := computeLeafHash(nibbles, expectedValue)
-
-	// Walk backwards through the proof, recomputing hashes
-	for i := len(proof) - 2; i >= 0; i-- {
-		parentHash := computeBranchHashWithChild(currentHash, nibbles, i)
-		currentHash = parentHash
-	}
-
-	// Step 2: Final hash must match root hash
-	return bytes.Equal(currentHash, rootHash)
+func hasPrefix(path, prefix []byte) bool {
+	return len(path) >= len(prefix) && Equal(path[:len(prefix)], prefix)
 }
 
-// computeLeafHash hashes a fake leaf node with the provided key and value.
-func computeLeafHash(nibbles, value []byte) []byte {
-	data := append(append([]byte{0x00}, nibbles...), value...)
-	h := sha256.Sum256(data)
-	return h[:]
+func commonPrefix(a, b []byte) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
 }
 
-// computeBranchHashWithChild fakes a branch node with the given child hash at the correct index.
-func computeBranchHashWithChild(childHash, keyNibbles []byte, depth int) []byte {
-	index := keyNibbles[depth]
-	data := []byte{0x01}
-	for i := 0; i < 16; i++ {
-		if i == int(index) {
-			data = append(data, childHash...)
-		} else {
-			data = append(data, make([]byte, 32)...)
-		}
-	}
-	// No value at branch node
-	h := sha256.Sum256(data)
-	return h[:]
+// concat returns a new slice holding a followed by b.
+func concat(a, b []byte) []byte {
+	out := make([]byte, 0, len(a)+len(b))
+	return append(append(out, a...), b...)
 }
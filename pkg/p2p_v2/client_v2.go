@@ -107,7 +107,9 @@ func BroadcastCommittedBlock(block *blockchain.Block, peerAddrs []string) {
 	}
 }
 
-func SyncBlockFromLeader(startHeight int, leaderAddr string, db *storage.DB) error {
+// SyncBlockFromLeader stores every block the leader has above the local tip,
+// without touching state. The node itself uses a Syncer with a commit callback.
+func SyncBlockFromLeader(leaderAddr string, db *storage.DB) error {
 	conn, err := grpc.Dial(leaderAddr, grpc.WithInsecure())
 	if err != nil {
 		return fmt.Errorf("failed to connect to leader: %v", err)
@@ -116,20 +118,12 @@ func SyncBlockFromLeader(startHeight int, leaderAddr string, db *storage.DB) err
 
 	client := nodepb.NewNodeServiceClient(conn)
 
-	res, err := client.GetBlockFromHeight(context.Background(), &nodepb.HeightRequest{FromHeight: int64(startHeight)})
+	syncer := NewSyncer(db, db.SaveBlock, DefaultSyncOptions())
+	synced, err := syncer.SyncFrom(context.Background(), leaderAddr, client)
 	if err != nil {
 		return fmt.Errorf("sync failed: %v", err)
 	}
 
-	for _, pb := range res.Blocks {
-		block := blockchain.ProtoToBlock(pb)
-		err := db.SaveBlock(block)
-		if err != nil {
-			return fmt.Errorf("failed to save synced block at height %d: %v", block.Height, err)
-		}
-		log.Printf("📥 Synced block at height %d", block.Height)
-	}
-
-	log.Printf("✅ Sync complete. Total blocks synced: %d", len(res.Blocks))
+	log.Printf("✅ Sync complete. Total blocks synced: %d", synced)
 	return nil
 }
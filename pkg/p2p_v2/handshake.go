@@ -0,0 +1,55 @@
+package p2p_v2
+
+import (
+	"blockchain-go/proto/nodepb"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrWrongChain is returned by Handshake when the peer runs another chain.
+var ErrWrongChain = errors.New("peer runs a different chain")
+
+// Handshake là RPC handler: peer gửi chain ID và genesis hash của mình, node
+// từ chối nếu không khớp. Trường để trống không được kiểm tra, nên client chỉ
+// cần gọi với request rỗng để biết chain ID của node.
+func (s *NodeServer) Handshake(ctx context.Context, req *nodepb.HandshakeRequest) (*nodepb.HandshakeResponse, error) {
+	if err := checkChain(s.ChainID, s.GenesisHash, req.ChainId, req.GenesisHash); err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%s: %v", req.NodeId, err)
+	}
+	res := &nodepb.HandshakeResponse{NodeId: s.NodeID, ChainId: s.ChainID, GenesisHash: s.GenesisHash}
+	if latest := s.Consensus.LatestBlock; latest != nil {
+		res.Height = latest.Height
+	}
+	return res, nil
+}
+
+// Handshake checks that the peer behind client runs the chain described by
+// req, and returns what the peer reported about itself.
+func Handshake(ctx context.Context, client nodepb.NodeServiceClient, req *nodepb.HandshakeRequest) (*nodepb.HandshakeResponse, error) {
+	res, err := client.Handshake(ctx, req)
+	if status.Code(err) == codes.FailedPrecondition {
+		return nil, fmt.Errorf("%w: %s", ErrWrongChain, status.Convert(err).Message())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("handshake failed: %w", err)
+	}
+	if err := checkChain(req.ChainId, req.GenesisHash, res.ChainId, res.GenesisHash); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWrongChain, err)
+	}
+	return res, nil
+}
+
+func checkChain(chainID string, genesisHash []byte, otherChainID string, otherGenesisHash []byte) error {
+	if otherChainID != "" && otherChainID != chainID {
+		return fmt.Errorf("chain ID %q does not match %q", otherChainID, chainID)
+	}
+	if len(otherGenesisHash) > 0 && !bytes.Equal(otherGenesisHash, genesisHash) {
+		return fmt.Errorf("genesis hash %x does not match %x", otherGenesisHash, genesisHash)
+	}
+	return nil
+}
@@ -32,8 +32,8 @@ func (a *GrpcAdapter) BroadcastProposedBlock(block *blockchain.Block) {
 
 	for _, addr := range a.peerAddrs {
 		peerAddr := addr // Tạo biến cục bộ cho goroutine
-		go a.sendToPeer(peerAddr, func(client nodepb.NodeServiceClient) error {
-			res, err := client.ProposeBlock(context.Background(), pb)
+		go a.sendToPeer(peerAddr, func(ctx context.Context, client nodepb.NodeServiceClient) error {
+			res, err := client.ProposeBlock(ctx, pb)
 			if err != nil {
 				return err
 			}
@@ -49,8 +49,8 @@ func (a *GrpcAdapter) BroadcastProposedBlock(block *blockchain.Block) {
 // Send vote for leader
 func (a *GrpcAdapter) SendVoteToLeader(vote *nodepb.Vote) error {
 	var err error
-	a.sendToPeer(a.leaderAddr, func(client nodepb.NodeServiceClient) error {
-		_, err = client.VoteBlock(context.Background(), vote)
+	a.sendToPeer(a.leaderAddr, func(ctx context.Context, client nodepb.NodeServiceClient) error {
+		_, err = client.VoteBlock(ctx, vote)
 		if err == nil {
 			log.Printf("✅ Submitted vote for block %x to leader.", vote.BlockHash)
 		}
@@ -66,8 +66,8 @@ func (a *GrpcAdapter) BroadcastCommittedBlock(block *blockchain.Block) {
 
 	for _, addr := range a.peerAddrs {
 		peerAddr := addr // Tạo biến cục bộ
-		go a.sendToPeer(peerAddr, func(client nodepb.NodeServiceClient) error {
-			_, err := client.CommitBlock(context.Background(), pb)
+		go a.sendToPeer(peerAddr, func(ctx context.Context, client nodepb.NodeServiceClient) error {
+			_, err := client.CommitBlock(ctx, pb)
 			if err == nil {
 				log.Printf("✅ Commit notification to %s success.", peerAddr)
 			}
@@ -76,21 +76,66 @@ func (a *GrpcAdapter) BroadcastCommittedBlock(block *blockchain.Block) {
 	}
 }
 
-func (a *GrpcAdapter) sendToPeer(peerAddr string, rpcCall func(client nodepb.NodeServiceClient) error) {
+// FetchBlocks asks the leader first, then every other peer, for committed
+// blocks starting at fromHeight and returns the first non-empty page.
+func (a *GrpcAdapter) FetchBlocks(fromHeight int64, maxBlocks int64) ([]*blockchain.Block, error) {
+	var lastErr error
+	for _, peerAddr := range a.syncPeers() {
+		var blocks []*blockchain.Block
+		err := a.callPeer(peerAddr, func(ctx context.Context, client nodepb.NodeServiceClient) error {
+			res, err := client.GetBlockRange(ctx, &nodepb.BlockRangeRequest{FromHeight: fromHeight, MaxBlocks: maxBlocks})
+			if err != nil {
+				return err
+			}
+			for _, pb := range res.Blocks {
+				blocks = append(blocks, blockchain.ProtoToBlock(pb))
+			}
+			return nil
+		})
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if len(blocks) > 0 {
+			log.Printf("📥 Fetched %d blocks from %s starting at height %d", len(blocks), peerAddr, fromHeight)
+			return blocks, nil
+		}
+	}
+	return nil, lastErr
+}
+
+// syncPeers lists the leader followed by the other peers, without duplicates.
+func (a *GrpcAdapter) syncPeers() []string {
+	peers := []string{a.leaderAddr}
+	for _, addr := range a.peerAddrs {
+		if addr != a.leaderAddr {
+			peers = append(peers, addr)
+		}
+	}
+	return peers
+}
+
+func (a *GrpcAdapter) sendToPeer(peerAddr string, rpcCall func(ctx context.Context, client nodepb.NodeServiceClient) error) {
+	if err := a.callPeer(peerAddr, rpcCall); err != nil {
+		log.Printf("❌ RPC error to peer %s: %v", peerAddr, err)
+	}
+}
+
+// callPeer dials peerAddr and runs rpcCall, returning any error to the caller.
+// Dialing and the call share one 5 second deadline through ctx, so a peer
+// that hangs can not stall the caller.
+func (a *GrpcAdapter) callPeer(peerAddr string, rpcCall func(ctx context.Context, client nodepb.NodeServiceClient) error) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	conn, err := grpc.DialContext(ctx, peerAddr, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
 
 	if err != nil {
-		log.Printf("❌ can not connect to peer %s: %v", peerAddr, err)
-		return
+		return fmt.Errorf("can not connect to peer %s: %w", peerAddr, err)
 	}
 
 	defer conn.Close()
 
 	client := nodepb.NewNodeServiceClient(conn)
-	if err := rpcCall(client); err != nil {
-		log.Printf("❌ RPC error to peer %s: %v", peerAddr, err)
-	}
+	return rpcCall(ctx, client)
 }
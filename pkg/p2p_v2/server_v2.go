@@ -4,17 +4,24 @@ package p2p_v2
 import (
 	"blockchain-go/pkg/blockchain"
 	"blockchain-go/pkg/consensus"
-	"blockchain-go/pkg/cryptohelper"
+	"blockchain-go/pkg/genesis"
+	"blockchain-go/pkg/snapshot"
 	"blockchain-go/pkg/state"
+	"blockchain-go/pkg/storage"
 	"blockchain-go/proto/nodepb"
+	"bytes"
 	"context"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 type NodeServer struct {
@@ -23,8 +30,19 @@ type NodeServer struct {
 	NodeID   string
 	IsLeader bool
 
-	// Fields solve transaction
-	PendingTxs  []*blockchain.Transaction
+	// Chain the node runs, from genesis.json. Transactions and peers of
+	// another chain are rejected.
+	ChainID     string
+	GenesisHash []byte
+	// MaxBlockTxs and BlockInterval come from the genesis params; zero
+	// values fall back to 10 transactions and 5 seconds.
+	MaxBlockTxs   int
+	BlockInterval time.Duration
+
+	// Fields solve transaction. pendingTxs is only filled by
+	// SendTransaction after the transaction is authenticated, so blocks
+	// built from it never carry a transaction with a spoofed sender.
+	pendingTxs  []*blockchain.Transaction
 	txMutex     sync.Mutex
 	isCreating  bool
 	createMutex sync.Mutex
@@ -32,19 +50,19 @@ type NodeServer struct {
 	// modules handle logic
 	Consensus *consensus.Manager
 	State     *state.State
+	Snapshots *snapshot.Store
 }
 
 // SendTransaction nhận một giao dịch mới
 func (s *NodeServer) SendTransaction(ctx context.Context, txProto *nodepb.Transaction) (*nodepb.Status, error) {
-	txInternal := blockchain.ProtoToBlock(&nodepb.Block{Transactions: []*nodepb.Transaction{txProto}}).Transactions[0]
-
-	// Xác thực chữ ký cơ bản
-	pubKey, err := cryptohelper.BytesToPublicKey(txInternal.PublicKey)
-	if err != nil {
-		return &nodepb.Status{Message: "Public key Invalid", Success: false}, nil
+	txInternal := blockchain.ProtoToTransaction(txProto)
+	if txInternal.ChainID != s.ChainID {
+		return &nodepb.Status{Message: fmt.Sprintf("transaction is signed for chain %q, this node runs %q", txInternal.ChainID, s.ChainID), Success: false}, nil
 	}
-	if !blockchain.VerifyTransaction(txInternal, pubKey) {
-		return &nodepb.Status{Message: "Chữ ký không hợp lệ", Success: false}, nil
+
+	// Xác thực chữ ký và địa chỉ người gửi
+	if err := blockchain.AuthenticateTransaction(txInternal); err != nil {
+		return &nodepb.Status{Message: err.Error(), Success: false}, nil
 	}
 
 	// Nếu là Leader, kiểm tra số dư ngay lập tức
@@ -67,8 +85,8 @@ func (s *NodeServer) SendTransaction(ctx context.Context, txProto *nodepb.Transa
 
 func (s *NodeServer) addTxToPending(tx *blockchain.Transaction) {
 	s.txMutex.Lock()
-	s.PendingTxs = append(s.PendingTxs, tx)
-	txCount := len(s.PendingTxs)
+	s.pendingTxs = append(s.pendingTxs, tx)
+	txCount := len(s.pendingTxs)
 	s.txMutex.Unlock()
 
 	if !s.IsLeader {
@@ -80,30 +98,55 @@ func (s *NodeServer) addTxToPending(tx *blockchain.Transaction) {
 		s.createMutex.Unlock()
 		return
 	}
-	// Nếu đủ 10 tx hoặc đã đến lúc, tạo block
-	if txCount >= 10 {
+	// Nếu đủ số tx của một block hoặc đã đến lúc, tạo block
+	if txCount >= s.maxBlockTxs() {
 		s.isCreating = true
 		s.createMutex.Unlock()
 		go s.triggerCreateBlock()
 	} else {
 		s.createMutex.Unlock()
-		// Tạo một timer, nếu sau 5s chưa có block mới thì sẽ tạo
-		time.AfterFunc(5*time.Second, func() {
-			s.createMutex.Lock()
-			if !s.isCreating {
-				s.isCreating = true
-				s.createMutex.Unlock()
-				go s.triggerCreateBlock()
-			} else {
-				s.createMutex.Unlock()
-			}
-		})
+		// Tạo một timer, nếu sau BlockInterval chưa có block mới thì sẽ tạo
+		s.scheduleCreateBlock(s.blockInterval())
+	}
+}
+
+// PendingCount returns the number of transactions waiting for a block.
+func (s *NodeServer) PendingCount() int {
+	s.txMutex.Lock()
+	defer s.txMutex.Unlock()
+	return len(s.pendingTxs)
+}
+
+func (s *NodeServer) maxBlockTxs() int {
+	if s.MaxBlockTxs > 0 {
+		return s.MaxBlockTxs
+	}
+	return 10
+}
+
+func (s *NodeServer) blockInterval() time.Duration {
+	if s.BlockInterval > 0 {
+		return s.BlockInterval
 	}
+	return 5 * time.Second
+}
+
+func (s *NodeServer) scheduleCreateBlock(delay time.Duration) {
+	time.AfterFunc(delay, func() {
+		s.createMutex.Lock()
+		if !s.isCreating {
+			s.isCreating = true
+			s.createMutex.Unlock()
+			go s.triggerCreateBlock()
+		} else {
+			s.createMutex.Unlock()
+		}
+	})
 }
 
 func (s *NodeServer) triggerCreateBlock() {
 	s.txMutex.Lock()
-	if len(s.PendingTxs) == 0 {
+	if len(s.pendingTxs) == 0 {
 		s.txMutex.Unlock()
 		s.createMutex.Lock()
 		s.isCreating = false
@@ -111,25 +154,39 @@ func (s *NodeServer) triggerCreateBlock() {
 		return
 	}
 
-	// Lấy tối đa 10 giao dịch
+	// Lấy tối đa MaxBlockTxs giao dịch
 	var txsToProcess []*blockchain.Transaction
-	if len(s.PendingTxs) > 10 {
-		txsToProcess = s.PendingTxs[:10]
-		s.PendingTxs = s.PendingTxs[10:]
+	if limit := s.maxBlockTxs(); len(s.pendingTxs) > limit {
+		txsToProcess = s.pendingTxs[:limit]
+		s.pendingTxs = s.pendingTxs[limit:]
 	} else {
-		txsToProcess = s.PendingTxs
-		s.PendingTxs = []*blockchain.Transaction{}
+		txsToProcess = s.pendingTxs
+		s.pendingTxs = []*blockchain.Transaction{}
 	}
 	s.txMutex.Unlock()
 
 	// Gọi Consensus Manager để xử lý
-	s.Consensus.CreateAndProposeBlock(txsToProcess)
+	err := s.Consensus.CreateAndProposeBlock(txsToProcess)
+	if err != nil {
+		// Trả các giao dịch về đầu hàng đợi để đưa vào block sau
+		s.txMutex.Lock()
+		s.pendingTxs = append(append([]*blockchain.Transaction{}, txsToProcess...), s.pendingTxs...)
+		s.txMutex.Unlock()
+		if errors.Is(err, consensus.ErrProposalPending) {
+			log.Println("⏳ Leader: the previous block is still waiting for votes, retrying later")
+		} else {
+			log.Printf("❌ Leader: can not propose block: %v", err)
+		}
+	}
 
 	// Reset cờ
 	time.Sleep(2 * time.Second) // Chờ một chút trước khi cho phép tạo block mới
 	s.createMutex.Lock()
 	s.isCreating = false
 	s.createMutex.Unlock()
+	if err != nil {
+		s.scheduleCreateBlock(s.blockInterval())
+	}
 }
 
 // ProposeBlock là RPC handler cho follower.
@@ -139,6 +196,10 @@ func (s *NodeServer) ProposeBlock(ctx context.Context, pb *nodepb.Block) (*nodep
 	}
 
 	block := blockchain.ProtoToBlock(pb)
+	if err := s.checkBlockChain(block); err != nil {
+		log.Printf("❌ Block was rejected: %v", err)
+		return &nodepb.Status{Message: err.Error(), Success: false}, nil
+	}
 	err := s.Consensus.HandleProposedBlock(block) // Ủy quyền cho Consensus Manager
 	if err != nil {
 		log.Printf("❌ Block was rejected: %v", err)
@@ -153,6 +214,9 @@ func (s *NodeServer) VoteBlock(ctx context.Context, vote *nodepb.Vote) (*nodepb.
 	if !s.IsLeader {
 		return &nodepb.Status{Message: "Only leader receive vote", Success: false}, nil
 	}
+	if vote.ChainId != s.ChainID {
+		return &nodepb.Status{Message: fmt.Sprintf("vote is for chain %q, this node runs %q", vote.ChainId, s.ChainID), Success: false}, nil
+	}
 
 	go s.Consensus.HandleVote(vote) // Xử lý bất đồng bộ
 
@@ -166,7 +230,11 @@ func (s *NodeServer) CommitBlock(ctx context.Context, pb *nodepb.Block) (*nodepb
 	}
 
 	block := blockchain.ProtoToBlock(pb)
-	if err := s.Consensus.CommitBlock(block); err != nil {
+	if err := s.checkBlockChain(block); err != nil {
+		log.Printf("❌ Follower commit block fail: %v", err)
+		return &nodepb.Status{Message: err.Error(), Success: false}, nil
+	}
+	if err := s.Consensus.HandleCommittedBlock(block); err != nil {
 		log.Printf("❌ Follower commit block fail: %v", err)
 		return &nodepb.Status{Message: err.Error(), Success: false}, nil
 	}
@@ -174,27 +242,219 @@ func (s *NodeServer) CommitBlock(ctx context.Context, pb *nodepb.Block) (*nodepb
 	return &nodepb.Status{Message: "Block has been committed", Success: true}, nil
 }
 
-// GetBlockFromHeight trả về danh sách các block từ một height nhất định.
+// checkBlockChain rejects a block of another chain. Only the genesis block
+// is compared with the genesis hash: later blocks must link to the local
+// chain through PreviousBlockHash, which validation checks.
+func (s *NodeServer) checkBlockChain(block *blockchain.Block) error {
+	if block.ChainID != s.ChainID {
+		return fmt.Errorf("block %d is for chain %q, this node runs %q", block.Height, block.ChainID, s.ChainID)
+	}
+	if block.Height == 0 && !bytes.Equal(block.CurrentBlockHash, s.GenesisHash) {
+		return fmt.Errorf("genesis block %x does not match %x", block.CurrentBlockHash, s.GenesisHash)
+	}
+	return nil
+}
+
+const (
+	// DefaultSyncBlocks is the page size used when a sync request does not set one.
+	DefaultSyncBlocks = 128
+	// MaxSyncBlocks is the largest page a peer may ask for.
+	MaxSyncBlocks = 1024
+	// MaxSyncBytes keeps a page well below gRPC's default 4MB message limit.
+	MaxSyncBytes = 2 * 1024 * 1024
+)
+
+// GetBlockFromHeight trả về một trang các block bắt đầu từ một height nhất định.
+// The page is capped like GetBlockRange; callers follow NextHeight/HasMore.
 func (s *NodeServer) GetBlockFromHeight(ctx context.Context, req *nodepb.HeightRequest) (*nodepb.BlockList, error) {
-	start := int(req.FromHeight)
-	var blocks []*nodepb.Block
+	return s.GetBlockRange(ctx, &nodepb.BlockRangeRequest{FromHeight: req.FromHeight})
+}
+
+// GetBlockRange returns full blocks from FromHeight, stopping at the block or
+// byte limit, whichever comes first. At least one block is always returned
+// when it exists so a single oversized block can still be synced.
+func (s *NodeServer) GetBlockRange(ctx context.Context, req *nodepb.BlockRangeRequest) (*nodepb.BlockList, error) {
+	maxBlocks, maxBytes, err := syncLimits(req)
+	if err != nil {
+		return nil, err
+	}
+	tip, err := s.tipHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	list := &nodepb.BlockList{NextHeight: req.FromHeight}
+	size := 0
+	for h := req.FromHeight; h <= tip && len(list.Blocks) < maxBlocks; h++ {
+		block, err := s.Consensus.DB.GetBlockByHeight(int(h))
+		if err != nil {
+			return nil, blockLookupError(err, "height %d", h)
+		}
+		pb := blockchain.BlockToProto(block)
+		n := proto.Size(pb)
+		if len(list.Blocks) > 0 && size+n > maxBytes {
+			break
+		}
+		list.Blocks = append(list.Blocks, pb)
+		list.NextHeight = h + 1
+		size += n
+	}
+	list.HasMore = list.NextHeight <= tip
+
+	return list, nil
+}
+
+// GetHeaderRange is the header-only counterpart of GetBlockRange, used for
+// header-first sync.
+func (s *NodeServer) GetHeaderRange(ctx context.Context, req *nodepb.BlockRangeRequest) (*nodepb.HeaderList, error) {
+	maxBlocks, maxBytes, err := syncLimits(req)
+	if err != nil {
+		return nil, err
+	}
+	tip, err := s.tipHeight()
+	if err != nil {
+		return nil, err
+	}
 
-	for h := start; ; h++ {
-		block, err := s.Consensus.DB.GetBlockByHeight(h)
+	list := &nodepb.HeaderList{NextHeight: req.FromHeight}
+	size := 0
+	for h := req.FromHeight; h <= tip && len(list.Headers) < maxBlocks; h++ {
+		block, txCount, err := s.Consensus.DB.GetHeaderByHeight(int(h))
 		if err != nil {
-			break // Hết block
+			return nil, blockLookupError(err, "height %d", h)
+		}
+		header := headerProto(block, txCount)
+		n := proto.Size(header)
+		if len(list.Headers) > 0 && size+n > maxBytes {
+			break
+		}
+		list.Headers = append(list.Headers, header)
+		list.NextHeight = h + 1
+		size += n
+	}
+	list.HasMore = list.NextHeight <= tip
+
+	return list, nil
+}
+
+// syncLimits applies the server defaults and caps to a range request.
+func syncLimits(req *nodepb.BlockRangeRequest) (int, int, error) {
+	if req.FromHeight < 0 {
+		return 0, 0, status.Errorf(codes.InvalidArgument, "from_height must not be negative")
+	}
+	maxBlocks := int(req.MaxBlocks)
+	if maxBlocks <= 0 {
+		maxBlocks = DefaultSyncBlocks
+	}
+	if maxBlocks > MaxSyncBlocks {
+		maxBlocks = MaxSyncBlocks
+	}
+	maxBytes := int(req.MaxBytes)
+	if maxBytes <= 0 || maxBytes > MaxSyncBytes {
+		maxBytes = MaxSyncBytes
+	}
+	return maxBlocks, maxBytes, nil
+}
+
+// tipHeight returns the height of the latest local block, or -1 on an empty chain.
+func (s *NodeServer) tipHeight() (int64, error) {
+	latest, err := s.Consensus.DB.GetLatestBlock()
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return -1, nil
 		}
-		blocks = append(blocks, blockchain.BlockToProto(block))
+		return 0, status.Errorf(codes.Internal, "can not load latest block: %v", err)
+	}
+	return latest.Height, nil
+}
+
+// GetBlock returns the block at the requested height.
+func (s *NodeServer) GetBlock(ctx context.Context, req *nodepb.BlockRequest) (*nodepb.Block, error) {
+	block, err := s.Consensus.DB.GetBlockByHeight(int(req.Height))
+	if err != nil {
+		return nil, blockLookupError(err, "height %d", req.Height)
 	}
+	return blockchain.BlockToProto(block), nil
+}
+
+// GetBlockByHash returns the block with the requested hash.
+func (s *NodeServer) GetBlockByHash(ctx context.Context, req *nodepb.BlockHashRequest) (*nodepb.Block, error) {
+	block, err := s.Consensus.DB.GetBlock(req.Hash)
+	if err != nil {
+		return nil, blockLookupError(err, "hash %x", req.Hash)
+	}
+	return blockchain.BlockToProto(block), nil
+}
+
+// GetLatestBlock returns the tip of the local chain.
+func (s *NodeServer) GetLatestBlock(ctx context.Context, _ *nodepb.Empty) (*nodepb.Block, error) {
+	block, err := s.Consensus.DB.GetLatestBlock()
+	if err != nil {
+		return nil, blockLookupError(err, "latest")
+	}
+	return blockchain.BlockToProto(block), nil
+}
+
+// GetBlockHeader returns only the header of the block at the requested height.
+func (s *NodeServer) GetBlockHeader(ctx context.Context, req *nodepb.BlockRequest) (*nodepb.BlockHeader, error) {
+	block, txCount, err := s.Consensus.DB.GetHeaderByHeight(int(req.Height))
+	if err != nil {
+		return nil, blockLookupError(err, "height %d", req.Height)
+	}
+	return headerProto(block, txCount), nil
+}
+
+// GetBlockHeaderByHash returns only the header of the block with the requested hash.
+func (s *NodeServer) GetBlockHeaderByHash(ctx context.Context, req *nodepb.BlockHashRequest) (*nodepb.BlockHeader, error) {
+	block, txCount, err := s.Consensus.DB.GetHeader(req.Hash)
+	if err != nil {
+		return nil, blockLookupError(err, "hash %x", req.Hash)
+	}
+	return headerProto(block, txCount), nil
+}
+
+// GetLatestBlockHeader returns only the header of the tip of the local chain.
+func (s *NodeServer) GetLatestBlockHeader(ctx context.Context, _ *nodepb.Empty) (*nodepb.BlockHeader, error) {
+	block, err := s.Consensus.DB.GetLatestBlock()
+	if err != nil {
+		return nil, blockLookupError(err, "latest")
+	}
+	return blockchain.BlockToHeaderProto(block), nil
+}
 
-	return &nodepb.BlockList{Blocks: blocks}, nil
+// headerProto builds a header for a block that may have had its body pruned.
+func headerProto(block *blockchain.Block, txCount int) *nodepb.BlockHeader {
+	header := blockchain.BlockToHeaderProto(block)
+	header.TxCount = int32(txCount)
+	return header
+}
+
+// blockLookupError maps a storage error to a gRPC status so clients can tell
+// a missing or pruned block apart from a broken database.
+func blockLookupError(err error, format string, args ...interface{}) error {
+	if errors.Is(err, storage.ErrNotFound) {
+		return status.Errorf(codes.NotFound, "block not found ("+format+")", args...)
+	}
+	if errors.Is(err, storage.ErrPruned) {
+		return status.Errorf(codes.FailedPrecondition, "block pruned ("+format+")", args...)
+	}
+	return status.Errorf(codes.Internal, "can not load block: %v", err)
 }
 
 // GetBalance return balance from the address
 func (s *NodeServer) GetBalance(ctx context.Context, req *nodepb.GetBalanceRequest) (*nodepb.GetBalanceResponse, error) {
 	log.Printf("🔍 Received GetBalance request for address: %s", req.Address)
-	balance, err := s.State.GetBalance(req.Address)
+	var balance float64
+	var err error
+	if req.Height != nil {
+		balance, err = s.State.GetBalanceAt(req.Address, req.GetHeight())
+	} else {
+		balance, err = s.State.GetBalance(req.Address)
+	}
 	if err != nil {
+		if errors.Is(err, storage.ErrPruned) {
+			return nil, status.Errorf(codes.FailedPrecondition, "Can not get balance: %v", err)
+		}
 		return nil, status.Errorf(codes.Internal, "Can not get balance: %v", err)
 	}
 
@@ -203,3 +463,141 @@ func (s *NodeServer) GetBalance(ctx context.Context, req *nodepb.GetBalanceReque
 		Balance: balance,
 	}, nil
 }
+
+// GetAccountHistory returns one page of the transactions sent or received by
+// an address, oldest first.
+func (s *NodeServer) GetAccountHistory(ctx context.Context, req *nodepb.AccountHistoryRequest) (*nodepb.AccountHistory, error) {
+	address, err := normalizeAddress(req.Address)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	if req.FromHeight < 0 || req.Limit < 0 || (req.ToHeight != nil && req.GetToHeight() < req.FromHeight) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid height range or limit")
+	}
+	query := storage.HistoryQuery{
+		Address:    address,
+		FromHeight: req.FromHeight,
+		ToHeight:   -1,
+		Cursor:     req.Cursor,
+		Limit:      int(req.Limit),
+	}
+	if req.ToHeight != nil {
+		query.ToHeight = req.GetToHeight()
+	}
+	switch req.Direction {
+	case nodepb.TxDirection_SENT:
+		query.Direction = storage.Sent
+	case nodepb.TxDirection_RECEIVED:
+		query.Direction = storage.Received
+	}
+
+	page, err := s.Consensus.DB.AccountHistory(query)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "can not load account history: %v", err)
+	}
+	resp := &nodepb.AccountHistory{NextCursor: page.NextCursor, HasMore: len(page.NextCursor) > 0}
+	for _, entry := range page.Entries {
+		direction := nodepb.TxDirection_RECEIVED
+		if entry.Direction == storage.Sent {
+			direction = nodepb.TxDirection_SENT
+		}
+		accountTx := &nodepb.AccountTx{Height: entry.Height, Index: int32(entry.Index), Direction: direction}
+		// Giao dịch của block đã bị prune thì chỉ còn vị trí
+		if entry.Tx != nil {
+			accountTx.Transaction = blockchain.TransactionToProto(entry.Tx)
+			accountTx.TxHash = entry.Tx.Hash()
+		}
+		resp.Entries = append(resp.Entries, accountTx)
+	}
+	return resp, nil
+}
+
+// normalizeAddress turns a hex address, with or without 0x and in any case,
+// into the lowercase form used by the address index.
+func normalizeAddress(address string) (string, error) {
+	if address == "" {
+		return "", errors.New("address is required")
+	}
+	trimmed := strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(address, "0x"), "0X"))
+	raw, err := hex.DecodeString(trimmed)
+	if err != nil || len(raw) != genesis.AddressLength {
+		return "", fmt.Errorf("address %q is not %d bytes of hex", address, genesis.AddressLength)
+	}
+	return trimmed, nil
+}
+
+// GetTransactionProof returns a transaction of a block with the proof that
+// it is committed to by the block's Merkle root. The transaction is chosen by
+// hash, or by index when no hash is given.
+func (s *NodeServer) GetTransactionProof(ctx context.Context, req *nodepb.TransactionProofRequest) (*nodepb.TransactionProof, error) {
+	block, err := s.Consensus.DB.GetBlockByHeight(int(req.Height))
+	if err != nil {
+		return nil, blockLookupError(err, "height %d", req.Height)
+	}
+
+	index := int(req.Index)
+	if len(req.TxHash) > 0 {
+		index = -1
+		for i, tx := range block.Transactions {
+			if bytes.Equal(tx.Hash(), req.TxHash) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil, status.Errorf(codes.NotFound, "transaction %x is not in block %d", req.TxHash, req.Height)
+		}
+	}
+	proof, err := blockchain.ProveTransaction(block.TxRootType, block.Transactions, index)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	tx := block.Transactions[index]
+	return &nodepb.TransactionProof{
+		Height:      block.Height,
+		BlockHash:   block.CurrentBlockHash,
+		MerkleRoot:  block.MerkleRoot,
+		Index:       int32(index),
+		TxHash:      tx.Hash(),
+		Transaction: blockchain.TransactionToProto(tx),
+		Proof:       proof,
+		TxRootType:  int32(block.TxRootType),
+		TxCount:     int32(len(block.Transactions)),
+	}, nil
+}
+
+// ListSnapshots returns the state snapshots this node can serve, newest first.
+func (s *NodeServer) ListSnapshots(ctx context.Context, _ *nodepb.Empty) (*nodepb.SnapshotList, error) {
+	if s.Snapshots == nil {
+		return &nodepb.SnapshotList{}, nil
+	}
+	infos, err := s.Snapshots.List()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "can not list snapshots: %v", err)
+	}
+	list := &nodepb.SnapshotList{}
+	for _, info := range infos {
+		list.Snapshots = append(list.Snapshots, snapshot.InfoToProto(info))
+	}
+	return list, nil
+}
+
+// GetSnapshotChunk returns one chunk of the snapshot at the requested height.
+func (s *NodeServer) GetSnapshotChunk(ctx context.Context, req *nodepb.SnapshotChunkRequest) (*nodepb.SnapshotChunk, error) {
+	if s.Snapshots == nil {
+		return nil, status.Errorf(codes.NotFound, "snapshots are disabled on this node")
+	}
+	accounts, err := s.Snapshots.Chunk(req.Height, int(req.Index))
+	if err != nil {
+		if errors.Is(err, snapshot.ErrNotFound) {
+			return nil, status.Errorf(codes.NotFound, "%v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "can not load snapshot chunk: %v", err)
+	}
+	return &nodepb.SnapshotChunk{
+		Height:   req.Height,
+		Index:    req.Index,
+		Accounts: snapshot.AccountsToProto(accounts),
+	}, nil
+}
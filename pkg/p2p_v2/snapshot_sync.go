@@ -0,0 +1,103 @@
+package p2p_v2
+
+import (
+	"blockchain-go/pkg/blockchain"
+	"blockchain-go/pkg/snapshot"
+	"blockchain-go/pkg/state"
+	"blockchain-go/pkg/storage"
+	"blockchain-go/proto/nodepb"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// ErrNoSnapshot is returned by FastSync when the peer has no snapshot above
+// the local chain.
+var ErrNoSnapshot = errors.New("peer has no usable snapshot")
+
+// FastSync restores the state from the newest snapshot offered by the peer
+// instead of replaying every block. The snapshot's block hash and state root
+// must match the header the peer serves for that height, the chunks must
+// match the snapshot hashes and the accounts must produce the state root.
+// On success the snapshot block becomes the local tip; later blocks are then
+// synced normally with a Syncer.
+func FastSync(ctx context.Context, peer string, client nodepb.NodeServiceClient, db *storage.DB, st *state.State) (*blockchain.Block, error) {
+	list, err := client.ListSnapshots(ctx, &nodepb.Empty{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots on %s: %w", peer, err)
+	}
+
+	local, _ := db.GetLatestBlock()
+	if len(list.Snapshots) == 0 || (local != nil && list.Snapshots[0].Height <= local.Height) {
+		return nil, ErrNoSnapshot
+	}
+	info := snapshot.ProtoToInfo(list.Snapshots[0])
+	log.Printf("📸 Fast sync from %s using snapshot at height %d (%d accounts, %d chunks)", peer, info.Height, info.Accounts, info.Chunks())
+
+	// Both chains must start from the same genesis block.
+	if genesis, _, err := db.GetHeaderByHeight(0); err == nil {
+		remote, err := client.GetBlockHeader(ctx, &nodepb.BlockRequest{Height: 0})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get genesis header: %w", err)
+		}
+		if !bytes.Equal(remote.CurrentBlockHash, genesis.CurrentBlockHash) {
+			return nil, fmt.Errorf("peer %s has a different genesis block", peer)
+		}
+	}
+
+	header, err := client.GetBlockHeader(ctx, &nodepb.BlockRequest{Height: info.Height})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get header %d: %w", info.Height, err)
+	}
+	// Header tự khớp với hash của nó, nên state root nằm trong block hash
+	if !bytes.Equal(blockchain.ProtoToHeader(header).Hash(), header.CurrentBlockHash) {
+		return nil, fmt.Errorf("header %d does not match its hash", info.Height)
+	}
+	if !bytes.Equal(header.CurrentBlockHash, info.BlockHash) {
+		return nil, fmt.Errorf("snapshot block hash does not match header %d", info.Height)
+	}
+	if !bytes.Equal(header.StateRoot, info.StateRoot) {
+		return nil, fmt.Errorf("snapshot state root does not match header %d", info.Height)
+	}
+	pb, err := client.GetBlock(ctx, &nodepb.BlockRequest{Height: info.Height})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block %d: %w", info.Height, err)
+	}
+	block := blockchain.ProtoToBlock(pb)
+	if !bytes.Equal(block.Hash(), header.CurrentBlockHash) || !block.TxRootType.Valid() ||
+		!bytes.Equal(blockchain.TxRoot(block.TxRootType, block.Transactions), block.MerkleRoot) {
+		return nil, fmt.Errorf("block %d does not match its header", info.Height)
+	}
+
+	chunks := make([][]state.Account, info.Chunks())
+	for i := range chunks {
+		res, err := client.GetSnapshotChunk(ctx, &nodepb.SnapshotChunkRequest{Height: info.Height, Index: int32(i)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to download chunk %d: %w", i, err)
+		}
+		chunks[i] = snapshot.ProtoToAccounts(res.Accounts)
+	}
+	if err := snapshot.Verify(info, chunks); err != nil {
+		return nil, fmt.Errorf("snapshot verification failed: %w", err)
+	}
+
+	var accounts []state.Account
+	for _, chunk := range chunks {
+		accounts = append(accounts, chunk...)
+	}
+	if err := st.RestoreSnapshot(info.Height, accounts); err != nil {
+		return nil, fmt.Errorf("failed to restore state: %w", err)
+	}
+	if err := db.SaveBlock(block); err != nil {
+		return nil, fmt.Errorf("failed to save snapshot block: %w", err)
+	}
+	// Blocks before the snapshot are never downloaded; report them as pruned.
+	if err := db.SetPrunedBelow(info.Height); err != nil {
+		return nil, fmt.Errorf("failed to mark skipped blocks: %w", err)
+	}
+
+	log.Printf("✅ State restored at height %d, root %x", info.Height, info.StateRoot)
+	return block, nil
+}
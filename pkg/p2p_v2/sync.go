@@ -0,0 +1,224 @@
+package p2p_v2
+
+import (
+	"blockchain-go/pkg/blockchain"
+	"blockchain-go/pkg/storage"
+	"blockchain-go/proto/nodepb"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// SyncOptions controls how much data a Syncer asks for at a time.
+type SyncOptions struct {
+	// BatchSize is the number of blocks requested per GetBlockRange call.
+	BatchSize int64
+	// Workers is the number of body batches downloaded in parallel.
+	Workers int
+	// WindowSize is the number of headers verified before their bodies
+	// are downloaded. It bounds memory use on long chains.
+	WindowSize int64
+}
+
+// DefaultSyncOptions returns the options used by the node.
+func DefaultSyncOptions() SyncOptions {
+	return SyncOptions{
+		BatchSize:  DefaultSyncBlocks,
+		Workers:    4,
+		WindowSize: 4096,
+	}
+}
+
+// Syncer downloads blocks from a peer header-first: it fetches and checks the
+// header chain for a window of heights, downloads the bodies of that window in
+// parallel and then commits them in order.
+type Syncer struct {
+	db     *storage.DB
+	commit func(block *blockchain.Block) error
+	opts   SyncOptions
+}
+
+// NewSyncer creates a Syncer. commit is called for every downloaded block in
+// height order; it is expected to validate and persist the block.
+func NewSyncer(db *storage.DB, commit func(block *blockchain.Block) error, opts SyncOptions) *Syncer {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultSyncBlocks
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+	if opts.WindowSize < opts.BatchSize {
+		opts.WindowSize = opts.BatchSize
+	}
+	return &Syncer{db: db, commit: commit, opts: opts}
+}
+
+// SyncFrom catches up with the peer behind client and returns the number of
+// committed blocks. Every block is committed as soon as its window is
+// verified, so the local latest block is the sync progress: after a failure
+// or a restart, SyncFrom resumes from there with any peer.
+func (s *Syncer) SyncFrom(ctx context.Context, peer string, client nodepb.NodeServiceClient) (int, error) {
+	tip, err := client.GetLatestBlockHeader(ctx, &nodepb.Empty{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest header from %s: %w", peer, err)
+	}
+
+	local, err := s.db.GetLatestBlock()
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return 0, fmt.Errorf("failed to read local tip: %w", err)
+	}
+	next := int64(0)
+	var prevHash []byte
+	if local != nil {
+		next = local.Height + 1
+		prevHash = local.CurrentBlockHash
+	}
+
+	if tip.Height < next {
+		return 0, nil
+	}
+	log.Printf("🔄 Syncing heights %d..%d from %s", next, tip.Height, peer)
+
+	synced := 0
+	for next <= tip.Height {
+		end := next + s.opts.WindowSize - 1
+		if end > tip.Height {
+			end = tip.Height
+		}
+
+		headers, err := s.fetchHeaders(ctx, client, next, end, prevHash)
+		if err != nil {
+			return synced, err
+		}
+
+		blocks, err := s.fetchBodies(ctx, client, next, end)
+		if err != nil {
+			return synced, err
+		}
+
+		for i, block := range blocks {
+			header := headers[i]
+			if !bytes.Equal(block.Hash(), header.CurrentBlockHash) || !bytes.Equal(blockchain.TxRoot(block.TxRootType, block.Transactions), header.MerkleRoot) {
+				return synced, fmt.Errorf("block %d does not match its header", block.Height)
+			}
+			if err := s.commit(block); err != nil {
+				return synced, fmt.Errorf("failed to commit synced block %d: %w", block.Height, err)
+			}
+			synced++
+		}
+
+		prevHash = headers[len(headers)-1].CurrentBlockHash
+		next = end + 1
+		log.Printf("⛓️  Synced up to height %d / %d", end, tip.Height)
+	}
+
+	return synced, nil
+}
+
+// fetchHeaders downloads headers from..to and checks that they form a chain
+// starting at prevHash.
+func (s *Syncer) fetchHeaders(ctx context.Context, client nodepb.NodeServiceClient, from, to int64, prevHash []byte) ([]*nodepb.BlockHeader, error) {
+	headers := make([]*nodepb.BlockHeader, 0, to-from+1)
+	for h := from; h <= to; {
+		res, err := client.GetHeaderRange(ctx, &nodepb.BlockRangeRequest{FromHeight: h, MaxBlocks: to - h + 1})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get headers from height %d: %w", h, err)
+		}
+		if len(res.Headers) == 0 {
+			return nil, fmt.Errorf("peer returned no headers at height %d", h)
+		}
+		for _, header := range res.Headers {
+			if header.Height != h {
+				return nil, fmt.Errorf("unexpected header height %d, want %d", header.Height, h)
+			}
+			if prevHash != nil && !bytes.Equal(header.PreviousBlockHash, prevHash) {
+				return nil, fmt.Errorf("header %d does not link to previous block", header.Height)
+			}
+			if !bytes.Equal(blockchain.ProtoToHeader(header).Hash(), header.CurrentBlockHash) {
+				return nil, fmt.Errorf("header %d does not match its hash", header.Height)
+			}
+			prevHash = header.CurrentBlockHash
+			headers = append(headers, header)
+			h++
+			if h > to {
+				break
+			}
+		}
+	}
+	return headers, nil
+}
+
+// fetchBodies downloads blocks from..to using up to opts.Workers parallel
+// requests and returns them in height order.
+func (s *Syncer) fetchBodies(ctx context.Context, client nodepb.NodeServiceClient, from, to int64) ([]*blockchain.Block, error) {
+	blocks := make([]*blockchain.Block, to-from+1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	batches := make(chan int64)
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+
+	for w := 0; w < s.opts.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range batches {
+				end := start + s.opts.BatchSize - 1
+				if end > to {
+					end = to
+				}
+				if err := fetchBatch(ctx, client, start, end, blocks[start-from:end-from+1]); err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+	for start := from; start <= to; start += s.opts.BatchSize {
+		select {
+		case batches <- start:
+		case <-ctx.Done():
+		}
+	}
+	close(batches)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return blocks, nil
+}
+
+// fetchBatch fills out with blocks start..end, following the server's paging
+// when a response is capped by size.
+func fetchBatch(ctx context.Context, client nodepb.NodeServiceClient, start, end int64, out []*blockchain.Block) error {
+	for h := start; h <= end; {
+		res, err := client.GetBlockRange(ctx, &nodepb.BlockRangeRequest{FromHeight: h, MaxBlocks: end - h + 1})
+		if err != nil {
+			return fmt.Errorf("failed to get blocks from height %d: %w", h, err)
+		}
+		if len(res.Blocks) == 0 {
+			return fmt.Errorf("peer returned no blocks at height %d", h)
+		}
+		for _, pb := range res.Blocks {
+			if pb.Height != h {
+				return fmt.Errorf("unexpected block height %d, want %d", pb.Height, h)
+			}
+			out[h-start] = blockchain.ProtoToBlock(pb)
+			h++
+			if h > end {
+				break
+			}
+		}
+	}
+	return nil
+}
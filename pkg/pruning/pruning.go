@@ -0,0 +1,121 @@
+package pruning
+
+import (
+	"blockchain-go/pkg/state"
+	"blockchain-go/pkg/storage"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Mode selects how much history a node keeps.
+type Mode string
+
+const (
+	// Archive keeps every block body and the full state history.
+	Archive Mode = "archive"
+	// Full keeps every block body but only Retention blocks of state history.
+	Full Mode = "full"
+	// Pruned keeps only the last Retention block bodies and state history.
+	// Headers and the hash/height indexes are always kept.
+	Pruned Mode = "pruned"
+)
+
+// DefaultRetention is the number of recent blocks kept by full and pruned nodes.
+const DefaultRetention = 1000
+
+// pruneEvery is how often, in blocks, a pruning pass runs.
+const pruneEvery = 100
+
+// ParseMode parses a mode name; an empty name means Archive.
+func ParseMode(name string) (Mode, error) {
+	switch mode := Mode(strings.ToLower(name)); mode {
+	case "":
+		return Archive, nil
+	case Archive, Full, Pruned:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown prune mode %q (use archive, full or pruned)", name)
+	}
+}
+
+// Pruner removes data that falls out of the retention window.
+type Pruner struct {
+	Mode      Mode
+	Retention int64
+	db        *storage.DB
+	state     *state.State
+
+	// running is set while a background pass runs; wg waits for it.
+	running atomic.Bool
+	wg      sync.WaitGroup
+}
+
+// NewPruner creates a pruner for db and its state.
+func NewPruner(mode Mode, retention int64, db *storage.DB, st *state.State) *Pruner {
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	return &Pruner{Mode: mode, Retention: retention, db: db, state: st}
+}
+
+// MaybePrune starts a pruning pass every pruneEvery blocks. The pass runs in
+// the background, because compacting the database can take long and
+// MaybePrune is called while a block is being committed; if the previous
+// pass is still running, this one is skipped. Errors are logged because
+// pruning must not stop block commits.
+func (p *Pruner) MaybePrune(latestHeight int64) {
+	if p.Mode == Archive || latestHeight%pruneEvery != 0 {
+		return
+	}
+	if !p.running.CompareAndSwap(false, true) {
+		log.Printf("⏭️ Pruning at height %d skipped: the previous pass is still running", latestHeight)
+		return
+	}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer p.running.Store(false)
+		if err := p.Prune(latestHeight); err != nil {
+			log.Printf("⚠️ Pruning at height %d failed: %v", latestHeight, err)
+		}
+	}()
+}
+
+// Wait blocks until the background pass started by MaybePrune, if any, is
+// done.
+func (p *Pruner) Wait() {
+	p.wg.Wait()
+}
+
+// Prune drops everything older than the retention window below latestHeight.
+func (p *Pruner) Prune(latestHeight int64) error {
+	cutoff := latestHeight - p.Retention
+	if p.Mode == Archive || cutoff <= 0 {
+		return nil
+	}
+
+	diffs, err := p.state.PruneHistory(cutoff)
+	if err != nil {
+		return fmt.Errorf("failed to prune state history: %w", err)
+	}
+
+	blocks := 0
+	if p.Mode == Pruned {
+		blocks, err = p.db.PruneBlocksBelow(cutoff + 1)
+		if err != nil {
+			return fmt.Errorf("failed to prune block bodies: %w", err)
+		}
+	}
+
+	if diffs == 0 && blocks == 0 {
+		return nil
+	}
+	if err := p.db.Compact(); err != nil {
+		return fmt.Errorf("failed to compact database: %w", err)
+	}
+	log.Printf("✂️ Pruned %d block bodies and %d state diffs below height %d", blocks, diffs, cutoff+1)
+	return nil
+}
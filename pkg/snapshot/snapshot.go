@@ -0,0 +1,296 @@
+package snapshot
+
+import (
+	"blockchain-go/pkg/blockchain"
+	"blockchain-go/pkg/state"
+	"blockchain-go/pkg/storage"
+	"blockchain-go/proto/nodepb"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+)
+
+// ChunkSize is the number of accounts per snapshot chunk. It keeps a chunk
+// far below the gRPC message limit.
+const ChunkSize = 1000
+
+var (
+	metaPrefix  = string(storage.SnapshotKey("meta/"))
+	chunkPrefix = string(storage.SnapshotKey("chunk/"))
+)
+
+// ErrNotFound is returned when a snapshot or chunk does not exist.
+var ErrNotFound = errors.New("snapshot not found")
+
+// Info describes a snapshot of the balances taken right after the block at
+// Height was committed.
+type Info struct {
+	Height      int64    `json:"height"`
+	BlockHash   []byte   `json:"block_hash"`
+	StateRoot   []byte   `json:"state_root"`
+	Accounts    int      `json:"accounts"`
+	ChunkHashes [][]byte `json:"chunk_hashes"`
+}
+
+// Chunks returns the number of chunks in the snapshot.
+func (i *Info) Chunks() int {
+	return len(i.ChunkHashes)
+}
+
+// Store keeps snapshots in the node database and creates new ones every
+// Interval blocks, keeping only the newest Keep of them.
+type Store struct {
+	db       *storage.DB
+	Interval int64
+	Keep     int
+}
+
+// NewStore creates a snapshot store. An interval of 0 disables automatic
+// snapshots.
+func NewStore(db *storage.DB, interval int64, keep int) *Store {
+	if keep <= 0 {
+		keep = 1
+	}
+	return &Store{db: db, Interval: interval, Keep: keep}
+}
+
+// MaybeCreate takes a snapshot when block is at a snapshot interval. Errors
+// are logged because a missing snapshot must not stop block commits.
+func (s *Store) MaybeCreate(st *state.State, block *blockchain.Block) {
+	if s.Interval <= 0 || block.Height == 0 || block.Height%s.Interval != 0 {
+		return
+	}
+	info, err := s.Create(st, block)
+	if err != nil {
+		log.Printf("⚠️ Could not create snapshot at height %d: %v", block.Height, err)
+		return
+	}
+	log.Printf("📸 Snapshot at height %d: %d accounts in %d chunks, root %x", info.Height, info.Accounts, info.Chunks(), info.StateRoot)
+}
+
+// Create exports the current balances as a snapshot for block. The state must
+// have been applied up to exactly block.Height.
+func (s *Store) Create(st *state.State, block *blockchain.Block) (*Info, error) {
+	height, ok, err := st.Height()
+	if err != nil {
+		return nil, err
+	}
+	if !ok || height != block.Height {
+		return nil, fmt.Errorf("state is at height %d, not %d", height, block.Height)
+	}
+
+	accounts, err := st.Accounts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read accounts: %w", err)
+	}
+
+	info := &Info{
+		Height:    block.Height,
+		BlockHash: block.CurrentBlockHash,
+		StateRoot: state.ComputeStateRoot(accounts),
+		Accounts:  len(accounts),
+	}
+	for i, chunk := range split(accounts) {
+		data, err := json.Marshal(chunk)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.db.Put(chunkKey(block.Height, i), data); err != nil {
+			return nil, fmt.Errorf("failed to save chunk %d: %w", i, err)
+		}
+		info.ChunkHashes = append(info.ChunkHashes, ChunkHash(chunk))
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Put(metaKey(block.Height), data); err != nil {
+		return nil, fmt.Errorf("failed to save snapshot info: %w", err)
+	}
+
+	s.prune()
+	return info, nil
+}
+
+// List returns every stored snapshot, newest first.
+func (s *Store) List() ([]*Info, error) {
+	var infos []*Info
+	err := s.db.IteratePrefix([]byte(metaPrefix), func(_, value []byte) error {
+		var info Info
+		if err := json.Unmarshal(value, &info); err != nil {
+			return fmt.Errorf("failed to decode snapshot info: %w", err)
+		}
+		infos = append(infos, &info)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Height > infos[j].Height })
+	return infos, nil
+}
+
+// Info returns the snapshot taken at height.
+func (s *Store) Info(height int64) (*Info, error) {
+	data, err := s.db.Get(metaKey(height))
+	if err != nil {
+		return nil, fmt.Errorf("%w at height %d", ErrNotFound, height)
+	}
+	var info Info
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot info: %w", err)
+	}
+	return &info, nil
+}
+
+// Chunk returns the accounts of one chunk of the snapshot at height.
+func (s *Store) Chunk(height int64, index int) ([]state.Account, error) {
+	data, err := s.db.Get(chunkKey(height, index))
+	if err != nil {
+		return nil, fmt.Errorf("%w: chunk %d at height %d", ErrNotFound, index, height)
+	}
+	var accounts []state.Account
+	if err := json.Unmarshal(data, &accounts); err != nil {
+		return nil, fmt.Errorf("failed to decode chunk: %w", err)
+	}
+	return accounts, nil
+}
+
+// prune deletes all but the newest Keep snapshots.
+func (s *Store) prune() {
+	infos, err := s.List()
+	if err != nil || len(infos) <= s.Keep {
+		return
+	}
+	for _, info := range infos[s.Keep:] {
+		s.delete(info)
+	}
+}
+
+// DeleteAbove deletes the snapshots taken after height, for example when the
+// chain has been truncated below them.
+func (s *Store) DeleteAbove(height int64) (int, error) {
+	infos, err := s.List()
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, info := range infos {
+		if info.Height <= height {
+			continue
+		}
+		if err := s.delete(info); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+func (s *Store) delete(info *Info) error {
+	for i := 0; i < info.Chunks(); i++ {
+		if err := s.db.Delete(chunkKey(info.Height, i)); err != nil {
+			return err
+		}
+	}
+	return s.db.Delete(metaKey(info.Height))
+}
+
+// ChunkHash hashes the accounts of a chunk in a fixed binary layout.
+func ChunkHash(accounts []state.Account) []byte {
+	var buf bytes.Buffer
+	for _, acc := range accounts {
+		binary.Write(&buf, binary.BigEndian, uint32(len(acc.Address)))
+		buf.WriteString(acc.Address)
+		binary.Write(&buf, binary.BigEndian, math.Float64bits(acc.Balance))
+	}
+	h := sha256.Sum256(buf.Bytes())
+	return h[:]
+}
+
+// Verify checks downloaded chunks against info: every chunk hash must match
+// and the accounts together must produce info.StateRoot.
+func Verify(info *Info, chunks [][]state.Account) error {
+	if len(chunks) != info.Chunks() {
+		return fmt.Errorf("expected %d chunks, got %d", info.Chunks(), len(chunks))
+	}
+	var all []state.Account
+	for i, chunk := range chunks {
+		if !bytes.Equal(ChunkHash(chunk), info.ChunkHashes[i]) {
+			return fmt.Errorf("chunk %d hash mismatch", i)
+		}
+		all = append(all, chunk...)
+	}
+	if len(all) != info.Accounts {
+		return fmt.Errorf("expected %d accounts, got %d", info.Accounts, len(all))
+	}
+	if root := state.ComputeStateRoot(all); !bytes.Equal(root, info.StateRoot) {
+		return fmt.Errorf("state root mismatch (expected %x, got %x)", info.StateRoot, root)
+	}
+	return nil
+}
+
+func split(accounts []state.Account) [][]state.Account {
+	var chunks [][]state.Account
+	for len(accounts) > ChunkSize {
+		chunks = append(chunks, accounts[:ChunkSize])
+		accounts = accounts[ChunkSize:]
+	}
+	// An empty state still has one (empty) chunk so the layout is uniform.
+	return append(chunks, accounts)
+}
+
+func metaKey(height int64) []byte {
+	return []byte(metaPrefix + storage.PaddedHeight(height))
+}
+
+func chunkKey(height int64, index int) []byte {
+	return []byte(fmt.Sprintf("%s%s-%d", chunkPrefix, storage.PaddedHeight(height), index))
+}
+
+// InfoToProto converts snapshot metadata to its wire form.
+func InfoToProto(info *Info) *nodepb.SnapshotInfo {
+	return &nodepb.SnapshotInfo{
+		Height:      info.Height,
+		BlockHash:   info.BlockHash,
+		StateRoot:   info.StateRoot,
+		Accounts:    int64(info.Accounts),
+		ChunkHashes: info.ChunkHashes,
+	}
+}
+
+// ProtoToInfo converts wire snapshot metadata back to an Info.
+func ProtoToInfo(pb *nodepb.SnapshotInfo) *Info {
+	return &Info{
+		Height:      pb.Height,
+		BlockHash:   pb.BlockHash,
+		StateRoot:   pb.StateRoot,
+		Accounts:    int(pb.Accounts),
+		ChunkHashes: pb.ChunkHashes,
+	}
+}
+
+// AccountsToProto converts the accounts of a chunk to their wire form.
+func AccountsToProto(accounts []state.Account) []*nodepb.Account {
+	pbs := make([]*nodepb.Account, 0, len(accounts))
+	for _, acc := range accounts {
+		pbs = append(pbs, &nodepb.Account{Address: acc.Address, Balance: acc.Balance})
+	}
+	return pbs
+}
+
+// ProtoToAccounts converts wire accounts back to state accounts.
+func ProtoToAccounts(pbs []*nodepb.Account) []state.Account {
+	accounts := make([]state.Account, 0, len(pbs))
+	for _, pb := range pbs {
+		accounts = append(accounts, state.Account{Address: pb.Address, Balance: pb.Balance})
+	}
+	return accounts
+}
@@ -2,34 +2,111 @@ package state
 
 import (
 	"blockchain-go/pkg/blockchain"
+	"blockchain-go/pkg/mpt"
 	"blockchain-go/pkg/storage"
+	"encoding/binary"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	// "log"
+	"sort"
 	"strconv"
+	"sync"
+)
+
+var balancePrefix = string(storage.StateKey("balance/"))
+
+// heightKey records the height of the last block applied to the balances, so
+// a restart only replays the blocks after it.
+var heightKey = storage.StateKey("height")
+
+// historyBelowKey records the lowest height whose balances can still be
+// reconstructed from the per-block diffs.
+var historyBelowKey = storage.StateKey("history-below")
 
-	"github.com/syndtr/goleveldb/leveldb" // SỬA ĐỔI: Import package chính, không phải 'opt'
+// trieSavedKey is set while the stored balances are exactly those of the
+// state trie root kept in the trie database, so it can be opened instead of
+// rebuilt. It is removed before a balance changes on its own, and written in
+// the same batch as the balances when the trie is saved with them.
+var trieSavedKey = storage.StateKey("trie-saved")
+
+// DefaultAccountCacheSize is how many balances State keeps in memory.
+const DefaultAccountCacheSize = 65536
+
+// stateRootName is the name of the current state root in the trie database.
+// The roots of the last keptStateRoots commits are kept too, so that a
+// speculation started on an older trie can still load its nodes; older roots
+// are pruned.
+const (
+	stateRootName  = "state"
+	keptStateRoots = 8
 )
 
 // State quản lý số dư của các tài khoản
 type State struct {
 	db *storage.DB
+
+	// accounts is a write-through cache of balances; addresses without a
+	// stored balance are cached as 0. cacheMutex orders cache fills after a
+	// miss with writes, so a fill can not bring back an overwritten balance.
+	accounts   *storage.LRU[string, float64]
+	cacheMutex sync.Mutex
+
+	// trie holds the balances as the state trie, so the root is updated
+	// with each balance instead of rebuilt from every account. Its nodes are
+	// stored in nodes and loaded as they are needed; it is committed after
+	// every block and dropped when it may be out of date.
+	trie      *mpt.MPT
+	nodes     *mpt.Database
+	trieSaved bool
+	trieMutex sync.Mutex
+}
+
+// Account is one entry of the balance table.
+type Account struct {
+	Address string
+	Balance float64
 }
 
 // NewState tạo một State Manager mới
 func NewState(db *storage.DB) (*State, error) {
-	s := &State{db: db}
+	s := &State{
+		db:       db,
+		accounts: storage.NewLRU[string, float64](DefaultAccountCacheSize),
+		nodes:    mpt.NewDatabase(db.TrieStore()),
+	}
+	if _, err := db.Get(trieSavedKey); err == nil {
+		s.trieSaved = true
+	} else if !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
 	return s, nil
 }
 
+// SetCacheSize changes how many balances are cached. 0 disables the cache.
+func (s *State) SetCacheSize(accounts int) {
+	s.accounts.Resize(accounts)
+}
+
+// CacheStats returns the hit and miss counters of the balance cache.
+func (s *State) CacheStats() storage.CacheStats {
+	return s.accounts.Stats()
+}
+
 // GetBalance lấy số dư của một địa chỉ (dạng chuỗi hex)
 func (s *State) GetBalance(address string) (float64, error) {
-	key := []byte("balance-" + address)
+	if balance, ok := s.accounts.Get(address); ok {
+		return balance, nil
+	}
+	s.cacheMutex.Lock()
+	defer s.cacheMutex.Unlock()
+
+	key := []byte(balancePrefix + address)
 	data, err := s.db.Get(key)
 	if err != nil {
-		// SỬA ĐỔI: Sử dụng leveldb.ErrNotFound
-		if errors.Is(err, leveldb.ErrNotFound) {
+		if errors.Is(err, storage.ErrNotFound) {
+			s.accounts.Add(address, 0)
 			return 0, nil // Nếu không tìm thấy, số dư là 0
 		}
 		return 0, err // Lỗi khác
@@ -39,34 +116,64 @@ func (s *State) GetBalance(address string) (float64, error) {
 	if err != nil {
 		return 0, fmt.Errorf("could not parse balance: %w", err)
 	}
+	s.accounts.Add(address, balance)
 	return balance, nil
 }
 
 // SetBalance đặt số dư cho một địa chỉ (dạng chuỗi hex)
 func (s *State) SetBalance(address string, balance float64) error {
-	key := []byte("balance-" + address)
-	value := []byte(strconv.FormatFloat(balance, 'f', -1, 64))
-	return s.db.Put(key, value, nil)
+	s.cacheMutex.Lock()
+	defer s.cacheMutex.Unlock()
+	s.trieMutex.Lock()
+	defer s.trieMutex.Unlock()
+	if err := s.unsaveTrie(); err != nil {
+		return err
+	}
+
+	key := []byte(balancePrefix + address)
+	value := balanceValue(balance)
+	if err := s.db.Put(key, value, nil); err != nil {
+		s.accounts.Remove(address)
+		s.trie = nil
+		return err
+	}
+	s.accounts.Add(address, balance)
+
+	if s.trie != nil {
+		if err := s.trie.Insert(accountKey(address), value); err != nil {
+			// Trie được dựng lại từ số dư khi cần
+			s.trie = nil
+		}
+	}
+	return nil
 }
 
 // ApplyTransaction cập nhật số dư dựa trên một giao dịch.
 func (s *State) ApplyTransaction(tx *blockchain.Transaction) error {
+	return applyTransaction(s, tx)
+}
+
+// balanceStore is where applyTransaction reads and writes balances: the
+// database for committed blocks, a Speculation for previews.
+type balanceStore interface {
+	GetBalance(address string) (float64, error)
+	SetBalance(address string, balance float64) error
+}
+
+func applyTransaction(s balanceStore, tx *blockchain.Transaction) error {
 	// Xử lý trường hợp người gửi là giao dịch GENESIS
 	if string(tx.Sender) == "GENESIS" {
 		receiverKey := hex.EncodeToString(tx.Receiver)
-		// log.Printf("--- DEBUG [ApplyTx]: Applying GENESIS tx. Receiver: %s, Amount: %f", receiverKey, tx.Amount) // DEBUG
 
 		receiverBalance, err := s.GetBalance(receiverKey)
 		if err != nil {
 			return fmt.Errorf("failed to get receiver balance for genesis tx: %w", err)
 		}
-		// log.Printf("--- DEBUG [ApplyTx]: Old balance: %f", receiverBalance) // DEBUG
 
 		newBalance := receiverBalance + tx.Amount
 		if err := s.SetBalance(receiverKey, newBalance); err != nil {
 			return fmt.Errorf("failed to set receiver balance for genesis tx: %w", err)
 		}
-		// log.Printf("--- DEBUG [ApplyTx]: New balance set to: %f", newBalance) // DEBUG
 		return nil
 	}
 
@@ -98,36 +205,543 @@ func (s *State) ApplyTransaction(tx *blockchain.Transaction) error {
 	return nil
 }
 
+// Speculation executes transactions on top of the state without changing
+// it: balances change in memory and in a fork of the state trie, so the root
+// a block leads to is known before the block is accepted. A speculation that
+// is not wanted, e.g. because the block is invalid, is simply dropped.
+type Speculation struct {
+	state   *State
+	changed map[string]float64
+	trie    *mpt.MPT
+	err     error // the first trie error, reported by Root
+}
+
+// Speculate starts a speculation from the current balances.
+func (s *State) Speculate() (*Speculation, error) {
+	s.trieMutex.Lock()
+	defer s.trieMutex.Unlock()
+	trie, err := s.loadTrie()
+	if err != nil {
+		return nil, err
+	}
+	return &Speculation{state: s, changed: make(map[string]float64), trie: trie.Fork()}, nil
+}
+
+func (sp *Speculation) GetBalance(address string) (float64, error) {
+	if balance, ok := sp.changed[address]; ok {
+		return balance, nil
+	}
+	return sp.state.GetBalance(address)
+}
+
+func (sp *Speculation) SetBalance(address string, balance float64) error {
+	if err := sp.trie.Insert(accountKey(address), balanceValue(balance)); err != nil {
+		if sp.err == nil {
+			sp.err = fmt.Errorf("failed to update state trie: %w", err)
+		}
+		return sp.err
+	}
+	sp.changed[address] = balance
+	return nil
+}
+
+// ApplyTransaction applies tx to the speculative balances.
+func (sp *Speculation) ApplyTransaction(tx *blockchain.Transaction) error {
+	return applyTransaction(sp, tx)
+}
+
+// Root returns the state root of the speculative balances.
+func (sp *Speculation) Root() ([]byte, error) {
+	if sp.err != nil {
+		return nil, sp.err
+	}
+	return sp.trie.RootHash(), nil
+}
+
+// PreviewRoot returns the state root the balances would have after applying
+// txs, without changing them. Transactions that would fail are skipped, as
+// in ApplyBlock.
+func (s *State) PreviewRoot(txs []*blockchain.Transaction) ([]byte, error) {
+	sp, err := s.Speculate()
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range txs {
+		sp.ApplyTransaction(tx)
+	}
+	return sp.Root()
+}
+
+// ErrTransactionFailed marks the transactions of a block that could not be
+// applied, e.g. for insufficient funds. They are skipped; the rest of the
+// block is applied.
+var ErrTransactionFailed = errors.New("transaction failed")
+
+// ApplyBlock applies every transaction of a committed block and records the
+// block height. The diff, the new balances, the height and the state trie
+// are written in one batch, so after a crash or a failed write the state is
+// either before or after the block, and the block is never applied twice. A failing transaction does not stop the
+// others; all failures are returned together, wrapping ErrTransactionFailed.
+// Any other error means the block was not applied.
+func (s *State) ApplyBlock(block *blockchain.Block) error {
+	diff, err := s.blockDiff(block)
+	if err != nil {
+		return fmt.Errorf("failed to record state diff: %w", err)
+	}
+
+	writes := &blockWrites{state: s, changed: make(map[string]float64)}
+	var errs []error
+	for _, tx := range block.Transactions {
+		if err := applyTransaction(writes, tx); err != nil {
+			errs = append(errs, fmt.Errorf("%w: %w", ErrTransactionFailed, err))
+		}
+	}
+	if err := s.writeBlock(block.Height, diff, writes.changed); err != nil {
+		return fmt.Errorf("failed to write state of block %d: %w", block.Height, err)
+	}
+	return errors.Join(errs...)
+}
+
+// blockWrites collects the balances a block changes, so they are written
+// together with its diff and height.
+type blockWrites struct {
+	state   *State
+	changed map[string]float64
+}
+
+func (w *blockWrites) GetBalance(address string) (float64, error) {
+	if balance, ok := w.changed[address]; ok {
+		return balance, nil
+	}
+	return w.state.GetBalance(address)
+}
+
+func (w *blockWrites) SetBalance(address string, balance float64) error {
+	w.changed[address] = balance
+	return nil
+}
+
+// writeBlock writes the diff, the changed balances, the height and the
+// committed state trie of the block at height in one batch, then updates the
+// cache.
+func (s *State) writeBlock(height int64, diff []byte, changed map[string]float64) error {
+	s.cacheMutex.Lock()
+	defer s.cacheMutex.Unlock()
+	s.trieMutex.Lock()
+	defer s.trieMutex.Unlock()
+
+	trie, err := s.loadTrie()
+	if err != nil {
+		return err
+	}
+	batch := s.db.NewBatch()
+	batch.Put(diffKey(height), diff)
+	updates := make(map[string][]byte, len(changed))
+	for address, balance := range changed {
+		batch.Put([]byte(balancePrefix+address), balanceValue(balance))
+		updates[string(accountKey(address))] = balanceValue(balance)
+	}
+	batch.Put(heightKey, markerValue(height))
+	if err := trie.Update(updates); err != nil {
+		s.trie = nil
+		return err
+	}
+	if err := s.saveTrie(trie, height, batch); err != nil {
+		return err
+	}
+	for address, balance := range changed {
+		s.accounts.Add(address, balance)
+	}
+	return nil
+}
+
+// Height returns the height of the last block applied to the state.
+func (s *State) Height() (int64, bool, error) {
+	return s.getMarker(heightKey)
+}
+
+// Accounts returns every account with a balance entry, sorted by address.
+func (s *State) Accounts() ([]Account, error) {
+	var accounts []Account
+	err := s.db.IteratePrefix([]byte(balancePrefix), func(key, value []byte) error {
+		balance, err := strconv.ParseFloat(string(value), 64)
+		if err != nil {
+			return fmt.Errorf("could not parse balance of %s: %w", key, err)
+		}
+		accounts = append(accounts, Account{Address: string(key[len(balancePrefix):]), Balance: balance})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].Address < accounts[j].Address })
+	return accounts, nil
+}
+
+// Root returns the state root of the current balances.
+func (s *State) Root() ([]byte, error) {
+	s.trieMutex.Lock()
+	defer s.trieMutex.Unlock()
+	trie, err := s.loadTrie()
+	if err != nil {
+		return nil, err
+	}
+	return trie.RootHash(), nil
+}
+
+// loadTrie returns the state trie. It opens the saved root when the balances
+// have not changed since it was committed, and otherwise builds the trie
+// from the stored balances. The caller holds trieMutex.
+func (s *State) loadTrie() (*mpt.MPT, error) {
+	if s.trie != nil {
+		return s.trie, nil
+	}
+	if s.trieSaved {
+		root, err := s.nodes.Root(stateRootName)
+		if err != nil {
+			return nil, err
+		}
+		if root != nil {
+			s.trie = mpt.OpenMPT(s.nodes, root)
+			return s.trie, nil
+		}
+	}
+	accounts, err := s.Accounts()
+	if err != nil {
+		return nil, err
+	}
+	trie := mpt.OpenMPT(s.nodes, nil)
+	if err := trie.Update(accountChanges(accounts)); err != nil {
+		return nil, err
+	}
+	s.trie = trie
+	return s.trie, nil
+}
+
+// saveTrie commits trie as the root of the balances after the block at
+// height, prunes the nodes of roots that are no longer kept, and writes the
+// nodes with the rest of batch. When anything fails nothing is written and
+// the trie is reloaded from the store when next needed. The caller holds
+// trieMutex.
+func (s *State) saveTrie(trie *mpt.MPT, height int64, batch storage.Batch) error {
+	root, err := trie.Commit()
+	if err == nil {
+		err = s.nodes.SetRoot(stateRootName, root)
+	}
+	if err == nil {
+		slot := (height%keptStateRoots + keptStateRoots) % keptStateRoots
+		err = s.nodes.SetRoot(fmt.Sprintf("%s/%d", stateRootName, slot), root)
+	}
+	if err == nil {
+		err = s.nodes.FlushWith(func(changes map[string][]byte) error {
+			storage.PutTrieChanges(batch, changes)
+			batch.Put(trieSavedKey, []byte{1})
+			return s.db.Write(batch)
+		})
+	}
+	if err != nil {
+		// Các thay đổi chưa ghi bị bỏ: mở lại database từ những gì đã lưu
+		s.nodes = mpt.NewDatabase(s.db.TrieStore())
+		s.trie = nil
+		s.trieSaved = false
+		return err
+	}
+	s.trie = trie
+	s.trieSaved = true
+	return nil
+}
+
+// unsaveTrie marks the saved trie as out of date before a balance changes.
+// The caller holds trieMutex.
+func (s *State) unsaveTrie() error {
+	if !s.trieSaved {
+		return nil
+	}
+	if err := s.db.Delete(trieSavedKey); err != nil {
+		return err
+	}
+	s.trieSaved = false
+	return nil
+}
+
+// ComputeStateRoot builds an MPT over address -> balance and returns its root.
+func ComputeStateRoot(accounts []Account) []byte {
+	trie := mpt.NewMPT()
+	trie.Update(accountChanges(accounts))
+	return trie.RootHash()
+}
+
+func accountChanges(accounts []Account) map[string][]byte {
+	changes := make(map[string][]byte, len(accounts))
+	for _, acc := range accounts {
+		changes[string(accountKey(acc.Address))] = balanceValue(acc.Balance)
+	}
+	return changes
+}
+
+// balanceValue is how a balance is stored, in the database and in the trie.
+func balanceValue(balance float64) []byte {
+	return []byte(strconv.FormatFloat(balance, 'f', -1, 64))
+}
+
+// accountKey is the trie key of an address: its raw bytes when it is hex.
+func accountKey(address string) []byte {
+	if key, err := hex.DecodeString(address); err == nil {
+		return key
+	}
+	return []byte(address)
+}
+
+// RestoreSnapshot replaces every balance with accounts and marks the state as
+// being at height. It is used by fast sync before any later block is applied.
+// Everything is written in one batch, so a crash never leaves part of the
+// snapshot.
+func (s *State) RestoreSnapshot(height int64, accounts []Account) error {
+	batch := s.db.NewBatch()
+	// Nothing before the snapshot can be reconstructed.
+	batch.Put(historyBelowKey, markerValue(height))
+	return s.replaceBalances(batch, height, accounts)
+}
+
+// RollBack replaces every balance with accounts, the balances right after the
+// block at height, and drops the diffs of later blocks. It is used to repair
+// the state after the chain has been truncated to height.
+func (s *State) RollBack(height int64, accounts []Account) error {
+	batch := s.db.NewBatch()
+	err := s.db.IterateRange(diffKey(height+1), storage.PrefixRange(storage.StateKey("diff/")).Limit, func(key, _ []byte) error {
+		batch.Delete(append([]byte(nil), key...))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return s.replaceBalances(batch, height, accounts)
+}
+
+// replaceBalances writes batch together with accounts in place of every
+// stored balance, height as the state height and the trie of accounts.
+func (s *State) replaceBalances(batch storage.Batch, height int64, accounts []Account) error {
+	keys, err := s.balanceKeys()
+	if err != nil {
+		return err
+	}
+	s.cacheMutex.Lock()
+	defer s.cacheMutex.Unlock()
+	s.trieMutex.Lock()
+	defer s.trieMutex.Unlock()
+
+	for _, key := range keys {
+		batch.Delete(key)
+	}
+	for _, acc := range accounts {
+		batch.Put([]byte(balancePrefix+acc.Address), balanceValue(acc.Balance))
+	}
+	batch.Put(heightKey, markerValue(height))
+	trie := mpt.OpenMPT(s.nodes, nil)
+	if err := trie.Update(accountChanges(accounts)); err != nil {
+		return err
+	}
+	// Cache có thể giữ số dư cũ, dù ghi thành công hay không
+	defer s.accounts.Purge()
+	return s.saveTrie(trie, height, batch)
+}
+
+func (s *State) balanceKeys() ([][]byte, error) {
+	var keys [][]byte
+	err := s.db.IteratePrefix([]byte(balancePrefix), func(key, _ []byte) error {
+		keys = append(keys, append([]byte(nil), key...))
+		return nil
+	})
+	return keys, err
+}
+
+func (s *State) clearBalances() error {
+	keys, err := s.balanceKeys()
+	if err != nil {
+		return err
+	}
+	defer s.accounts.Purge()
+	s.trieMutex.Lock()
+	defer s.trieMutex.Unlock()
+	if err := s.unsaveTrie(); err != nil {
+		return err
+	}
+	s.trie = nil
+	for _, key := range keys {
+		if err := s.db.Delete(key); err != nil {
+			return err
+		}
+	}
+	s.trie = mpt.OpenMPT(s.nodes, nil)
+	return nil
+}
+
+// GetBalanceAt returns the balance of address right after the block at height
+// was applied. It returns storage.ErrPruned when the history for that height
+// has been pruned.
+func (s *State) GetBalanceAt(address string, height int64) (float64, error) {
+	current, ok, err := s.Height()
+	if err != nil {
+		return 0, err
+	}
+	if !ok || height >= current {
+		return s.GetBalance(address)
+	}
+	below, err := s.HistoryBelow()
+	if err != nil {
+		return 0, err
+	}
+	if height < below {
+		return 0, fmt.Errorf("balance at height %d: %w", height, storage.ErrPruned)
+	}
+
+	// The first diff after height that touched the address holds its
+	// balance as of height; if none did, the balance has not changed since.
+	found := false
+	var balance float64
+	err = s.db.IterateRange(diffKey(height+1), diffKey(current+1), func(_, value []byte) error {
+		var diff map[string]float64
+		if err := json.Unmarshal(value, &diff); err != nil {
+			return fmt.Errorf("failed to decode state diff: %w", err)
+		}
+		if prev, ok := diff[address]; ok {
+			balance, found = prev, true
+			return errStopIteration
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStopIteration) {
+		return 0, err
+	}
+	if found {
+		return balance, nil
+	}
+	return s.GetBalance(address)
+}
+
+// HistoryBelow returns the lowest height GetBalanceAt can answer.
+func (s *State) HistoryBelow() (int64, error) {
+	below, _, err := s.getMarker(historyBelowKey)
+	return below, err
+}
+
+// PruneHistory deletes the diffs needed to answer queries below height.
+func (s *State) PruneHistory(height int64) (int, error) {
+	below, err := s.HistoryBelow()
+	if err != nil {
+		return 0, err
+	}
+	if height <= below {
+		return 0, nil
+	}
+	var keys [][]byte
+	err = s.db.IterateRange(diffKey(0), diffKey(height+1), func(key, _ []byte) error {
+		keys = append(keys, append([]byte(nil), key...))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, key := range keys {
+		if err := s.db.Delete(key); err != nil {
+			return 0, err
+		}
+	}
+	return len(keys), s.putMarker(historyBelowKey, height)
+}
+
+var errStopIteration = errors.New("stop iteration")
+
+// blockDiff encodes the balances the block is about to change, as they are
+// before the block is applied.
+func (s *State) blockDiff(block *blockchain.Block) ([]byte, error) {
+	diff := make(map[string]float64)
+	for _, tx := range block.Transactions {
+		addrs := []string{hex.EncodeToString(tx.Receiver)}
+		if string(tx.Sender) != "GENESIS" {
+			addrs = append(addrs, hex.EncodeToString(tx.Sender))
+		}
+		for _, addr := range addrs {
+			if _, ok := diff[addr]; ok {
+				continue
+			}
+			balance, err := s.GetBalance(addr)
+			if err != nil {
+				return nil, err
+			}
+			diff[addr] = balance
+		}
+	}
+	return json.Marshal(diff)
+}
+
+func diffKey(height int64) []byte {
+	return storage.StateKey("diff/" + storage.PaddedHeight(height))
+}
+
+// getMarker reads a height stored under key; ok is false when it is unset.
+func (s *State) getMarker(key []byte) (int64, bool, error) {
+	data, err := s.db.Get(key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	if len(data) != 8 {
+		return 0, false, fmt.Errorf("corrupted marker %s", key)
+	}
+	return int64(binary.BigEndian.Uint64(data)), true, nil
+}
+
+func (s *State) putMarker(key []byte, height int64) error {
+	return s.db.Put(key, markerValue(height))
+}
+
+func markerValue(height int64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(height))
+	return buf
+}
+
 // RebuildStateFromBlockchain quét toàn bộ blockchain để tính toán lại trạng thái số dư.
 func (s *State) RebuildStateFromBlockchain() error {
 	fmt.Println("Rebuilding state from blockchain...")
 
 	latestBlock, err := s.db.GetLatestBlock()
 	if err != nil {
-		if errors.Is(err, leveldb.ErrNotFound) {
+		if errors.Is(err, storage.ErrNotFound) {
 			fmt.Println("No blocks in DB, state is empty.")
 			return nil
 		}
 		return fmt.Errorf("failed to get latest block for state rebuild: %w", err)
 	}
 
-	for i := 0; i <= int(latestBlock.Height); i++ {
-		block, err := s.db.GetBlockByHeight(i)
+	// Balances are persisted, so only the blocks after the recorded state
+	// height are replayed. Without a recorded height the balances cannot be
+	// trusted and are rebuilt from genesis.
+	start := int64(0)
+	height, ok, err := s.Height()
+	if err != nil {
+		return fmt.Errorf("failed to read state height: %w", err)
+	}
+	if ok {
+		start = height + 1
+	} else if err := s.clearBalances(); err != nil {
+		return fmt.Errorf("failed to reset balances: %w", err)
+	}
+
+	for i := start; i <= latestBlock.Height; i++ {
+		block, err := s.db.GetBlockByHeight(int(i))
 		if err != nil {
 			return fmt.Errorf("failed to get block %d for state rebuild: %w", i, err)
 		}
 
-		for _, tx := range block.Transactions {
-
-			// // logs Debug thông tin giao dịch
-			// log.Printf("--- DEBUG [Rebuild]: Processing Tx in Block %d ---", block.Height)
-			// log.Printf("--- DEBUG [Rebuild]: Sender: %s", string(tx.Sender))
-			// log.Printf("--- DEBUG [Rebuild]: Receiver (hex): %s", hex.EncodeToString(tx.Receiver))
-			// log.Printf("--- DEBUG [Rebuild]: Amount: %f", tx.Amount)
-
-			if err := s.ApplyTransaction(tx); err != nil {
-				fmt.Printf("❌ Failed to apply tx during rebuild (block %d): %v\n", block.Height, err)
+		if err := s.ApplyBlock(block); err != nil {
+			if !errors.Is(err, ErrTransactionFailed) {
+				return fmt.Errorf("failed to apply block %d during rebuild: %w", block.Height, err)
 			}
+			fmt.Printf("❌ Failed to apply tx during rebuild (block %d): %v\n", block.Height, err)
 		}
 	}
 	fmt.Println("✅ State rebuild complete.")
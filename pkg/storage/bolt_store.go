@@ -0,0 +1,260 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// boltBucket is the single bucket every key lives in.
+var boltBucket = []byte("chain")
+
+// boltPageSize is how many entries a bolt iterator copies per read
+// transaction.
+const boltPageSize = 256
+
+// boltMmapSize is the initial memory map size. Bolt must remap to grow the
+// file and a remap waits for every open read transaction, so a large initial
+// map keeps writes from blocking behind snapshots.
+const boltMmapSize = 1 << 30
+
+// boltStore is the bbolt engine. Bolt keeps one B+tree file and gives
+// cheaper reads than LevelDB at the cost of slower random writes.
+type boltStore struct {
+	db *bolt.DB
+}
+
+// OpenBoltStore opens or creates a bolt database in the directory path.
+func OpenBoltStore(path string) (Store, error) {
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+	db, err := bolt.Open(filepath.Join(path, "chain.db"), 0o600, &bolt.Options{InitialMmapSize: boltMmapSize})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create bucket: %w", err)
+	}
+	return &boltStore{db: db}, nil
+}
+
+func (s *boltStore) Get(key []byte) ([]byte, error) {
+	var value []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(boltBucket).Get(key)
+		if v == nil {
+			return ErrNotFound
+		}
+		value = append([]byte(nil), v...)
+		return nil
+	})
+	return value, err
+}
+
+func (s *boltStore) Has(key []byte) (bool, error) {
+	_, err := s.Get(key)
+	if err == ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// NewIterator returns an iterator that reads boltPageSize entries per short
+// read transaction. Holding one transaction for the whole walk would stop
+// bolt from growing its file, and callers often write while iterating.
+func (s *boltStore) NewIterator(r *Range) Iterator {
+	it := &boltIterator{pos: -1, load: func(start []byte, skipStart bool) ([]batchOp, error) {
+		var page []batchOp
+		err := s.db.View(func(tx *bolt.Tx) error {
+			page = boltPage(tx, r, start, skipStart)
+			return nil
+		})
+		return page, err
+	}}
+	if r != nil {
+		it.next = r.Start
+	}
+	return it
+}
+
+func (s *boltStore) Put(key, value []byte) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).Put(key, value)
+	})
+}
+
+func (s *boltStore) Delete(key []byte) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).Delete(key)
+	})
+}
+
+func (s *boltStore) NewBatch() Batch {
+	return &memoryBatch{}
+}
+
+func (s *boltStore) Write(batch Batch) error {
+	b, ok := batch.(*memoryBatch)
+	if !ok {
+		return errForeignBatch(batch, Bolt)
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltBucket)
+		for _, op := range b.ops {
+			var err error
+			if op.delete {
+				err = bucket.Delete(op.key)
+			} else {
+				err = bucket.Put(op.key, op.value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// NewSnapshot opens a read transaction that lives until Release. Release it
+// promptly: once the file outgrows boltMmapSize, writes wait for it.
+func (s *boltStore) NewSnapshot() (Snapshot, error) {
+	tx, err := s.db.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+	return &boltSnapshot{tx: tx}, nil
+}
+
+// Compact is a no-op: bolt reuses freed pages instead of compacting.
+func (s *boltStore) Compact() error {
+	return nil
+}
+
+func (s *boltStore) Close() error {
+	return s.db.Close()
+}
+
+type boltSnapshot struct {
+	tx *bolt.Tx
+}
+
+func (s *boltSnapshot) Get(key []byte) ([]byte, error) {
+	v := s.tx.Bucket(boltBucket).Get(key)
+	if v == nil {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), v...), nil
+}
+
+func (s *boltSnapshot) Has(key []byte) (bool, error) {
+	return s.tx.Bucket(boltBucket).Get(key) != nil, nil
+}
+
+func (s *boltSnapshot) NewIterator(r *Range) Iterator {
+	it := &boltIterator{pos: -1, load: func(start []byte, skipStart bool) ([]batchOp, error) {
+		return boltPage(s.tx, r, start, skipStart), nil
+	}}
+	if r != nil {
+		it.next = r.Start
+	}
+	return it
+}
+
+func (s *boltSnapshot) Release() {
+	s.tx.Rollback()
+}
+
+// boltPage copies up to boltPageSize entries of r starting at start. When
+// skipStart is set the entry at start itself was already returned.
+func boltPage(tx *bolt.Tx, r *Range, start []byte, skipStart bool) []batchOp {
+	c := tx.Bucket(boltBucket).Cursor()
+	var k, v []byte
+	if start == nil {
+		k, v = c.First()
+	} else {
+		k, v = c.Seek(start)
+		if skipStart && k != nil && bytes.Equal(k, start) {
+			k, v = c.Next()
+		}
+	}
+
+	var page []batchOp
+	for ; k != nil && len(page) < boltPageSize; k, v = c.Next() {
+		if r != nil && r.Limit != nil && bytes.Compare(k, r.Limit) >= 0 {
+			break
+		}
+		page = append(page, batchOp{key: append([]byte(nil), k...), value: append([]byte(nil), v...)})
+	}
+	return page
+}
+
+type boltIterator struct {
+	load    func(start []byte, skipStart bool) ([]batchOp, error)
+	page    []batchOp
+	pos     int
+	next    []byte // where the next page starts
+	started bool
+	done    bool
+	err     error
+}
+
+func (it *boltIterator) Next() bool {
+	if it.done {
+		return false
+	}
+	it.pos++
+	if it.pos < len(it.page) {
+		return true
+	}
+	if it.started && len(it.page) < boltPageSize {
+		it.done = true
+		return false
+	}
+
+	page, err := it.load(it.next, it.started)
+	if err != nil {
+		it.err = err
+		it.done = true
+		return false
+	}
+	it.started = true
+	it.page, it.pos = page, 0
+	if len(page) == 0 {
+		it.done = true
+		return false
+	}
+	it.next = page[len(page)-1].key
+	return true
+}
+
+func (it *boltIterator) Key() []byte {
+	if it.done || it.pos < 0 || it.pos >= len(it.page) {
+		return nil
+	}
+	return it.page[it.pos].key
+}
+
+func (it *boltIterator) Value() []byte {
+	if it.done || it.pos < 0 || it.pos >= len(it.page) {
+		return nil
+	}
+	return it.page[it.pos].value
+}
+
+func (it *boltIterator) Release() {
+	it.page = nil
+	it.done = true
+}
+
+func (it *boltIterator) Error() error {
+	return it.err
+}
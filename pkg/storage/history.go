@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"blockchain-go/pkg/blockchain"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// Direction tells whether an address sent or received a transaction.
+type Direction byte
+
+const (
+	// AnyDirection matches both sent and received transactions in queries.
+	AnyDirection Direction = 0
+	Sent         Direction = 's'
+	Received     Direction = 'r'
+)
+
+// addressIndexRoot prefixes the address index:
+// idx/addr/<address>/<height>/<tx index>/<direction> → empty value.
+// The transaction is read from its block, so it is not stored twice.
+var addressIndexRoot = IndexPrefix + "addr/"
+
+// DefaultHistoryLimit and MaxHistoryLimit bound one page of account history.
+const (
+	DefaultHistoryLimit = 50
+	MaxHistoryLimit     = 500
+)
+
+// AddressTx is one transaction in the history of an address. Tx is nil when
+// the block body was pruned.
+type AddressTx struct {
+	Height    int64
+	Index     int
+	Direction Direction
+	Tx        *blockchain.Transaction
+}
+
+// HistoryQuery selects a page of an address's history. ToHeight < 0 means no
+// upper bound. Cursor is the NextCursor of the previous page.
+type HistoryQuery struct {
+	Address    string
+	Direction  Direction
+	FromHeight int64
+	ToHeight   int64
+	Cursor     []byte
+	Limit      int
+}
+
+// HistoryPage is a page of history, oldest first. NextCursor is empty on the
+// last page.
+type HistoryPage struct {
+	Entries    []AddressTx
+	NextCursor []byte
+}
+
+func addressIndexPrefix(address string) string {
+	return addressIndexRoot + address + "/"
+}
+
+func addressIndexKey(address string, height int64, index int, dir Direction) []byte {
+	return []byte(fmt.Sprintf("%s%s/%06d/%c", addressIndexPrefix(address), PaddedHeight(height), index, dir))
+}
+
+// parseAddressIndexKey returns the height, tx index and direction encoded in
+// an address index key.
+func parseAddressIndexKey(key []byte) (int64, int, Direction, error) {
+	var height int64
+	var index int
+	var dir Direction
+	rest := key[len(addressIndexRoot):]
+	slash := bytes.IndexByte(rest, '/')
+	if slash < 0 {
+		return 0, 0, 0, fmt.Errorf("corrupted history key %q", key)
+	}
+	if _, err := fmt.Sscanf(string(rest[slash+1:]), "%020d/%06d/%c", &height, &index, &dir); err != nil {
+		return 0, 0, 0, fmt.Errorf("corrupted history key %q: %w", key, err)
+	}
+	return height, index, dir, nil
+}
+
+// indexBlock adds the address index entries of block to batch. The GENESIS
+// pseudo-sender is not indexed.
+func indexBlock(batch Batch, block *blockchain.Block) {
+	for i, tx := range block.Transactions {
+		if string(tx.Sender) != "GENESIS" {
+			batch.Put(addressIndexKey(hex.EncodeToString(tx.Sender), block.Height, i, Sent), nil)
+		}
+		batch.Put(addressIndexKey(hex.EncodeToString(tx.Receiver), block.Height, i, Received), nil)
+	}
+}
+
+// historyTx returns the transaction an index entry points to. Blocks already
+// loaded for the page are kept in blocks. Entries written before the index
+// stopped storing transactions still carry one in value, which is used when
+// the body was pruned.
+func (d *DB) historyTx(blocks map[int64]*blockchain.Block, height int64, index int, value []byte) (*blockchain.Transaction, error) {
+	block, ok := blocks[height]
+	if !ok {
+		var err error
+		block, err = d.GetBlockByHeight(int(height))
+		if err != nil && !errors.Is(err, ErrPruned) {
+			return nil, fmt.Errorf("failed to load block %d: %w", height, err)
+		}
+		blocks[height] = block
+	}
+	if block == nil {
+		if len(value) == 0 {
+			return nil, nil
+		}
+		return blockchain.DecodeTransaction(value)
+	}
+	if index >= len(block.Transactions) {
+		return nil, fmt.Errorf("history entry %d/%d is past the end of the block", height, index)
+	}
+	return block.Transactions[index], nil
+}
+
+// AccountHistory returns one page of the transactions sent or received by an
+// address. The address is lowercase hex, as in the index.
+func (d *DB) AccountHistory(q HistoryQuery) (*HistoryPage, error) {
+	if q.Limit <= 0 {
+		q.Limit = DefaultHistoryLimit
+	}
+	if q.Limit > MaxHistoryLimit {
+		q.Limit = MaxHistoryLimit
+	}
+
+	prefix := addressIndexPrefix(q.Address)
+	r := &Range{Start: []byte(prefix + PaddedHeight(q.FromHeight)), Limit: PrefixRange([]byte(prefix)).Limit}
+	if q.ToHeight >= 0 {
+		r.Limit = []byte(prefix + PaddedHeight(q.ToHeight+1))
+	}
+	if len(q.Cursor) > 0 {
+		if !bytes.HasPrefix(q.Cursor, []byte(prefix)) {
+			return nil, fmt.Errorf("cursor does not belong to address %s", q.Address)
+		}
+		// Resume right after the last key of the previous page.
+		after := append(append([]byte(nil), q.Cursor...), 0)
+		if bytes.Compare(after, r.Start) > 0 {
+			r.Start = after
+		}
+	}
+
+	page := &HistoryPage{}
+	blocks := make(map[int64]*blockchain.Block)
+	var lastKey []byte
+	iter := d.db.NewIterator(r)
+	defer iter.Release()
+	for iter.Next() {
+		key := iter.Key()
+		height, index, dir, err := parseAddressIndexKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if q.Direction != AnyDirection && dir != q.Direction {
+			continue
+		}
+		if len(page.Entries) == q.Limit {
+			page.NextCursor = lastKey
+			break
+		}
+		tx, err := d.historyTx(blocks, height, index, iter.Value())
+		if err != nil {
+			return nil, err
+		}
+		page.Entries = append(page.Entries, AddressTx{Height: height, Index: index, Direction: dir, Tx: tx})
+		lastKey = append(lastKey[:0], key...)
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
@@ -0,0 +1,62 @@
+package storage
+
+import "fmt"
+
+// Key namespaces. Every key the node writes starts with one of these
+// prefixes so one kind of record can be iterated without touching the others.
+const (
+	// BlockPrefix: blk/<hash> → full block.
+	BlockPrefix = "blk/"
+	// HeaderPrefix: hdr/<hash> → block header and its transaction count.
+	HeaderPrefix = "hdr/"
+	// IndexPrefix: idx/height/<height> → block hash.
+	IndexPrefix = "idx/"
+	// StatePrefix: balances, state diffs, state markers and the state trie.
+	StatePrefix = "st/"
+	// SnapshotPrefix: state snapshot metadata and chunks.
+	SnapshotPrefix = "snap/"
+	// MetaPrefix: database-wide records such as the tip and schema version.
+	MetaPrefix = "meta/"
+)
+
+var (
+	latestKey        = MetaKey("latest")
+	heightIndexRoot  = IndexPrefix + "height/"
+	schemaVersionKey = MetaKey("schema-version")
+)
+
+// BlockKey is the key of the full block with the given hash.
+func BlockKey(hash []byte) []byte {
+	return append([]byte(BlockPrefix), hash...)
+}
+
+// HeaderKey is the key of the header of the block with the given hash.
+func HeaderKey(hash []byte) []byte {
+	return append([]byte(HeaderPrefix), hash...)
+}
+
+// HeightIndexKey is the height-to-hash index key. Heights are zero padded so
+// the index iterates in height order.
+func HeightIndexKey(height int64) []byte {
+	return []byte(heightIndexRoot + PaddedHeight(height))
+}
+
+// StateKey returns name inside the state namespace.
+func StateKey(name string) []byte {
+	return []byte(StatePrefix + name)
+}
+
+// SnapshotKey returns name inside the snapshot namespace.
+func SnapshotKey(name string) []byte {
+	return []byte(SnapshotPrefix + name)
+}
+
+// MetaKey returns name inside the metadata namespace.
+func MetaKey(name string) []byte {
+	return []byte(MetaPrefix + name)
+}
+
+// PaddedHeight formats a height so that keys containing it sort by height.
+func PaddedHeight(height int64) string {
+	return fmt.Sprintf("%020d", height)
+}
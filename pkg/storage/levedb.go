@@ -2,107 +2,336 @@ package storage
 
 import (
 	"blockchain-go/pkg/blockchain"
-	"encoding/json"
+	"bytes"
+	"errors"
 	"fmt"
-	"github.com/syndtr/goleveldb/leveldb"
+	"sync/atomic"
 )
 
+// DB stores the chain on top of any Store engine.
 type DB struct {
-	db *leveldb.DB
+	db Store
+
+	// Decoded headers and blocks by hash and block hashes by height. Saved
+	// blocks never change, so entries only go stale when blocks are pruned
+	// or truncated, which purge them.
+	headers *LRU[string, cachedHeader]
+	blocks  *LRU[string, *blockchain.Block]
+	heights *LRU[int64, []byte]
+	// prunedBelow caches the pruned-below marker; -1 means not loaded yet.
+	prunedBelow atomic.Int64
+}
+
+type cachedHeader struct {
+	header  *blockchain.Block
+	txCount int
+}
+
+// DBCacheStats reports the block caches of a DB.
+type DBCacheStats struct {
+	Blocks  CacheStats
+	Headers CacheStats
+	Heights CacheStats
+}
+
+func newDB(store Store) *DB {
+	d := &DB{
+		db:      store,
+		headers: NewLRU[string, cachedHeader](DefaultHeaderCacheSize),
+		blocks:  NewLRU[string, *blockchain.Block](DefaultBlockCacheSize),
+		heights: NewLRU[int64, []byte](DefaultHeaderCacheSize),
+	}
+	d.prunedBelow.Store(-1)
+	return d
+}
+
+// NewDB wraps an already opened store and upgrades its schema.
+func NewDB(store Store) (*DB, error) {
+	d := newDB(store)
+	if err := d.Migrate(); err != nil {
+		return nil, err
+	}
+	return d, nil
 }
 
-// OpenDB opens or creates the database at a given path.
+// OpenDB opens or creates a LevelDB database at a given path.
 func OpenDB(path string) (*DB, error) {
-	db, err := leveldb.OpenFile(path, nil)
+	return OpenDBWithEngine(LevelDB, path)
+}
+
+// OpenDBWithEngine opens or creates the database at path using engine.
+func OpenDBWithEngine(engine Engine, path string) (*DB, error) {
+	store, err := OpenStore(engine, path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
+		return nil, err
 	}
-	return &DB{db: db}, nil
+	d, err := NewDB(store)
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// OpenMemoryDB returns an empty database that lives in memory, for tests and
+// tools.
+func OpenMemoryDB() *DB {
+	d := newDB(NewMemoryStore())
+	d.setSchemaVersion(SchemaVersion)
+	return d
 }
 
 func (d *DB) SaveBlock(block *blockchain.Block) error {
-	key := block.CurrentBlockHash
-	value, err := json.Marshal(block)
+	hash := block.CurrentBlockHash
+	body, err := block.EncodeBody()
+	if err != nil {
+		return fmt.Errorf("failed to encode block body: %w", err)
+	}
+	header, err := encodeHeader(block, len(block.Transactions))
 	if err != nil {
-		return fmt.Errorf("failed to marshal block: %w", err)
+		return fmt.Errorf("failed to encode header: %w", err)
 	}
 
-	// Save the block by its hash
-	if err := d.db.Put(key, value, nil); err != nil {
+	// The body, the header, the latest pointer and the height and address
+	// indexes are written in one batch so a crash never leaves them out of
+	// step.
+	batch := d.db.NewBatch()
+	batch.Put(BlockKey(hash), body)
+	batch.Put(HeaderKey(hash), header)
+	batch.Put(latestKey, hash)
+	batch.Put(HeightIndexKey(block.Height), hash)
+	indexBlock(batch, block)
+	if err := d.db.Write(batch); err != nil {
 		return fmt.Errorf("failed to save block: %w", err)
 	}
 
-	// Save the latest block hash
-	if err := d.db.Put([]byte("latest"), key, nil); err != nil {
-		return fmt.Errorf("failed to update latest block: %w", err)
-	}
+	// Recent blocks are the ones peers and clients ask for.
+	cached := cloneBlock(block)
+	cached.Transactions = nil
+	d.headers.Add(string(hash), cachedHeader{header: cached, txCount: len(block.Transactions)})
+	d.blocks.Add(string(hash), cloneBlock(block))
+	d.heights.Add(block.Height, hash)
+	return nil
+}
+
+// SetCacheSizes changes how many decoded blocks and headers are cached. A
+// size of 0 disables the cache.
+func (d *DB) SetCacheSizes(blocks, headers int) {
+	d.blocks.Resize(blocks)
+	d.headers.Resize(headers)
+	d.heights.Resize(headers)
+}
+
+// CacheStats returns the hit and miss counters of the block caches.
+func (d *DB) CacheStats() DBCacheStats {
+	return DBCacheStats{Blocks: d.blocks.Stats(), Headers: d.headers.Stats(), Heights: d.heights.Stats()}
+}
 
-	// Save the height-to-hash index (e.g., "height-4" → blockHash)
-	heightKey := []byte(fmt.Sprintf("height-%d", block.Height))
-	if err := d.db.Put(heightKey, key, nil); err != nil {
-		return fmt.Errorf("failed to save height index: %w", err)
+// purgeCaches drops every cached block, header and height.
+func (d *DB) purgeCaches() {
+	d.headers.Purge()
+	d.blocks.Purge()
+	d.heights.Purge()
+	d.prunedBelow.Store(-1)
+}
+
+// invalidate purges the caches when key belongs to the chain data. It is
+// used by the raw Put and Delete, which bypass the block methods.
+func (d *DB) invalidate(key []byte) {
+	if isChainKey(key) {
+		d.purgeCaches()
 	}
+}
 
-	return nil
+func isChainKey(key []byte) bool {
+	return bytes.HasPrefix(key, []byte(BlockPrefix)) || bytes.HasPrefix(key, []byte(HeaderPrefix)) ||
+		bytes.HasPrefix(key, []byte(heightIndexRoot)) || bytes.Equal(key, prunedBelowKey)
+}
+
+// cloneBlock copies the block, its transaction list and the transactions, so
+// callers can set fields, attach transactions or requeue them without
+// changing a cached block. Byte slices (hashes, addresses, signatures) are
+// still shared and must not be written in place.
+func cloneBlock(block *blockchain.Block) *blockchain.Block {
+	c := *block
+	if block.Transactions != nil {
+		c.Transactions = make([]*blockchain.Transaction, len(block.Transactions))
+		for i, tx := range block.Transactions {
+			txCopy := *tx
+			c.Transactions[i] = &txCopy
+		}
+	}
+	return &c
 }
 
-// GetBlock retrieves a block by hash.
+// GetBlock retrieves a block by hash. It returns ErrPruned when the block's
+// transactions have been pruned; use GetHeader for the header alone.
 func (d *DB) GetBlock(hash []byte) (*blockchain.Block, error) {
-	value, err := d.db.Get(hash, nil)
+	header, _, err := d.getHeader(hash)
+	if err != nil {
+		return nil, err
+	}
+	prunedBelow, err := d.PrunedBelow()
+	if err != nil {
+		return nil, err
+	}
+	if header.Height < prunedBelow {
+		return nil, fmt.Errorf("block %d: %w", header.Height, ErrPruned)
+	}
+
+	if block, ok := d.blocks.Get(string(hash)); ok {
+		return cloneBlock(block), nil
+	}
+	value, err := d.db.Get(BlockKey(hash))
 	if err != nil {
-		return nil, fmt.Errorf("block not found: %w", err)
+		return nil, fmt.Errorf("block %d body not found: %w", header.Height, err)
 	}
-	var block blockchain.Block
-	if err := json.Unmarshal(value, &block); err != nil {
-		return nil, fmt.Errorf("failed to decode block: %w", err)
+	header.Transactions, err = blockchain.DecodeBody(value)
+	if err != nil {
+		return nil, err
 	}
-	return &block, nil
+	d.blocks.Add(string(hash), cloneBlock(header))
+	return header, nil
 }
 
 func (d *DB) GetLatestBlock() (*blockchain.Block, error) {
-	latestHash, err := d.db.Get([]byte("latest"), nil)
+	latestHash, err := d.db.Get(latestKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get latest block hash: %w", err)
 	}
 	return d.GetBlock(latestHash)
 }
 
+// GetAllBlocks returns every block whose body is still stored, in height
+// order.
 func (d *DB) GetAllBlocks() ([]*blockchain.Block, error) {
 	var blocks []*blockchain.Block
-	iter := d.db.NewIterator(nil, nil)
-	for iter.Next() {
-		key := iter.Key()
-		if string(key) == "latest" {
-			continue
+	err := d.IteratePrefix([]byte(heightIndexRoot), func(_, hash []byte) error {
+		block, err := d.GetBlock(hash)
+		if errors.Is(err, ErrPruned) {
+			return nil
 		}
-		val := iter.Value()
-		var block blockchain.Block
-		if err := json.Unmarshal(val, &block); err != nil {
-			return nil, err
+		if err != nil {
+			return err
 		}
-		blocks = append(blocks, &block)
-	}
-	iter.Release()
-	return blocks, iter.Error()
+		blocks = append(blocks, block)
+		return nil
+	})
+	return blocks, err
 }
 
 func (d *DB) GetBlockByHeight(height int) (*blockchain.Block, error) {
-	heightKey := []byte(fmt.Sprintf("height-%d", height))
-	hash, err := d.db.Get(heightKey, nil)
+	prunedBelow, err := d.PrunedBelow()
 	if err != nil {
-		return nil, fmt.Errorf("height index not found: %w", err)
+		return nil, err
+	}
+	if int64(height) < prunedBelow {
+		return nil, fmt.Errorf("block %d: %w", height, ErrPruned)
+	}
+	hash, err := d.hashAtHeight(int64(height))
+	if err != nil {
+		return nil, err
 	}
 	return d.GetBlock(hash)
 }
 
+func (d *DB) hashAtHeight(height int64) ([]byte, error) {
+	if hash, ok := d.heights.Get(height); ok {
+		return hash, nil
+	}
+	hash, err := d.db.Get(HeightIndexKey(height))
+	if err != nil {
+		return nil, fmt.Errorf("height index not found: %w", err)
+	}
+	d.heights.Add(height, hash)
+	return hash, nil
+}
+
 // Get retrieves a value by key.
 func (d *DB) Get(key []byte) ([]byte, error) {
-	return d.db.Get(key, nil)
+	return d.db.Get(key)
 }
 
 // Put saves a key-value pair.
 func (d *DB) Put(key, value []byte, options ...interface{}) error {
-	return d.db.Put(key, value, nil)
+	defer d.invalidate(key)
+	return d.db.Put(key, value)
+}
+
+// IteratePrefix calls fn for every key starting with prefix, in key order.
+// The slices passed to fn are only valid during the call.
+func (d *DB) IteratePrefix(prefix []byte, fn func(key, value []byte) error) error {
+	iter := d.db.NewIterator(PrefixRange(prefix))
+	defer iter.Release()
+	for iter.Next() {
+		if err := fn(iter.Key(), iter.Value()); err != nil {
+			return err
+		}
+	}
+	return iter.Error()
+}
+
+// IterateRange calls fn for every key in [start, limit), in key order.
+func (d *DB) IterateRange(start, limit []byte, fn func(key, value []byte) error) error {
+	iter := d.db.NewIterator(&Range{Start: start, Limit: limit})
+	defer iter.Release()
+	for iter.Next() {
+		if err := fn(iter.Key(), iter.Value()); err != nil {
+			return err
+		}
+	}
+	return iter.Error()
+}
+
+// Delete removes a key.
+func (d *DB) Delete(key []byte) error {
+	defer d.invalidate(key)
+	return d.db.Delete(key)
+}
+
+// NewBatch returns an empty batch for the underlying store.
+func (d *DB) NewBatch() Batch {
+	return &dbBatch{Batch: d.db.NewBatch()}
+}
+
+// Write applies a batch of NewBatch atomically. The block caches are purged
+// when the batch touches chain data.
+func (d *DB) Write(batch Batch) error {
+	b, ok := batch.(*dbBatch)
+	if !ok {
+		defer d.purgeCaches()
+		return d.db.Write(batch)
+	}
+	if b.chain {
+		defer d.purgeCaches()
+	}
+	return d.db.Write(b.Batch)
+}
+
+// dbBatch remembers whether any of its keys is chain data.
+type dbBatch struct {
+	Batch
+	chain bool
+}
+
+func (b *dbBatch) Put(key, value []byte) {
+	b.chain = b.chain || isChainKey(key)
+	b.Batch.Put(key, value)
+}
+
+func (b *dbBatch) Delete(key []byte) {
+	b.chain = b.chain || isChainKey(key)
+	b.Batch.Delete(key)
+}
+
+func (b *dbBatch) Reset() {
+	b.chain = false
+	b.Batch.Reset()
+}
+
+// NewSnapshot returns a consistent read-only view of the database.
+func (d *DB) NewSnapshot() (Snapshot, error) {
+	return d.db.NewSnapshot()
 }
 
 // Close closes the DB
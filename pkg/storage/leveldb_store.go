@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// levelStore is the LevelDB engine, the default for nodes.
+type levelStore struct {
+	db *leveldb.DB
+}
+
+// OpenLevelDBStore opens or creates a LevelDB database in the directory path.
+func OpenLevelDBStore(path string) (Store, error) {
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	return &levelStore{db: db}, nil
+}
+
+func (s *levelStore) Get(key []byte) ([]byte, error) {
+	value, err := s.db.Get(key, nil)
+	return value, levelError(err)
+}
+
+func (s *levelStore) Has(key []byte) (bool, error) {
+	return s.db.Has(key, nil)
+}
+
+func (s *levelStore) NewIterator(r *Range) Iterator {
+	return s.db.NewIterator(levelRange(r), nil)
+}
+
+func (s *levelStore) Put(key, value []byte) error {
+	return s.db.Put(key, value, nil)
+}
+
+func (s *levelStore) Delete(key []byte) error {
+	return s.db.Delete(key, nil)
+}
+
+func (s *levelStore) NewBatch() Batch {
+	return &levelBatch{}
+}
+
+func (s *levelStore) Write(batch Batch) error {
+	b, ok := batch.(*levelBatch)
+	if !ok {
+		return errForeignBatch(batch, LevelDB)
+	}
+	return s.db.Write(&b.Batch, nil)
+}
+
+func (s *levelStore) NewSnapshot() (Snapshot, error) {
+	snap, err := s.db.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &levelSnapshot{snap: snap}, nil
+}
+
+func (s *levelStore) Compact() error {
+	return s.db.CompactRange(util.Range{})
+}
+
+func (s *levelStore) Close() error {
+	return s.db.Close()
+}
+
+type levelBatch struct {
+	leveldb.Batch
+}
+
+type levelSnapshot struct {
+	snap *leveldb.Snapshot
+}
+
+func (s *levelSnapshot) Get(key []byte) ([]byte, error) {
+	value, err := s.snap.Get(key, nil)
+	return value, levelError(err)
+}
+
+func (s *levelSnapshot) Has(key []byte) (bool, error) {
+	return s.snap.Has(key, nil)
+}
+
+func (s *levelSnapshot) NewIterator(r *Range) Iterator {
+	return s.snap.NewIterator(levelRange(r), nil)
+}
+
+func (s *levelSnapshot) Release() {
+	s.snap.Release()
+}
+
+func levelRange(r *Range) *util.Range {
+	if r == nil {
+		return nil
+	}
+	return &util.Range{Start: r.Start, Limit: r.Limit}
+}
+
+// levelError maps LevelDB's not-found error to ErrNotFound.
+func levelError(err error) error {
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,208 @@
+package storage
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+)
+
+// memoryStore keeps everything in a map. It is meant for tests and tools that
+// do not need the data to survive a restart.
+type memoryStore struct {
+	mu   sync.RWMutex
+	data map[string][]byte
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() Store {
+	return &memoryStore{data: make(map[string][]byte)}
+}
+
+func (s *memoryStore) Get(key []byte) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.data[string(key)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), value...), nil
+}
+
+func (s *memoryStore) Has(key []byte) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.data[string(key)]
+	return ok, nil
+}
+
+func (s *memoryStore) NewIterator(r *Range) Iterator {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return newMemoryIterator(s.data, r)
+}
+
+func (s *memoryStore) Put(key, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[string(key)] = append([]byte(nil), value...)
+	return nil
+}
+
+func (s *memoryStore) Delete(key []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, string(key))
+	return nil
+}
+
+func (s *memoryStore) NewBatch() Batch {
+	return &memoryBatch{}
+}
+
+func (s *memoryStore) Write(batch Batch) error {
+	b, ok := batch.(*memoryBatch)
+	if !ok {
+		return errForeignBatch(batch, Memory)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, op := range b.ops {
+		if op.delete {
+			delete(s.data, string(op.key))
+		} else {
+			s.data[string(op.key)] = op.value
+		}
+	}
+	return nil
+}
+
+// NewSnapshot copies the whole map, which is fine for the data sizes the
+// memory store is used with.
+func (s *memoryStore) NewSnapshot() (Snapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data := make(map[string][]byte, len(s.data))
+	for k, v := range s.data {
+		data[k] = v
+	}
+	return &memorySnapshot{data: data}, nil
+}
+
+func (s *memoryStore) Compact() error {
+	return nil
+}
+
+func (s *memoryStore) Close() error {
+	return nil
+}
+
+type batchOp struct {
+	key, value []byte
+	delete     bool
+}
+
+// memoryBatch is also used by the bolt store.
+type memoryBatch struct {
+	ops []batchOp
+}
+
+func (b *memoryBatch) Put(key, value []byte) {
+	b.ops = append(b.ops, batchOp{key: append([]byte(nil), key...), value: append([]byte(nil), value...)})
+}
+
+func (b *memoryBatch) Delete(key []byte) {
+	b.ops = append(b.ops, batchOp{key: append([]byte(nil), key...), delete: true})
+}
+
+func (b *memoryBatch) Len() int {
+	return len(b.ops)
+}
+
+func (b *memoryBatch) Reset() {
+	b.ops = b.ops[:0]
+}
+
+// memorySnapshot is a private copy of the store's map; it is never written.
+type memorySnapshot struct {
+	data map[string][]byte
+}
+
+func (s *memorySnapshot) Get(key []byte) ([]byte, error) {
+	value, ok := s.data[string(key)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), value...), nil
+}
+
+func (s *memorySnapshot) Has(key []byte) (bool, error) {
+	_, ok := s.data[string(key)]
+	return ok, nil
+}
+
+func (s *memorySnapshot) NewIterator(r *Range) Iterator {
+	return newMemoryIterator(s.data, r)
+}
+
+func (s *memorySnapshot) Release() {}
+
+// memoryIterator iterates over a sorted copy of the keys in range taken when
+// it was created, so later writes do not affect it.
+type memoryIterator struct {
+	keys   []string
+	values [][]byte
+	pos    int
+}
+
+func newMemoryIterator(data map[string][]byte, r *Range) *memoryIterator {
+	it := &memoryIterator{pos: -1}
+	for k := range data {
+		if inRange([]byte(k), r) {
+			it.keys = append(it.keys, k)
+		}
+	}
+	sort.Strings(it.keys)
+	for _, k := range it.keys {
+		it.values = append(it.values, data[k])
+	}
+	return it
+}
+
+func (it *memoryIterator) Next() bool {
+	if it.pos < len(it.keys) {
+		it.pos++
+	}
+	return it.pos < len(it.keys)
+}
+
+func (it *memoryIterator) Key() []byte {
+	if it.pos < 0 || it.pos >= len(it.keys) {
+		return nil
+	}
+	return []byte(it.keys[it.pos])
+}
+
+func (it *memoryIterator) Value() []byte {
+	if it.pos < 0 || it.pos >= len(it.keys) {
+		return nil
+	}
+	return it.values[it.pos]
+}
+
+func (it *memoryIterator) Release() {
+	it.keys, it.values = nil, nil
+}
+
+func (it *memoryIterator) Error() error {
+	return nil
+}
+
+func inRange(key []byte, r *Range) bool {
+	if r == nil {
+		return true
+	}
+	if r.Start != nil && bytes.Compare(key, r.Start) < 0 {
+		return false
+	}
+	return r.Limit == nil || bytes.Compare(key, r.Limit) < 0
+}
@@ -0,0 +1,365 @@
+package storage
+
+import (
+	"blockchain-go/pkg/blockchain"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// SchemaVersion is the key layout written by this version of the code.
+const SchemaVersion = 4
+
+// migrationBatchSize bounds how many writes a migration keeps in one batch.
+const migrationBatchSize = 1000
+
+// migration upgrades the database from version-1 to version. Every migration
+// must be safe to run again after a crash halfway through.
+type migration struct {
+	version int
+	name    string
+	run     func(d *DB) error
+}
+
+var migrations = []migration{
+	{version: 1, name: "namespaced key schema", run: migrateNamespacedKeys},
+	{version: 2, name: "binary block encoding", run: migrateBinaryBlocks},
+	{version: 3, name: "header/body split", run: migrateBlockBodies},
+	{version: 4, name: "address index", run: migrateAddressIndex},
+}
+
+// SchemaVersion returns the version recorded in the database. A database
+// without a version record is 0 if it holds data and SchemaVersion if it is
+// empty.
+func (d *DB) SchemaVersion() (int, error) {
+	data, err := d.db.Get(schemaVersionKey)
+	if errors.Is(err, ErrNotFound) {
+		iter := d.db.NewIterator(nil)
+		empty := !iter.Next()
+		iter.Release()
+		if err := iter.Error(); err != nil {
+			return 0, err
+		}
+		if empty {
+			return SchemaVersion, nil
+		}
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	version, err := strconv.Atoi(string(data))
+	if err != nil {
+		return 0, fmt.Errorf("corrupted schema version %q", data)
+	}
+	return version, nil
+}
+
+// Migrate runs every migration newer than the database's schema version, in
+// order, recording the version after each one.
+func (d *DB) Migrate() error {
+	version, err := d.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if version > SchemaVersion {
+		return fmt.Errorf("database schema v%d is newer than supported v%d", version, SchemaVersion)
+	}
+
+	for _, m := range migrations {
+		if m.version <= version {
+			continue
+		}
+		log.Printf("🔧 Migrating database to schema v%d (%s)...", m.version, m.name)
+		if err := m.run(d); err != nil {
+			return fmt.Errorf("migration to v%d (%s) failed: %w", m.version, m.name, err)
+		}
+		// Migrations rewrite raw records behind the block caches.
+		d.purgeCaches()
+		if err := d.setSchemaVersion(m.version); err != nil {
+			return err
+		}
+		version = m.version
+	}
+	return d.setSchemaVersion(version)
+}
+
+func (d *DB) setSchemaVersion(version int) error {
+	if err := d.db.Put(schemaVersionKey, []byte(strconv.Itoa(version))); err != nil {
+		return fmt.Errorf("failed to record schema version: %w", err)
+	}
+	return nil
+}
+
+// legacyPrefixes maps the un-namespaced keys of schema v0 to their v1 keys.
+// Entries ending in "-" are prefixes, the others exact keys.
+var legacyPrefixes = []struct{ old, new string }{
+	{"latest", string(latestKey)},
+	{"pruned-below", string(prunedBelowKey)},
+	{"state-height", StatePrefix + "height"},
+	{"state-history-below", StatePrefix + "history-below"},
+	{"state-diff-", StatePrefix + "diff/"},
+	{"balance-", StatePrefix + "balance/"},
+	{"snapshot-meta-", SnapshotPrefix + "meta/"},
+	{"snapshot-chunk-", SnapshotPrefix + "chunk/"},
+}
+
+// jsonHeader is the v1 header record, a JSON block without transactions.
+type jsonHeader struct {
+	blockchain.Block
+	TxCount int
+}
+
+// legacyBlock is a v0 block record; pruned blocks kept only the header
+// fields and the number of transactions they had.
+type legacyBlock struct {
+	jsonHeader
+	PrunedTxCount int
+}
+
+// migrateNamespacedKeys moves the v0 keys, where blocks were keyed by their
+// raw hash next to "latest", "height-N" and "balance-<addr>", into the
+// namespaces of keys.go and splits every block into a header and a body.
+func migrateNamespacedKeys(d *DB) error {
+	snap, err := d.db.NewSnapshot()
+	if err != nil {
+		return err
+	}
+	defer snap.Release()
+
+	prunedBelow := int64(0)
+	if data, err := snap.Get([]byte("pruned-below")); err == nil && len(data) == 8 {
+		prunedBelow = int64(binary.BigEndian.Uint64(data))
+	}
+
+	batch := d.db.NewBatch()
+	flush := func() error {
+		if batch.Len() < migrationBatchSize {
+			return nil
+		}
+		err := d.db.Write(batch)
+		batch.Reset()
+		return err
+	}
+
+	moved, blocks := 0, 0
+	iter := snap.NewIterator(nil)
+	defer iter.Release()
+	for iter.Next() {
+		key, value := string(iter.Key()), iter.Value()
+		if isNamespaced(key) {
+			continue
+		}
+
+		switch newKey, ok := migrateLegacyKey(key); {
+		case ok:
+			batch.Put([]byte(newKey), value)
+			batch.Delete(iter.Key())
+			moved++
+		case len(key) == 32: // sha256 block hash
+			var stored legacyBlock
+			if err := json.Unmarshal(value, &stored); err != nil {
+				return fmt.Errorf("failed to decode legacy block %x: %w", key, err)
+			}
+			header := stored.jsonHeader
+			header.TxCount = len(stored.Transactions)
+			if stored.PrunedTxCount > 0 {
+				header.TxCount = stored.PrunedTxCount
+			}
+			if header.Height >= prunedBelow {
+				body, err := json.Marshal(stored.Block)
+				if err != nil {
+					return err
+				}
+				batch.Put(BlockKey(iter.Key()), body)
+			}
+			header.Block = stored.Block
+			header.Transactions = nil
+			data, err := json.Marshal(header)
+			if err != nil {
+				return err
+			}
+			batch.Put(HeaderKey(iter.Key()), data)
+			batch.Delete(iter.Key())
+			blocks++
+		default:
+			log.Printf("⚠️ Migration: leaving unknown key %q in place", key)
+			continue
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	if err := d.db.Write(batch); err != nil {
+		return err
+	}
+
+	log.Printf("✅ Migrated %d blocks and %d other keys to the namespaced schema", blocks, moved)
+	return nil
+}
+
+// migrateBinaryBlocks re-encodes the JSON block bodies and headers of v1 with
+// the binary encoding. Stored hashes are kept; blocks hashed with
+// the old JSON encoding are reported because peers will not accept them.
+func migrateBinaryBlocks(d *DB) error {
+	batch := d.db.NewBatch()
+	converted, legacyHashes := 0, 0
+	convert := func(key, value []byte, isHeader bool) error {
+		if len(value) == 0 || value[0] != '{' {
+			return nil // already binary
+		}
+		var stored jsonHeader
+		if err := json.Unmarshal(value, &stored); err != nil {
+			return fmt.Errorf("failed to decode JSON block %x: %w", key, err)
+		}
+		var data []byte
+		var err error
+		if isHeader {
+			data, err = encodeHeader(&stored.Block, stored.TxCount)
+		} else {
+			if !bytes.Equal(stored.Block.Hash(), stored.CurrentBlockHash) {
+				legacyHashes++
+			}
+			data, err = stored.Block.Encode()
+		}
+		if err != nil {
+			return err
+		}
+		batch.Put(key, data)
+		converted++
+		if batch.Len() >= migrationBatchSize {
+			err := d.db.Write(batch)
+			batch.Reset()
+			return err
+		}
+		return nil
+	}
+
+	err := d.IteratePrefix([]byte(BlockPrefix), func(key, value []byte) error {
+		return convert(key, value, false)
+	})
+	if err != nil {
+		return err
+	}
+	err = d.IteratePrefix([]byte(HeaderPrefix), func(key, value []byte) error {
+		return convert(key, value, true)
+	})
+	if err != nil {
+		return err
+	}
+	if err := d.db.Write(batch); err != nil {
+		return err
+	}
+
+	log.Printf("✅ Re-encoded %d block records", converted)
+	if legacyHashes > 0 {
+		log.Printf("⚠️ %d blocks were hashed with the old JSON encoding; peers will reject them. Recreate the data directory to join an up-to-date network.", legacyHashes)
+	}
+	return nil
+}
+
+// migrateBlockBodies strips the header fields from the v2 block records so
+// BlockKey holds only the transactions; the header lives under HeaderKey.
+func migrateBlockBodies(d *DB) error {
+	batch := d.db.NewBatch()
+	converted := 0
+	err := d.IteratePrefix([]byte(BlockPrefix), func(key, value []byte) error {
+		txs, err := blockchain.DecodeBody(value)
+		if err != nil {
+			return err
+		}
+		body, err := (&blockchain.Block{Transactions: txs}).EncodeBody()
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(body, value) {
+			return nil
+		}
+		batch.Put(key, body)
+		converted++
+		if batch.Len() >= migrationBatchSize {
+			err := d.db.Write(batch)
+			batch.Reset()
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := d.db.Write(batch); err != nil {
+		return err
+	}
+	log.Printf("✅ Split %d block bodies from their headers", converted)
+	return nil
+}
+
+// migrateAddressIndex builds the address index for the blocks saved before
+// it existed. Blocks whose bodies were pruned cannot be indexed.
+func migrateAddressIndex(d *DB) error {
+	batch := d.db.NewBatch()
+	indexed, skipped := 0, 0
+	err := d.IteratePrefix([]byte(heightIndexRoot), func(_, hash []byte) error {
+		block, err := d.GetBlock(hash)
+		if errors.Is(err, ErrPruned) {
+			skipped++
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		indexBlock(batch, block)
+		indexed++
+		if batch.Len() >= migrationBatchSize {
+			err := d.db.Write(batch)
+			batch.Reset()
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := d.db.Write(batch); err != nil {
+		return err
+	}
+	log.Printf("✅ Indexed the transactions of %d blocks by address", indexed)
+	if skipped > 0 {
+		log.Printf("⚠️ %d pruned blocks have no account history", skipped)
+	}
+	return nil
+}
+
+// migrateLegacyKey returns the v1 key for a v0 key other than a block.
+func migrateLegacyKey(key string) (string, bool) {
+	if rest, ok := strings.CutPrefix(key, "height-"); ok {
+		height, err := strconv.ParseInt(rest, 10, 64)
+		if err != nil {
+			return "", false
+		}
+		return string(HeightIndexKey(height)), true
+	}
+	for _, p := range legacyPrefixes {
+		if key == p.old || (strings.HasSuffix(p.old, "-") && strings.HasPrefix(key, p.old)) {
+			return p.new + key[len(p.old):], true
+		}
+	}
+	return "", false
+}
+
+func isNamespaced(key string) bool {
+	for _, prefix := range []string{BlockPrefix, HeaderPrefix, IndexPrefix, StatePrefix, SnapshotPrefix, MetaPrefix} {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"blockchain-go/pkg/blockchain"
+	"blockchain-go/proto/nodepb"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrPruned is returned for data that existed but was removed by pruning.
+var ErrPruned = errors.New("pruned")
+
+// prunedBelowKey stores the lowest height whose block body is still kept.
+var prunedBelowKey = MetaKey("pruned-below")
+
+// encodeHeader builds the record stored under HeaderKey: the block header
+// plus how many transactions the block has. Headers are never pruned.
+func encodeHeader(block *blockchain.Block, txCount int) ([]byte, error) {
+	header := blockchain.BlockToHeaderProto(block)
+	header.TxCount = int32(txCount)
+	return proto.MarshalOptions{Deterministic: true}.Marshal(header)
+}
+
+func decodeHeader(data []byte) (*blockchain.Block, int, error) {
+	var header nodepb.BlockHeader
+	if err := proto.Unmarshal(data, &header); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode header: %w", err)
+	}
+	return blockchain.ProtoToHeader(&header), int(header.TxCount), nil
+}
+
+// PrunedBelow returns the lowest height whose block body is available.
+func (d *DB) PrunedBelow() (int64, error) {
+	if height := d.prunedBelow.Load(); height >= 0 {
+		return height, nil
+	}
+	height, err := d.getHeightMarker(prunedBelowKey)
+	if err != nil {
+		return 0, err
+	}
+	d.prunedBelow.Store(height)
+	return height, nil
+}
+
+// SetPrunedBelow marks every block below height as pruned without touching
+// the stored data. Fast sync uses it because it never downloads those bodies.
+func (d *DB) SetPrunedBelow(height int64) error {
+	defer d.prunedBelow.Store(-1)
+	return d.putHeightMarker(prunedBelowKey, height)
+}
+
+// PruneBlocksBelow deletes the body of every block below height while
+// keeping its header and the hash/height indexes. It returns the number of
+// blocks pruned.
+func (d *DB) PruneBlocksBelow(height int64) (int, error) {
+	from, err := d.PrunedBelow()
+	if err != nil {
+		return 0, err
+	}
+
+	pruned := 0
+	batch := d.db.NewBatch()
+	for h := from; h < height; h++ {
+		hash, err := d.db.Get(HeightIndexKey(h))
+		if errors.Is(err, ErrNotFound) {
+			continue // never had this block (e.g. fast-synced node)
+		}
+		if err != nil {
+			return pruned, err
+		}
+		ok, err := d.db.Has(BlockKey(hash))
+		if err != nil {
+			return pruned, fmt.Errorf("failed to load block %d for pruning: %w", h, err)
+		}
+		if !ok {
+			continue
+		}
+		batch.Delete(BlockKey(hash))
+		pruned++
+	}
+
+	if height > from {
+		batch.Put(prunedBelowKey, heightBytes(height))
+	}
+	err = d.db.Write(batch)
+	d.blocks.Purge()
+	d.prunedBelow.Store(-1)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune blocks below %d: %w", height, err)
+	}
+	return pruned, nil
+}
+
+// GetHeader returns the header of the block with the given hash as a Block
+// without transactions. It works for pruned blocks too. The second result is
+// the number of transactions the block has.
+func (d *DB) GetHeader(hash []byte) (*blockchain.Block, int, error) {
+	return d.getHeader(hash)
+}
+
+// GetHeaderByHeight is the height-indexed variant of GetHeader.
+func (d *DB) GetHeaderByHeight(height int) (*blockchain.Block, int, error) {
+	hash, err := d.hashAtHeight(int64(height))
+	if err != nil {
+		return nil, 0, err
+	}
+	return d.getHeader(hash)
+}
+
+// GetLatestHeader returns the header of the tip without loading its body.
+func (d *DB) GetLatestHeader() (*blockchain.Block, int, error) {
+	latestHash, err := d.db.Get(latestKey)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get latest block hash: %w", err)
+	}
+	return d.getHeader(latestHash)
+}
+
+func (d *DB) getHeader(hash []byte) (*blockchain.Block, int, error) {
+	if cached, ok := d.headers.Get(string(hash)); ok {
+		return cloneBlock(cached.header), cached.txCount, nil
+	}
+	value, err := d.db.Get(HeaderKey(hash))
+	if err != nil {
+		return nil, 0, fmt.Errorf("block not found: %w", err)
+	}
+	header, txCount, err := decodeHeader(value)
+	if err != nil {
+		return nil, 0, err
+	}
+	d.headers.Add(string(hash), cachedHeader{header: cloneBlock(header), txCount: txCount})
+	return header, txCount, nil
+}
+
+// Compact compacts the whole database so space freed by pruning is returned.
+func (d *DB) Compact() error {
+	return d.db.Compact()
+}
+
+func (d *DB) getHeightMarker(key []byte) (int64, error) {
+	data, err := d.db.Get(key)
+	if errors.Is(err, ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 8 {
+		return 0, fmt.Errorf("corrupted marker %s", key)
+	}
+	return int64(binary.BigEndian.Uint64(data)), nil
+}
+
+func (d *DB) putHeightMarker(key []byte, height int64) error {
+	return d.db.Put(key, heightBytes(height))
+}
+
+func heightBytes(height int64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(height))
+	return buf
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotFound is returned by every Store when a key does not exist.
+var ErrNotFound = errors.New("key not found")
+
+// Reader is the read side shared by a Store and its snapshots.
+type Reader interface {
+	Get(key []byte) ([]byte, error)
+	Has(key []byte) (bool, error)
+	// NewIterator walks the keys in r (all keys when r is nil) in
+	// ascending byte order. The iterator must be released.
+	NewIterator(r *Range) Iterator
+}
+
+// Store is a key-value engine the chain database can run on.
+type Store interface {
+	Reader
+	Put(key, value []byte) error
+	Delete(key []byte) error
+	// NewBatch returns an empty batch; Write applies it atomically.
+	NewBatch() Batch
+	Write(batch Batch) error
+	// NewSnapshot returns a consistent read-only view of the store. It
+	// must be released.
+	NewSnapshot() (Snapshot, error)
+	// Compact reclaims space left by deleted keys, where the engine
+	// supports it.
+	Compact() error
+	Close() error
+}
+
+// Batch collects writes that are applied together.
+type Batch interface {
+	Put(key, value []byte)
+	Delete(key []byte)
+	Len() int
+	Reset()
+}
+
+// Iterator walks keys in ascending order. Key and Value are only valid until
+// the next call to Next.
+type Iterator interface {
+	Next() bool
+	Key() []byte
+	Value() []byte
+	Release()
+	Error() error
+}
+
+// Snapshot is a frozen view of a Store.
+type Snapshot interface {
+	Reader
+	Release()
+}
+
+// Range selects the keys in [Start, Limit). A nil bound is open.
+type Range struct {
+	Start []byte
+	Limit []byte
+}
+
+// PrefixRange returns the range of keys starting with prefix.
+func PrefixRange(prefix []byte) *Range {
+	var limit []byte
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] < 0xff {
+			limit = make([]byte, i+1)
+			copy(limit, prefix)
+			limit[i]++
+			break
+		}
+	}
+	return &Range{Start: prefix, Limit: limit}
+}
+
+// Engine names a Store implementation.
+type Engine string
+
+const (
+	LevelDB Engine = "leveldb"
+	Bolt    Engine = "bolt"
+	Memory  Engine = "memory"
+)
+
+// ParseEngine parses an engine name; an empty name means LevelDB.
+func ParseEngine(name string) (Engine, error) {
+	switch engine := Engine(strings.ToLower(name)); engine {
+	case "":
+		return LevelDB, nil
+	case LevelDB, Bolt, Memory:
+		return engine, nil
+	default:
+		return "", fmt.Errorf("unknown storage engine %q (use leveldb, bolt or memory)", name)
+	}
+}
+
+// OpenStore opens a store of the given engine in the directory path. The
+// memory engine ignores path.
+func OpenStore(engine Engine, path string) (Store, error) {
+	switch engine {
+	case LevelDB:
+		return OpenLevelDBStore(path)
+	case Bolt:
+		return OpenBoltStore(path)
+	case Memory:
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown storage engine %q", engine)
+	}
+}
+
+func errForeignBatch(batch Batch, engine Engine) error {
+	return fmt.Errorf("batch of type %T does not belong to a %s store", batch, engine)
+}
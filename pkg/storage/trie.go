@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"blockchain-go/pkg/mpt"
+	"errors"
+)
+
+// trieKeyPrefix holds the nodes and roots of the persistent state trie.
+var trieKeyPrefix = string(StateKey("trie/"))
+
+// TrieStore returns the database as the node store of an mpt.Database.
+func (d *DB) TrieStore() mpt.KeyValueStore {
+	return trieStore{store: d.db}
+}
+
+type trieStore struct {
+	store Store
+}
+
+func (s trieStore) Get(key []byte) ([]byte, error) {
+	value, err := s.store.Get(append([]byte(trieKeyPrefix), key...))
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	return value, err
+}
+
+func (s trieStore) Write(changes map[string][]byte) error {
+	batch := s.store.NewBatch()
+	PutTrieChanges(batch, changes)
+	return s.store.Write(batch)
+}
+
+// PutTrieChanges adds the changes flushed by the mpt.Database of TrieStore
+// to batch, so they can be written together with other data.
+func PutTrieChanges(batch Batch, changes map[string][]byte) {
+	for key, value := range changes {
+		if value == nil {
+			batch.Delete([]byte(trieKeyPrefix + key))
+		} else {
+			batch.Put([]byte(trieKeyPrefix+key), value)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"fmt"
+)
+
+// TruncateAbove deletes every block above height, with its header and index
+// entries, and moves the tip back to height. It is used to repair a database
+// whose newest blocks are inconsistent; the state must be rolled back
+// separately.
+func (d *DB) TruncateAbove(height int64) (int, error) {
+	keep, _, err := d.GetHeaderByHeight(int(height))
+	if err != nil {
+		return 0, fmt.Errorf("can not load block %d to keep: %w", height, err)
+	}
+
+	batch := d.db.NewBatch()
+	flush := func() error {
+		if batch.Len() < migrationBatchSize {
+			return nil
+		}
+		err := d.db.Write(batch)
+		batch.Reset()
+		return err
+	}
+
+	// Headers are scanned instead of the height index so blocks whose index
+	// entry was never written are removed too.
+	removed := 0
+	err = d.IteratePrefix([]byte(HeaderPrefix), func(key, value []byte) error {
+		header, _, err := decodeHeader(value)
+		if err != nil {
+			return fmt.Errorf("corrupted header %x: %w", key[len(HeaderPrefix):], err)
+		}
+		if header.Height <= height {
+			return nil
+		}
+		hash := key[len(HeaderPrefix):]
+		batch.Delete(HeaderKey(hash))
+		batch.Delete(BlockKey(hash))
+		removed++
+		return flush()
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	err = d.IterateRange(HeightIndexKey(height+1), PrefixRange([]byte(heightIndexRoot)).Limit, func(key, _ []byte) error {
+		batch.Delete(key)
+		return flush()
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	err = d.IteratePrefix([]byte(addressIndexRoot), func(key, _ []byte) error {
+		entryHeight, _, _, err := parseAddressIndexKey(key)
+		if err != nil {
+			return err
+		}
+		if entryHeight > height {
+			batch.Delete(key)
+		}
+		return flush()
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	batch.Put(latestKey, keep.CurrentBlockHash)
+	err = d.db.Write(batch)
+	d.purgeCaches()
+	if err != nil {
+		return 0, fmt.Errorf("failed to truncate chain: %w", err)
+	}
+	return removed, nil
+}
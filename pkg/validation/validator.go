@@ -2,8 +2,6 @@ package validation
 
 import (
 	"blockchain-go/pkg/blockchain"
-	"blockchain-go/pkg/cryptohelper"
-	"blockchain-go/pkg/mpt"
 	"blockchain-go/pkg/state"
 	"bytes"
 	"encoding/hex"
@@ -11,20 +9,34 @@ import (
 )
 
 func ValidateBlock(block *blockchain.Block, stateManager *state.State, latestBlock *blockchain.Block) error {
+	// 0. Block phải thuộc cùng chain với block trước (chain ID lấy từ genesis)
+	if latestBlock != nil && block.ChainID != latestBlock.ChainID {
+		return fmt.Errorf("block thuộc chain %q, node đang chạy chain %q", block.ChainID, latestBlock.ChainID)
+	}
+	// Kiểu tx root cũng được chọn một lần trong genesis
+	if !block.TxRootType.Valid() {
+		return fmt.Errorf("kiểu tx root không hợp lệ: %s", block.TxRootType)
+	}
+	if latestBlock != nil && block.TxRootType != latestBlock.TxRootType {
+		return fmt.Errorf("block dùng tx root %s, chain dùng %s", block.TxRootType, latestBlock.TxRootType)
+	}
+
 	// 1. Kiểm tra số dư và chữ ký của từng giao dịch
 	for _, tx := range block.Transactions {
-		// Bỏ qua giao dịch genesis
-		if string(tx.Sender) == "GENESIS" {
-			continue
+		// Chain ID nằm trong hash được ký, nên giao dịch của mạng khác không
+		// thể dùng lại ở đây
+		if tx.ChainID != block.ChainID {
+			return fmt.Errorf("giao dịch được ký cho chain %q, block thuộc chain %q", tx.ChainID, block.ChainID)
 		}
 
-		// Kiểm tra chữ ký
-		pubKey, err := cryptohelper.BytesToPublicKey(tx.PublicKey)
-		if err != nil {
-			return fmt.Errorf("lỗi chuyển đổi public key: %w", err)
+		// Bỏ qua giao dịch genesis, chỉ có trong block đầu tiên của chain
+		if string(tx.Sender) == "GENESIS" && latestBlock == nil {
+			continue
 		}
-		if !blockchain.VerifyTransaction(tx, pubKey) {
-			return fmt.Errorf("chữ ký không hợp lệ trong giao dịch")
+
+		// Kiểm tra chữ ký và địa chỉ người gửi phải là địa chỉ của public key
+		if err := blockchain.AuthenticateBlockTransaction(tx); err != nil {
+			return fmt.Errorf("giao dịch không hợp lệ: %w", err)
 		}
 
 		// Kiểm tra số dư
@@ -39,11 +51,7 @@ func ValidateBlock(block *blockchain.Block, stateManager *state.State, latestBlo
 	}
 
 	// 2. Kiểm tra Merkle Root
-	var txHashes [][]byte
-	for _, tx := range block.Transactions {
-		txHashes = append(txHashes, tx.Hash())
-	}
-	_, computedRoot := mpt.BuildMPTFromTxHashes(txHashes)
+	computedRoot := blockchain.TxRoot(block.TxRootType, block.Transactions)
 	if !bytes.Equal(computedRoot, block.MerkleRoot) {
 		return fmt.Errorf("merkle root không khớp (kỳ vọng %x, nhận được %x)", block.MerkleRoot, computedRoot)
 	}
@@ -55,5 +63,19 @@ func ValidateBlock(block *blockchain.Block, stateManager *state.State, latestBlo
 		}
 	}
 
+	// 4. Kiểm tra hash của header
+	if !bytes.Equal(block.Hash(), block.CurrentBlockHash) {
+		return fmt.Errorf("block hash không khớp với header")
+	}
+
+	// 5. Kiểm tra state root sau khi áp dụng các giao dịch
+	stateRoot, err := stateManager.PreviewRoot(block.Transactions)
+	if err != nil {
+		return fmt.Errorf("không thể tính state root: %w", err)
+	}
+	if !bytes.Equal(stateRoot, block.StateRoot) {
+		return fmt.Errorf("state root không khớp (kỳ vọng %x, nhận được %x)", block.StateRoot, stateRoot)
+	}
+
 	return nil
 }
@@ -0,0 +1,225 @@
+package wallet
+
+import (
+	"blockchain-go/pkg/cryptohelper"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// Dẫn xuất khoá phân cấp theo SLIP-10, tức BIP-32 cho mọi đường cong: từ một
+// seed ra khoá gốc, và từ mỗi khoá ra 2^32 khoá con. Khoá hardened (chỉ số >=
+// 2^31) chỉ dẫn xuất được từ khoá bí mật của cha; Ed25519 chỉ có khoá
+// hardened.
+
+// HardenedOffset is added to an index to derive a hardened child.
+const HardenedOffset uint32 = 1 << 31
+
+// CoinType is the BIP-44 coin type of account paths: 1, the SLIP-44 type
+// shared by all test networks.
+const CoinType = 1
+
+// ErrInvalidPath is returned for a derivation path that does not parse.
+var ErrInvalidPath = errors.New("invalid derivation path")
+
+// hdCurve is what SLIP-10 needs of the curve of a scheme.
+type hdCurve struct {
+	// seed is the HMAC key of the master key.
+	seed string
+	// order is the group order; nil for Ed25519, where any 32 bytes are a
+	// key and every child is hardened.
+	order *big.Int
+	// publicKey returns the compressed public key of a private key.
+	publicKey func(key []byte) []byte
+}
+
+var hdCurves = map[cryptohelper.SchemeID]hdCurve{
+	cryptohelper.P256: {
+		seed:  "Nist256p1 seed",
+		order: elliptic.P256().Params().N,
+		publicKey: func(key []byte) []byte {
+			x, y := elliptic.P256().ScalarBaseMult(key)
+			return elliptic.MarshalCompressed(elliptic.P256(), x, y)
+		},
+	},
+	cryptohelper.Secp256k1: {
+		seed:  "Bitcoin seed",
+		order: secp256k1.Params().N,
+		publicKey: func(key []byte) []byte {
+			return secp256k1.PrivKeyFromBytes(key).PubKey().SerializeCompressed()
+		},
+	},
+	cryptohelper.Ed25519: {seed: "ed25519 seed"},
+}
+
+// ExtendedKey is a private key with the chain code its children are derived
+// with.
+type ExtendedKey struct {
+	Scheme    cryptohelper.SchemeID
+	Key       []byte // 32 byte private key
+	ChainCode []byte
+}
+
+func lookupCurve(scheme cryptohelper.SchemeID) (hdCurve, error) {
+	curve, ok := hdCurves[scheme]
+	if !ok {
+		return hdCurve{}, fmt.Errorf("%w: %d", cryptohelper.ErrUnknownScheme, scheme)
+	}
+	return curve, nil
+}
+
+// validKey reports whether IL of a derivation is a usable key of the curve.
+func (c hdCurve) validKey(il *big.Int) bool {
+	return c.order == nil || (il.Sign() != 0 && il.Cmp(c.order) < 0)
+}
+
+// NewMasterKey returns the root key of seed for scheme. The seed must be 16
+// to 64 bytes.
+func NewMasterKey(scheme cryptohelper.SchemeID, seed []byte) (*ExtendedKey, error) {
+	curve, err := lookupCurve(scheme)
+	if err != nil {
+		return nil, err
+	}
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, fmt.Errorf("seed must be 16-64 bytes, not %d", len(seed))
+	}
+	data := seed
+	for {
+		mac := hmac.New(sha512.New, []byte(curve.seed))
+		mac.Write(data)
+		I := mac.Sum(nil)
+		// IL phải là một khoá hợp lệ, nếu không thì băm lại chính I
+		if curve.validKey(new(big.Int).SetBytes(I[:32])) {
+			return &ExtendedKey{Scheme: scheme, Key: I[:32], ChainCode: I[32:]}, nil
+		}
+		data = I
+	}
+}
+
+// Child derives the child at index; indexes from HardenedOffset up are
+// hardened.
+func (k *ExtendedKey) Child(index uint32) (*ExtendedKey, error) {
+	curve, err := lookupCurve(k.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	switch {
+	case index >= HardenedOffset:
+		data = append([]byte{0}, k.Key...)
+	case curve.order == nil:
+		return nil, fmt.Errorf("%w: %s keys only have hardened children", ErrInvalidPath, k.Scheme)
+	default:
+		data = curve.publicKey(k.Key)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	for {
+		mac := hmac.New(sha512.New, k.ChainCode)
+		mac.Write(data)
+		I := mac.Sum(nil)
+		if curve.order == nil {
+			return &ExtendedKey{Scheme: k.Scheme, Key: I[:32], ChainCode: I[32:]}, nil
+		}
+		il := new(big.Int).SetBytes(I[:32])
+		if il.Cmp(curve.order) < 0 {
+			child := il.Add(il, new(big.Int).SetBytes(k.Key))
+			child.Mod(child, curve.order)
+			if child.Sign() != 0 {
+				return &ExtendedKey{Scheme: k.Scheme, Key: child.FillBytes(make([]byte, 32)), ChainCode: I[32:]}, nil
+			}
+		}
+		// Xác suất gần như bằng 0: dẫn xuất lại với 0x01 || IR || index
+		data = binary.BigEndian.AppendUint32(append([]byte{1}, I[32:]...), index)
+	}
+}
+
+// Derive follows path, e.g. m/44'/1'/0'/0/0, from k. An index ending in '
+// or H is hardened.
+func (k *ExtendedKey) Derive(path string) (*ExtendedKey, error) {
+	indexes, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	key := k
+	for _, i := range indexes {
+		if key, err = key.Child(i); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// Wallet returns the wallet of the key.
+func (k *ExtendedKey) Wallet() (*Wallet, error) {
+	return walletFromKey(k.Scheme, k.Key)
+}
+
+// ParsePath parses a derivation path into its indexes.
+func ParsePath(path string) ([]uint32, error) {
+	parts := strings.Split(strings.TrimSpace(path), "/")
+	if parts[0] != "m" {
+		return nil, fmt.Errorf("%w %q: must start with m", ErrInvalidPath, path)
+	}
+	indexes := make([]uint32, 0, len(parts)-1)
+	for _, p := range parts[1:] {
+		hardened := strings.HasSuffix(p, "'") || strings.HasSuffix(p, "H") || strings.HasSuffix(p, "h")
+		if hardened {
+			p = p[:len(p)-1]
+		}
+		i, err := strconv.ParseUint(p, 10, 32)
+		if err != nil || i >= uint64(HardenedOffset) {
+			return nil, fmt.Errorf("%w %q: bad index %q", ErrInvalidPath, path, p)
+		}
+		if hardened {
+			i += uint64(HardenedOffset)
+		}
+		indexes = append(indexes, uint32(i))
+	}
+	return indexes, nil
+}
+
+// AccountPath returns the BIP-44 path of account index of scheme:
+// m/44'/1'/0'/0/<index>, or m/44'/1'/0'/0'/<index>' for Ed25519, whose
+// children are all hardened.
+func AccountPath(scheme cryptohelper.SchemeID, index uint32) string {
+	if scheme == cryptohelper.Ed25519 {
+		return fmt.Sprintf("m/44'/%d'/0'/0'/%d'", CoinType, index)
+	}
+	return fmt.Sprintf("m/44'/%d'/0'/0/%d", CoinType, index)
+}
+
+// DeriveWallet returns the wallet of scheme at path of a mnemonic, with no
+// BIP-39 passphrase. The wallet remembers the mnemonic and path, and
+// SaveToFile stores them encrypted so more accounts can be derived from the
+// file later.
+func DeriveWallet(scheme cryptohelper.SchemeID, mnemonic, path string) (*Wallet, error) {
+	seed, err := MnemonicToSeed(mnemonic, "")
+	if err != nil {
+		return nil, err
+	}
+	master, err := NewMasterKey(scheme, seed)
+	if err != nil {
+		return nil, err
+	}
+	key, err := master.Derive(path)
+	if err != nil {
+		return nil, err
+	}
+	w, err := key.Wallet()
+	if err != nil {
+		return nil, err
+	}
+	w.Mnemonic = strings.Join(strings.Fields(strings.ToLower(mnemonic)), " ")
+	w.Path = path
+	return w, nil
+}
@@ -0,0 +1,366 @@
+package wallet
+
+import (
+	"blockchain-go/pkg/cryptohelper"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+// A keystore file holds the private key encrypted with a passphrase:
+//
+//	{
+//	  "version": 1,
+//	  "address": "<20 byte hex>",
+//	  "scheme": "secp256k1",
+//	  "public_key": "<public key hex, as carried in transactions>",
+//	  "crypto": {
+//	    "cipher": "aes-256-gcm",
+//	    "ciphertext": "<hex>", "nonce": "<hex>", "mac": "<GCM tag hex>",
+//	    "kdf": "scrypt",
+//	    "kdfparams": { "n": 32768, "r": 8, "p": 1, "dklen": 32, "salt": "<hex>" }
+//	  },
+//	  "hd": { "path": "m/44'/1'/0'/0/0", "ciphertext": "<hex>", "nonce": "<hex>", "mac": "<hex>" }
+//	}
+//
+// The AES key is derived from the passphrase with scrypt. The address is
+// authenticated with the key, so a file whose address was edited does not
+// decrypt. The scheme is omitted for P-256. The optional hd section holds
+// the mnemonic of a derived wallet, sealed with the same AES key; the
+// address and path are authenticated with it.
+
+// KeystoreVersion is the version of the keystore format written by
+// SaveToFile.
+const KeystoreVersion = 1
+
+// Parameters of the scrypt KDF for new keystores: 32 MB and about 100ms per
+// derivation, the recommendation for interactive use.
+const (
+	ScryptN      = 1 << 15
+	ScryptR      = 8
+	ScryptP      = 1
+	scryptKeyLen = 32
+	saltLength   = 32
+)
+
+// maxScryptN bounds the cost read from a file, so a crafted keystore can not
+// make the loader allocate gigabytes.
+const maxScryptN = 1 << 20
+
+// Environment variables the passphrase of a keystore is read from.
+const (
+	PassphraseEnv     = "WALLET_PASSPHRASE"
+	PassphraseFileEnv = "WALLET_PASSPHRASE_FILE"
+)
+
+var (
+	// ErrWrongPassphrase is returned when a keystore does not decrypt with
+	// the given passphrase, or was tampered with.
+	ErrWrongPassphrase = errors.New("wrong passphrase or corrupted keystore")
+	// ErrNoPassphrase is returned when no passphrase is set and there is
+	// no terminal to ask for one.
+	ErrNoPassphrase = errors.New("no passphrase: set " + PassphraseEnv + " or " + PassphraseFileEnv)
+)
+
+type keystoreJSON struct {
+	Version   int        `json:"version"`
+	Address   string     `json:"address"`
+	Scheme    string     `json:"scheme,omitempty"`
+	PublicKey string     `json:"public_key"`
+	Crypto    cryptoJSON `json:"crypto"`
+	HD        *hdJSON    `json:"hd,omitempty"`
+}
+
+type cryptoJSON struct {
+	Cipher     string       `json:"cipher"`
+	CipherText string       `json:"ciphertext"`
+	Nonce      string       `json:"nonce"`
+	MAC        string       `json:"mac"`
+	KDF        string       `json:"kdf"`
+	KDFParams  scryptParams `json:"kdfparams"`
+}
+
+type hdJSON struct {
+	Path       string `json:"path"`
+	CipherText string `json:"ciphertext"`
+	Nonce      string `json:"nonce"`
+	MAC        string `json:"mac"`
+}
+
+type scryptParams struct {
+	N      int    `json:"n"`
+	R      int    `json:"r"`
+	P      int    `json:"p"`
+	KeyLen int    `json:"dklen"`
+	Salt   string `json:"salt"`
+}
+
+// EncryptKey returns the keystore file of w, encrypted with passphrase.
+func EncryptKey(w *Wallet, passphrase string) ([]byte, error) {
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	params := scryptParams{N: ScryptN, R: ScryptR, P: ScryptP, KeyLen: scryptKeyLen, Salt: hex.EncodeToString(salt)}
+	gcm, err := keystoreCipher(passphrase, params, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	// Khoá bí mật của mọi scheme dài 32 byte nên độ dài ciphertext không lộ gì
+	privBytes := w.Key.Bytes()
+	sealed := gcm.Seal(nil, nonce, privBytes, []byte(w.Address))
+	tag := len(sealed) - gcm.Overhead()
+
+	var hd *hdJSON
+	if w.Mnemonic != "" {
+		hdNonce := make([]byte, gcm.NonceSize())
+		if _, err := rand.Read(hdNonce); err != nil {
+			return nil, err
+		}
+		sealed := gcm.Seal(nil, hdNonce, []byte(w.Mnemonic), []byte(w.Address+w.Path))
+		tag := len(sealed) - gcm.Overhead()
+		hd = &hdJSON{
+			Path:       w.Path,
+			CipherText: hex.EncodeToString(sealed[:tag]),
+			Nonce:      hex.EncodeToString(hdNonce),
+			MAC:        hex.EncodeToString(sealed[tag:]),
+		}
+	}
+
+	scheme := ""
+	if w.Scheme() != cryptohelper.P256 {
+		scheme = w.Scheme().String()
+	}
+
+	return json.MarshalIndent(keystoreJSON{
+		Version:   KeystoreVersion,
+		Address:   w.Address,
+		Scheme:    scheme,
+		PublicKey: hex.EncodeToString(w.Key.PublicKey()),
+		Crypto: cryptoJSON{
+			Cipher:     "aes-256-gcm",
+			CipherText: hex.EncodeToString(sealed[:tag]),
+			Nonce:      hex.EncodeToString(nonce),
+			MAC:        hex.EncodeToString(sealed[tag:]),
+			KDF:        "scrypt",
+			KDFParams:  params,
+		},
+		HD: hd,
+	}, "", "  ")
+}
+
+// DecryptKey returns the wallet stored in a keystore file.
+func DecryptKey(data []byte, passphrase string) (*Wallet, error) {
+	var ks keystoreJSON
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return nil, fmt.Errorf("invalid keystore format: %w", err)
+	}
+	if ks.Version != KeystoreVersion {
+		return nil, fmt.Errorf("unsupported keystore version %d", ks.Version)
+	}
+	scheme, err := cryptohelper.ParseScheme(ks.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	c := ks.Crypto
+	if c.Cipher != "aes-256-gcm" || c.KDF != "scrypt" {
+		return nil, fmt.Errorf("unsupported keystore cipher %q with kdf %q", c.Cipher, c.KDF)
+	}
+	p := c.KDFParams
+	if p.N <= 1 || p.N > maxScryptN || p.N&(p.N-1) != 0 || p.R <= 0 || p.P <= 0 || p.R*p.P >= 1<<30 || p.KeyLen != scryptKeyLen {
+		return nil, fmt.Errorf("invalid scrypt parameters n=%d r=%d p=%d dklen=%d", p.N, p.R, p.P, p.KeyLen)
+	}
+	salt, err1 := hex.DecodeString(p.Salt)
+	cipherText, err2 := hex.DecodeString(c.CipherText)
+	nonce, err3 := hex.DecodeString(c.Nonce)
+	mac, err4 := hex.DecodeString(c.MAC)
+	if err := errors.Join(err1, err2, err3, err4); err != nil {
+		return nil, fmt.Errorf("invalid keystore encoding: %w", err)
+	}
+
+	gcm, err := keystoreCipher(passphrase, p, salt)
+	if err != nil {
+		return nil, err
+	}
+	privBytes, err := openSealed(gcm, cipherText, nonce, mac, []byte(ks.Address))
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := walletFromKey(scheme, privBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keystore key: %w", err)
+	}
+	if w.Address != ks.Address {
+		return nil, fmt.Errorf("keystore address %s does not match its key (%s)", ks.Address, w.Address)
+	}
+	if ks.HD != nil {
+		if err := openMnemonic(gcm, ks.HD, w); err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// openSealed opens a ciphertext whose GCM tag is stored apart as mac.
+func openSealed(gcm cipher.AEAD, cipherText, nonce, mac, additional []byte) ([]byte, error) {
+	if len(nonce) != gcm.NonceSize() || len(mac) != gcm.Overhead() {
+		return nil, fmt.Errorf("invalid keystore nonce or mac length")
+	}
+	plain, err := gcm.Open(nil, nonce, append(cipherText, mac...), additional)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plain, nil
+}
+
+// openMnemonic decrypts the hd section into w and checks that the mnemonic
+// really derives w at the stored path.
+func openMnemonic(gcm cipher.AEAD, hd *hdJSON, w *Wallet) error {
+	cipherText, err1 := hex.DecodeString(hd.CipherText)
+	nonce, err2 := hex.DecodeString(hd.Nonce)
+	mac, err3 := hex.DecodeString(hd.MAC)
+	if err := errors.Join(err1, err2, err3); err != nil {
+		return fmt.Errorf("invalid keystore hd encoding: %w", err)
+	}
+	mnemonic, err := openSealed(gcm, cipherText, nonce, mac, []byte(w.Address+hd.Path))
+	if err != nil {
+		return err
+	}
+	derived, err := DeriveWallet(w.Scheme(), string(mnemonic), hd.Path)
+	if err != nil {
+		return fmt.Errorf("invalid keystore mnemonic: %w", err)
+	}
+	if derived.Address != w.Address {
+		return fmt.Errorf("keystore mnemonic derives %s at %s, not %s", derived.Address, hd.Path, w.Address)
+	}
+	w.Mnemonic, w.Path = derived.Mnemonic, derived.Path
+	return nil
+}
+
+func keystoreCipher(passphrase string, p scryptParams, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, p.N, p.R, p.P, p.KeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// walletFromKey builds the wallet of a 32 byte private key of scheme.
+func walletFromKey(scheme cryptohelper.SchemeID, d []byte) (*Wallet, error) {
+	s, err := cryptohelper.LookupScheme(scheme)
+	if err != nil {
+		return nil, err
+	}
+	key, err := s.PrivateKeyFromBytes(d)
+	if err != nil {
+		return nil, err
+	}
+	return walletFromPrivateKey(key)
+}
+
+func walletFromPrivateKey(key cryptohelper.PrivateKey) (*Wallet, error) {
+	s, err := cryptohelper.LookupScheme(key.Scheme())
+	if err != nil {
+		return nil, err
+	}
+	address, err := s.Address(key.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+	w := &Wallet{Key: key, Address: hex.EncodeToString(address)}
+	if p, ok := key.(cryptohelper.P256PrivateKey); ok {
+		w.PrivateKey, w.PublicKey = p.Key, &p.Key.PublicKey
+	}
+	return w, nil
+}
+
+// IsEncrypted reports whether data is a keystore file rather than an old
+// plaintext wallet.
+func IsEncrypted(data []byte) bool {
+	var probe struct {
+		Version int             `json:"version"`
+		Crypto  json.RawMessage `json:"crypto"`
+	}
+	return json.Unmarshal(data, &probe) == nil && probe.Version > 0 && len(probe.Crypto) > 0
+}
+
+// writeFile writes data next to path and renames it over path, so a crash
+// never leaves a half written wallet.
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// ReadPassphrase returns the passphrase of a keystore: from WALLET_PASSPHRASE,
+// else from the file named by WALLET_PASSPHRASE_FILE (without its trailing
+// newline), else typed on the terminal after prompt. With confirm, a typed
+// passphrase must be entered twice and may not be empty.
+func ReadPassphrase(prompt string, confirm bool) (string, error) {
+	if pass, ok := os.LookupEnv(PassphraseEnv); ok {
+		return pass, nil
+	}
+	if path := os.Getenv(PassphraseFileEnv); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", ErrNoPassphrase
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	pass, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	if !confirm {
+		return string(pass), nil
+	}
+	if len(pass) == 0 {
+		return "", errors.New("empty passphrase")
+	}
+	fmt.Fprint(os.Stderr, "Repeat passphrase: ")
+	again, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	if string(again) != string(pass) {
+		return "", errors.New("passphrases do not match")
+	}
+	return string(pass), nil
+}
@@ -0,0 +1,162 @@
+package wallet
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Mnemonic theo BIP-39: entropy 128-256 bit cộng checksum là SHA-256 của
+// entropy (1 bit cho mỗi 32 bit), chia thành các nhóm 11 bit, mỗi nhóm là một
+// từ trong danh sách 2048 từ tiếng Anh. Seed được dẫn xuất từ câu mnemonic
+// bằng PBKDF2-HMAC-SHA512, nên mọi ví BIP-39 khác cũng ra cùng một seed.
+
+// englishWords is the BIP-39 English wordlist, byte for byte the file of the
+// specification (sha256 2f5eed53a4727b4bf8880d8f3f199efc90e58503646d9ff8eff3a2ed3b24dbda).
+//
+//go:embed wordlist_english.txt
+var englishWords string
+
+var (
+	wordlist  = strings.Fields(englishWords)
+	wordIndex = make(map[string]int, len(wordlist))
+)
+
+func init() {
+	if len(wordlist) != 2048 {
+		panic(fmt.Sprintf("wallet: wordlist has %d words, want 2048", len(wordlist)))
+	}
+	for i, w := range wordlist {
+		wordIndex[w] = i
+	}
+}
+
+// MnemonicEnv is the environment variable a mnemonic is read from.
+const MnemonicEnv = "WALLET_MNEMONIC"
+
+// seedIterations is the PBKDF2 round count fixed by BIP-39.
+const seedIterations = 2048
+
+var (
+	// ErrInvalidMnemonic is returned for a mnemonic with an unknown word or
+	// a wrong number of words.
+	ErrInvalidMnemonic = errors.New("invalid mnemonic")
+	// ErrMnemonicChecksum is returned when the words are valid but the
+	// checksum does not match, usually a mistyped or swapped word.
+	ErrMnemonicChecksum = errors.New("mnemonic checksum mismatch")
+)
+
+// NewMnemonic returns a random mnemonic of words words: 12, 15, 18, 21 or 24.
+func NewMnemonic(words int) (string, error) {
+	if words < 12 || words > 24 || words%3 != 0 {
+		return "", fmt.Errorf("mnemonic must have 12, 15, 18, 21 or 24 words, not %d", words)
+	}
+	entropy := make([]byte, words/3*4)
+	if _, err := rand.Read(entropy); err != nil {
+		return "", fmt.Errorf("failed to generate entropy: %w", err)
+	}
+	return EntropyToMnemonic(entropy)
+}
+
+// EntropyToMnemonic encodes 16 to 32 bytes of entropy, a multiple of 4, as
+// a mnemonic.
+func EntropyToMnemonic(entropy []byte) (string, error) {
+	n := len(entropy)
+	if n < 16 || n > 32 || n%4 != 0 {
+		return "", fmt.Errorf("entropy must be 16-32 bytes and a multiple of 4, not %d", n)
+	}
+	checksumBits := n / 4
+	hash := sha256.Sum256(entropy)
+
+	// Dồn entropy và checksum thành một số rồi cắt từ bit thấp lên
+	b := new(big.Int).SetBytes(entropy)
+	b.Lsh(b, uint(checksumBits))
+	b.Or(b, big.NewInt(int64(hash[0]>>(8-checksumBits))))
+
+	words := make([]string, (n*8+checksumBits)/11)
+	mask := big.NewInt(2047)
+	for i := len(words) - 1; i >= 0; i-- {
+		words[i] = wordlist[new(big.Int).And(b, mask).Int64()]
+		b.Rsh(b, 11)
+	}
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicToEntropy decodes a mnemonic and checks its checksum.
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(strings.ToLower(mnemonic))
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return nil, fmt.Errorf("%w: %d words", ErrInvalidMnemonic, len(words))
+	}
+	b := new(big.Int)
+	for _, w := range words {
+		i, ok := wordIndex[w]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown word %q", ErrInvalidMnemonic, w)
+		}
+		b.Lsh(b, 11)
+		b.Or(b, big.NewInt(int64(i)))
+	}
+
+	checksumBits := len(words) / 3
+	checksum := new(big.Int).And(b, big.NewInt(1<<checksumBits-1)).Int64()
+	b.Rsh(b, uint(checksumBits))
+	entropy := b.FillBytes(make([]byte, checksumBits*4))
+	hash := sha256.Sum256(entropy)
+	if int64(hash[0]>>(8-checksumBits)) != checksum {
+		return nil, ErrMnemonicChecksum
+	}
+	return entropy, nil
+}
+
+// ValidateMnemonic reports why mnemonic is not a valid BIP-39 mnemonic.
+func ValidateMnemonic(mnemonic string) error {
+	_, err := MnemonicToEntropy(mnemonic)
+	return err
+}
+
+// MnemonicToSeed returns the 64 byte seed of a valid mnemonic. passphrase is
+// the optional BIP-39 passphrase; a different passphrase gives a different,
+// equally valid seed.
+func MnemonicToSeed(mnemonic, passphrase string) ([]byte, error) {
+	if err := ValidateMnemonic(mnemonic); err != nil {
+		return nil, err
+	}
+	sentence := strings.Join(strings.Fields(strings.ToLower(mnemonic)), " ")
+	salt := "mnemonic" + norm.NFKD.String(passphrase)
+	return pbkdf2.Key(sha512.New, norm.NFKD.String(sentence), []byte(salt), seedIterations, 64)
+}
+
+// ReadMnemonic returns a mnemonic from WALLET_MNEMONIC, else typed on the
+// terminal after prompt without echo. The mnemonic is validated.
+func ReadMnemonic(prompt string) (string, error) {
+	mnemonic, ok := os.LookupEnv(MnemonicEnv)
+	if !ok {
+		fd := int(os.Stdin.Fd())
+		if !term.IsTerminal(fd) {
+			return "", errors.New("no mnemonic: set " + MnemonicEnv)
+		}
+		fmt.Fprint(os.Stderr, prompt)
+		typed, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", err
+		}
+		mnemonic = string(typed)
+	}
+	mnemonic = strings.Join(strings.Fields(strings.ToLower(mnemonic)), " ")
+	if err := ValidateMnemonic(mnemonic); err != nil {
+		return "", err
+	}
+	return mnemonic, nil
+}
@@ -2,9 +2,9 @@ package wallet
 
 import (
 	"blockchain-go/pkg/blockchain"
+	"blockchain-go/pkg/cryptohelper"
 	"blockchain-go/proto/nodepb"
 	"crypto/ecdsa"
-	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/sha256"
 	"fmt"
@@ -17,16 +17,25 @@ import (
 // 	return hash[:]
 // }
 
+// SignTransaction signs tx with a P-256 key.
 func SignTransaction(tx *blockchain.Transaction, privKey *ecdsa.PrivateKey) error {
-	hash := tx.Hash()
-	r, s, err := ecdsa.Sign(rand.Reader, privKey, hash)
+	return signTransaction(tx, cryptohelper.P256PrivateKey{Key: privKey})
+}
+
+// SignTransaction signs tx with the key of the wallet, under its scheme.
+func (w *Wallet) SignTransaction(tx *blockchain.Transaction) error {
+	return signTransaction(tx, w.Key)
+}
+
+func signTransaction(tx *blockchain.Transaction, key cryptohelper.PrivateKey) error {
+	// Scheme nằm trong hash được ký, nên phải gán trước khi băm
+	tx.SignatureScheme = key.Scheme()
+	tx.PublicKey = key.PublicKey()
+	sig, err := key.Sign(tx.Hash())
 	if err != nil {
 		return err
 	}
-	tx.Signature = append(r.Bytes(), s.Bytes()...)
-
-	tx.PublicKey = elliptic.Marshal(privKey.PublicKey.Curve, privKey.PublicKey.X, privKey.PublicKey.Y)
-	// fmt.Printf("🧪 Generated PublicKey: %x\n", tx.PublicKey)
+	tx.Signature = sig
 	return nil
 }
 
@@ -1,25 +1,35 @@
 package wallet
 
 import (
+	"blockchain-go/pkg/cryptohelper"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
-	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"math/big"
+	"log"
 	"os"
-	"path/filepath"
 )
 
 type Wallet struct {
+	// Key signs for the wallet with its signature scheme.
+	Key cryptohelper.PrivateKey
+	// PrivateKey and PublicKey are the ECDSA keys of a P-256 wallet, nil
+	// with the other schemes.
 	PrivateKey *ecdsa.PrivateKey
 	PublicKey  *ecdsa.PublicKey
 	Address    string
+
+	// Mnemonic and Path are set on a wallet derived with DeriveWallet and
+	// empty on a random one.
+	Mnemonic string
+	Path     string
 }
 
+// WalletJSON is the old plaintext wallet file, still read by LoadWallet and
+// MigrateFile.
 type WalletJSON struct {
 	PrivateKey string `json:"private_key"`
 	PublicKey  string `json:"public_key"`
@@ -36,53 +46,80 @@ func CreateWallet() (*Wallet, error) {
 	pub := &priv.PublicKey
 	address := PublicKeyToAddress(pub)
 	return &Wallet{
+		Key:        cryptohelper.P256PrivateKey{Key: priv},
 		PrivateKey: priv,
 		PublicKey:  pub,
 		Address:    address,
 	}, nil
 }
 
-// Convert public key to address (20 bytes from SHA256 hash)
-func PublicKeyToAddress(pub *ecdsa.PublicKey) string {
-	pubBytes := append(pub.X.Bytes(), pub.Y.Bytes()...)
-	hash := sha256.Sum256(pubBytes)
-	return hex.EncodeToString(hash[len(hash)-20:])
+// NewWallet generates a wallet with a new key of scheme.
+func NewWallet(scheme cryptohelper.SchemeID) (*Wallet, error) {
+	s, err := cryptohelper.LookupScheme(scheme)
+	if err != nil {
+		return nil, err
+	}
+	key, err := s.GenerateKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	return walletFromPrivateKey(key)
 }
 
-// Save wallet to JSON file
-func (w *Wallet) SaveToFile(filePath string) error {
-
-	privBytes := w.PrivateKey.D.Bytes()
+// Scheme returns the signature scheme of the wallet.
+func (w *Wallet) Scheme() cryptohelper.SchemeID {
+	return w.Key.Scheme()
+}
 
-	pubKeyBytes := append(w.PublicKey.X.Bytes(), w.PublicKey.Y.Bytes()...)
-	pubKeyHex := hex.EncodeToString(pubKeyBytes)
+// Convert public key to address (20 bytes from SHA256 hash)
+func PublicKeyToAddress(pub *ecdsa.PublicKey) string {
+	return hex.EncodeToString(cryptohelper.PublicKeyToAddress(pub))
+}
 
-	jsonData := WalletJSON{
-		PrivateKey: hex.EncodeToString(privBytes),
-		PublicKey:  pubKeyHex,
-		Address:    w.Address,
+// SaveToFile writes the wallet as a keystore file encrypted with
+// passphrase, readable only by its owner.
+func (w *Wallet) SaveToFile(filePath, passphrase string) error {
+	data, err := EncryptKey(w, passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt wallet: %w", err)
 	}
+	return writeFile(filePath, data)
+}
 
-	data, err := json.MarshalIndent(jsonData, "", "  ")
+// LoadWallet loads a wallet file. The passphrase of a keystore is read with
+// ReadPassphrase; an old plaintext wallet is still loaded, with a warning,
+// until it is migrated.
+func LoadWallet(filePath string) (*Wallet, error) {
+	data, err := os.ReadFile(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to marshal wallet: %w", err)
+		return nil, err
 	}
-
-	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
-		return err
+	if !IsEncrypted(data) {
+		log.Printf("⚠️ Wallet %s is not encrypted; encrypt it with: go run ./cmd/create_user migrate-wallets", filePath)
+		return loadPlaintext(data)
 	}
-
-	return os.WriteFile(filePath, data, 0600)
+	passphrase, err := ReadPassphrase(fmt.Sprintf("Passphrase for %s: ", filePath), false)
+	if err != nil {
+		return nil, err
+	}
+	return DecryptKey(data, passphrase)
 }
 
-// Load wallet from JSON file
-func LoadWallet(filePath string) (*Wallet, error) {
+// LoadWalletWithPassphrase loads a keystore file with the given passphrase.
+func LoadWalletWithPassphrase(filePath, passphrase string) (*Wallet, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, err
 	}
+	if !IsEncrypted(data) {
+		return nil, fmt.Errorf("wallet %s is not encrypted", filePath)
+	}
+	return DecryptKey(data, passphrase)
+}
 
+// loadPlaintext reads the old wallet format, which holds the private key in
+// hex.
+func loadPlaintext(data []byte) (*Wallet, error) {
 	var jsonData WalletJSON
 	if err := json.Unmarshal(data, &jsonData); err != nil {
 		return nil, fmt.Errorf("invalid wallet format: %w", err)
@@ -92,17 +129,39 @@ func LoadWallet(filePath string) (*Wallet, error) {
 	if err != nil {
 		return nil, fmt.Errorf("invalid private key encoding: %w", err)
 	}
+	if len(dBytes) == 0 || len(dBytes) > 32 {
+		return nil, errors.New("wallet has no valid private key")
+	}
 
-	priv := new(ecdsa.PrivateKey)
-	priv.PublicKey.Curve = elliptic.P256()
-	priv.D = new(big.Int).SetBytes(dBytes)
-	priv.PublicKey.X, priv.PublicKey.Y = priv.PublicKey.Curve.ScalarBaseMult(dBytes)
+	// Khoá cũ được lưu không đệm, có thể ngắn hơn 32 byte
+	w, err := walletFromKey(cryptohelper.P256, append(make([]byte, 32-len(dBytes)), dBytes...))
+	if err != nil {
+		return nil, err
+	}
+	if jsonData.Address != "" && jsonData.Address != w.Address {
+		return nil, fmt.Errorf("wallet address %s does not match its key (%s)", jsonData.Address, w.Address)
+	}
+	return w, nil
+}
 
-	return &Wallet{
-		PrivateKey: priv,
-		PublicKey:  &priv.PublicKey,
-		Address:    jsonData.Address,
-	}, nil
+// MigrateFile encrypts a plaintext wallet file in place with passphrase. It
+// reports false, and leaves the file alone, when it is already encrypted.
+func MigrateFile(filePath, passphrase string) (bool, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return false, err
+	}
+	if IsEncrypted(data) {
+		return false, nil
+	}
+	w, err := loadPlaintext(data)
+	if err != nil {
+		return false, err
+	}
+	if err := w.SaveToFile(filePath, passphrase); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 // Optional: Check if wallet file exists
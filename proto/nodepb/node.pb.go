@@ -21,16 +21,71 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type TxDirection int32
+
+const (
+	TxDirection_BOTH     TxDirection = 0
+	TxDirection_SENT     TxDirection = 1
+	TxDirection_RECEIVED TxDirection = 2
+)
+
+// Enum value maps for TxDirection.
+var (
+	TxDirection_name = map[int32]string{
+		0: "BOTH",
+		1: "SENT",
+		2: "RECEIVED",
+	}
+	TxDirection_value = map[string]int32{
+		"BOTH":     0,
+		"SENT":     1,
+		"RECEIVED": 2,
+	}
+)
+
+func (x TxDirection) Enum() *TxDirection {
+	p := new(TxDirection)
+	*p = x
+	return p
+}
+
+func (x TxDirection) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TxDirection) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_node_proto_enumTypes[0].Descriptor()
+}
+
+func (TxDirection) Type() protoreflect.EnumType {
+	return &file_proto_node_proto_enumTypes[0]
+}
+
+func (x TxDirection) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TxDirection.Descriptor instead.
+func (TxDirection) EnumDescriptor() ([]byte, []int) {
+	return file_proto_node_proto_rawDescGZIP(), []int{0}
+}
+
 type Transaction struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Sender        []byte                 `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
-	Receiver      []byte                 `protobuf:"bytes,2,opt,name=receiver,proto3" json:"receiver,omitempty"`
-	Amount        float64                `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
-	Timestamp     int64                  `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	Signature     []byte                 `protobuf:"bytes,5,opt,name=signature,proto3" json:"signature,omitempty"`
-	PublicKey     []byte                 `protobuf:"bytes,6,opt,name=publicKey,proto3" json:"publicKey,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Sender    []byte                 `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
+	Receiver  []byte                 `protobuf:"bytes,2,opt,name=receiver,proto3" json:"receiver,omitempty"`
+	Amount    float64                `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	Timestamp int64                  `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Signature []byte                 `protobuf:"bytes,5,opt,name=signature,proto3" json:"signature,omitempty"`
+	PublicKey []byte                 `protobuf:"bytes,6,opt,name=publicKey,proto3" json:"publicKey,omitempty"`
+	// Chain the transaction is valid on; it is part of the signed hash, so a
+	// transaction can not be replayed on another network.
+	ChainId string `protobuf:"bytes,7,opt,name=chainId,proto3" json:"chainId,omitempty"`
+	// Signature scheme of signature and publicKey (0 P-256, 1 secp256k1,
+	// 2 Ed25519); it is part of the signed hash.
+	SignatureScheme int32 `protobuf:"varint,8,opt,name=signatureScheme,proto3" json:"signatureScheme,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *Transaction) Reset() {
@@ -105,6 +160,20 @@ func (x *Transaction) GetPublicKey() []byte {
 	return nil
 }
 
+func (x *Transaction) GetChainId() string {
+	if x != nil {
+		return x.ChainId
+	}
+	return ""
+}
+
+func (x *Transaction) GetSignatureScheme() int32 {
+	if x != nil {
+		return x.SignatureScheme
+	}
+	return 0
+}
+
 type Block struct {
 	state             protoimpl.MessageState `protogen:"open.v1"`
 	Height            int64                  `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
@@ -113,8 +182,17 @@ type Block struct {
 	PreviousBlockHash []byte                 `protobuf:"bytes,4,opt,name=previousBlockHash,proto3" json:"previousBlockHash,omitempty"`
 	CurrentBlockHash  []byte                 `protobuf:"bytes,5,opt,name=currentBlockHash,proto3" json:"currentBlockHash,omitempty"`
 	Timestamp         int64                  `protobuf:"varint,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	unknownFields     protoimpl.UnknownFields
-	sizeCache         protoimpl.SizeCache
+	StateRoot         []byte                 `protobuf:"bytes,7,opt,name=stateRoot,proto3" json:"stateRoot,omitempty"`
+	Proposer          string                 `protobuf:"bytes,8,opt,name=proposer,proto3" json:"proposer,omitempty"`
+	ChainId           string                 `protobuf:"bytes,9,opt,name=chainId,proto3" json:"chainId,omitempty"`
+	// Set only on the genesis block: hash of the chain parameters and
+	// validators from genesis.json.
+	ConfigHash []byte `protobuf:"bytes,10,opt,name=configHash,proto3" json:"configHash,omitempty"`
+	// How merkleRoot is built: 0 = Merkle-Patricia Trie, 1 = binary Merkle
+	// tree (RFC 6962).
+	TxRootType    int32 `protobuf:"varint,11,opt,name=txRootType,proto3" json:"txRootType,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Block) Reset() {
@@ -189,19 +267,229 @@ func (x *Block) GetTimestamp() int64 {
 	return 0
 }
 
-type Vote struct {
+func (x *Block) GetStateRoot() []byte {
+	if x != nil {
+		return x.StateRoot
+	}
+	return nil
+}
+
+func (x *Block) GetProposer() string {
+	if x != nil {
+		return x.Proposer
+	}
+	return ""
+}
+
+func (x *Block) GetChainId() string {
+	if x != nil {
+		return x.ChainId
+	}
+	return ""
+}
+
+func (x *Block) GetConfigHash() []byte {
+	if x != nil {
+		return x.ConfigHash
+	}
+	return nil
+}
+
+func (x *Block) GetTxRootType() int32 {
+	if x != nil {
+		return x.TxRootType
+	}
+	return 0
+}
+
+// Header-only view of a block, used when the caller does not need the
+// transaction bodies. The block hash is the hash of this message with
+// currentBlockHash and txCount left empty.
+type BlockHeader struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Height            int64                  `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	MerkleRoot        []byte                 `protobuf:"bytes,2,opt,name=merkleRoot,proto3" json:"merkleRoot,omitempty"`
+	PreviousBlockHash []byte                 `protobuf:"bytes,3,opt,name=previousBlockHash,proto3" json:"previousBlockHash,omitempty"`
+	CurrentBlockHash  []byte                 `protobuf:"bytes,4,opt,name=currentBlockHash,proto3" json:"currentBlockHash,omitempty"`
+	Timestamp         int64                  `protobuf:"varint,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	TxCount           int32                  `protobuf:"varint,6,opt,name=txCount,proto3" json:"txCount,omitempty"`
+	StateRoot         []byte                 `protobuf:"bytes,7,opt,name=stateRoot,proto3" json:"stateRoot,omitempty"`
+	Proposer          string                 `protobuf:"bytes,8,opt,name=proposer,proto3" json:"proposer,omitempty"`
+	ChainId           string                 `protobuf:"bytes,9,opt,name=chainId,proto3" json:"chainId,omitempty"`
+	ConfigHash        []byte                 `protobuf:"bytes,10,opt,name=configHash,proto3" json:"configHash,omitempty"`
+	TxRootType        int32                  `protobuf:"varint,11,opt,name=txRootType,proto3" json:"txRootType,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *BlockHeader) Reset() {
+	*x = BlockHeader{}
+	mi := &file_proto_node_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlockHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlockHeader) ProtoMessage() {}
+
+func (x *BlockHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_node_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlockHeader.ProtoReflect.Descriptor instead.
+func (*BlockHeader) Descriptor() ([]byte, []int) {
+	return file_proto_node_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BlockHeader) GetHeight() int64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *BlockHeader) GetMerkleRoot() []byte {
+	if x != nil {
+		return x.MerkleRoot
+	}
+	return nil
+}
+
+func (x *BlockHeader) GetPreviousBlockHash() []byte {
+	if x != nil {
+		return x.PreviousBlockHash
+	}
+	return nil
+}
+
+func (x *BlockHeader) GetCurrentBlockHash() []byte {
+	if x != nil {
+		return x.CurrentBlockHash
+	}
+	return nil
+}
+
+func (x *BlockHeader) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *BlockHeader) GetTxCount() int32 {
+	if x != nil {
+		return x.TxCount
+	}
+	return 0
+}
+
+func (x *BlockHeader) GetStateRoot() []byte {
+	if x != nil {
+		return x.StateRoot
+	}
+	return nil
+}
+
+func (x *BlockHeader) GetProposer() string {
+	if x != nil {
+		return x.Proposer
+	}
+	return ""
+}
+
+func (x *BlockHeader) GetChainId() string {
+	if x != nil {
+		return x.ChainId
+	}
+	return ""
+}
+
+func (x *BlockHeader) GetConfigHash() []byte {
+	if x != nil {
+		return x.ConfigHash
+	}
+	return nil
+}
+
+func (x *BlockHeader) GetTxRootType() int32 {
+	if x != nil {
+		return x.TxRootType
+	}
+	return 0
+}
+
+// Transactions of a block, stored apart from its header. The field number
+// matches Block so an encoded Block also decodes as its body.
+type BlockBody struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	VoterId       string                 `protobuf:"bytes,1,opt,name=voterId,proto3" json:"voterId,omitempty"`
-	BlockHeight   int64                  `protobuf:"varint,2,opt,name=blockHeight,proto3" json:"blockHeight,omitempty"`
-	BlockHash     []byte                 `protobuf:"bytes,3,opt,name=blockHash,proto3" json:"blockHash,omitempty"`
-	Approved      bool                   `protobuf:"varint,4,opt,name=approved,proto3" json:"approved,omitempty"`
+	Transactions  []*Transaction         `protobuf:"bytes,2,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BlockBody) Reset() {
+	*x = BlockBody{}
+	mi := &file_proto_node_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlockBody) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlockBody) ProtoMessage() {}
+
+func (x *BlockBody) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_node_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlockBody.ProtoReflect.Descriptor instead.
+func (*BlockBody) Descriptor() ([]byte, []int) {
+	return file_proto_node_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BlockBody) GetTransactions() []*Transaction {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+type Vote struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	VoterId     string                 `protobuf:"bytes,1,opt,name=voterId,proto3" json:"voterId,omitempty"`
+	BlockHeight int64                  `protobuf:"varint,2,opt,name=blockHeight,proto3" json:"blockHeight,omitempty"`
+	BlockHash   []byte                 `protobuf:"bytes,3,opt,name=blockHash,proto3" json:"blockHash,omitempty"`
+	Approved    bool                   `protobuf:"varint,4,opt,name=approved,proto3" json:"approved,omitempty"`
+	// Chain of the voted block; the leader drops votes of another chain.
+	ChainId       string `protobuf:"bytes,5,opt,name=chainId,proto3" json:"chainId,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Vote) Reset() {
 	*x = Vote{}
-	mi := &file_proto_node_proto_msgTypes[2]
+	mi := &file_proto_node_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -213,7 +501,7 @@ func (x *Vote) String() string {
 func (*Vote) ProtoMessage() {}
 
 func (x *Vote) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_node_proto_msgTypes[2]
+	mi := &file_proto_node_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -226,7 +514,7 @@ func (x *Vote) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Vote.ProtoReflect.Descriptor instead.
 func (*Vote) Descriptor() ([]byte, []int) {
-	return file_proto_node_proto_rawDescGZIP(), []int{2}
+	return file_proto_node_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *Vote) GetVoterId() string {
@@ -257,6 +545,13 @@ func (x *Vote) GetApproved() bool {
 	return false
 }
 
+func (x *Vote) GetChainId() string {
+	if x != nil {
+		return x.ChainId
+	}
+	return ""
+}
+
 type BlockRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Height        int64                  `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
@@ -266,7 +561,7 @@ type BlockRequest struct {
 
 func (x *BlockRequest) Reset() {
 	*x = BlockRequest{}
-	mi := &file_proto_node_proto_msgTypes[3]
+	mi := &file_proto_node_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -278,7 +573,7 @@ func (x *BlockRequest) String() string {
 func (*BlockRequest) ProtoMessage() {}
 
 func (x *BlockRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_node_proto_msgTypes[3]
+	mi := &file_proto_node_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -291,7 +586,7 @@ func (x *BlockRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BlockRequest.ProtoReflect.Descriptor instead.
 func (*BlockRequest) Descriptor() ([]byte, []int) {
-	return file_proto_node_proto_rawDescGZIP(), []int{3}
+	return file_proto_node_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *BlockRequest) GetHeight() int64 {
@@ -301,6 +596,50 @@ func (x *BlockRequest) GetHeight() int64 {
 	return 0
 }
 
+type BlockHashRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hash          []byte                 `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BlockHashRequest) Reset() {
+	*x = BlockHashRequest{}
+	mi := &file_proto_node_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlockHashRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlockHashRequest) ProtoMessage() {}
+
+func (x *BlockHashRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_node_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlockHashRequest.ProtoReflect.Descriptor instead.
+func (*BlockHashRequest) Descriptor() ([]byte, []int) {
+	return file_proto_node_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BlockHashRequest) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
 type GetBlock struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Height        int64                  `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
@@ -310,7 +649,7 @@ type GetBlock struct {
 
 func (x *GetBlock) Reset() {
 	*x = GetBlock{}
-	mi := &file_proto_node_proto_msgTypes[4]
+	mi := &file_proto_node_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -322,7 +661,7 @@ func (x *GetBlock) String() string {
 func (*GetBlock) ProtoMessage() {}
 
 func (x *GetBlock) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_node_proto_msgTypes[4]
+	mi := &file_proto_node_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -335,7 +674,7 @@ func (x *GetBlock) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetBlock.ProtoReflect.Descriptor instead.
 func (*GetBlock) Descriptor() ([]byte, []int) {
-	return file_proto_node_proto_rawDescGZIP(), []int{4}
+	return file_proto_node_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *GetBlock) GetHeight() int64 {
@@ -353,7 +692,7 @@ type Empty struct {
 
 func (x *Empty) Reset() {
 	*x = Empty{}
-	mi := &file_proto_node_proto_msgTypes[5]
+	mi := &file_proto_node_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -365,7 +704,7 @@ func (x *Empty) String() string {
 func (*Empty) ProtoMessage() {}
 
 func (x *Empty) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_node_proto_msgTypes[5]
+	mi := &file_proto_node_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -378,7 +717,7 @@ func (x *Empty) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Empty.ProtoReflect.Descriptor instead.
 func (*Empty) Descriptor() ([]byte, []int) {
-	return file_proto_node_proto_rawDescGZIP(), []int{5}
+	return file_proto_node_proto_rawDescGZIP(), []int{8}
 }
 
 type Status struct {
@@ -391,7 +730,7 @@ type Status struct {
 
 func (x *Status) Reset() {
 	*x = Status{}
-	mi := &file_proto_node_proto_msgTypes[6]
+	mi := &file_proto_node_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -403,7 +742,7 @@ func (x *Status) String() string {
 func (*Status) ProtoMessage() {}
 
 func (x *Status) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_node_proto_msgTypes[6]
+	mi := &file_proto_node_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -416,7 +755,7 @@ func (x *Status) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Status.ProtoReflect.Descriptor instead.
 func (*Status) Descriptor() ([]byte, []int) {
-	return file_proto_node_proto_rawDescGZIP(), []int{6}
+	return file_proto_node_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *Status) GetMessage() string {
@@ -442,7 +781,7 @@ type HeightRequest struct {
 
 func (x *HeightRequest) Reset() {
 	*x = HeightRequest{}
-	mi := &file_proto_node_proto_msgTypes[7]
+	mi := &file_proto_node_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -454,7 +793,7 @@ func (x *HeightRequest) String() string {
 func (*HeightRequest) ProtoMessage() {}
 
 func (x *HeightRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_node_proto_msgTypes[7]
+	mi := &file_proto_node_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -467,7 +806,7 @@ func (x *HeightRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HeightRequest.ProtoReflect.Descriptor instead.
 func (*HeightRequest) Descriptor() ([]byte, []int) {
-	return file_proto_node_proto_rawDescGZIP(), []int{7}
+	return file_proto_node_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *HeightRequest) GetFromHeight() int64 {
@@ -478,15 +817,18 @@ func (x *HeightRequest) GetFromHeight() int64 {
 }
 
 type BlockList struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Blocks        []*Block               `protobuf:"bytes,1,rep,name=blocks,proto3" json:"blocks,omitempty"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Blocks []*Block               `protobuf:"bytes,1,rep,name=blocks,proto3" json:"blocks,omitempty"`
+	// Height to request next when the server capped the page.
+	NextHeight    int64 `protobuf:"varint,2,opt,name=next_height,json=nextHeight,proto3" json:"next_height,omitempty"`
+	HasMore       bool  `protobuf:"varint,3,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *BlockList) Reset() {
 	*x = BlockList{}
-	mi := &file_proto_node_proto_msgTypes[8]
+	mi := &file_proto_node_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -498,7 +840,7 @@ func (x *BlockList) String() string {
 func (*BlockList) ProtoMessage() {}
 
 func (x *BlockList) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_node_proto_msgTypes[8]
+	mi := &file_proto_node_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -511,7 +853,7 @@ func (x *BlockList) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BlockList.ProtoReflect.Descriptor instead.
 func (*BlockList) Descriptor() ([]byte, []int) {
-	return file_proto_node_proto_rawDescGZIP(), []int{8}
+	return file_proto_node_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *BlockList) GetBlocks() []*Block {
@@ -521,16 +863,154 @@ func (x *BlockList) GetBlocks() []*Block {
 	return nil
 }
 
-type GetBalanceRequest struct {
+func (x *BlockList) GetNextHeight() int64 {
+	if x != nil {
+		return x.NextHeight
+	}
+	return 0
+}
+
+func (x *BlockList) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+// Paged range request used by block and header sync. Zero limits mean
+// "server default"; the server also clamps limits that are too large.
+type BlockRangeRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Address       string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	FromHeight    int64                  `protobuf:"varint,1,opt,name=from_height,json=fromHeight,proto3" json:"from_height,omitempty"`
+	MaxBlocks     int64                  `protobuf:"varint,2,opt,name=max_blocks,json=maxBlocks,proto3" json:"max_blocks,omitempty"`
+	MaxBytes      int64                  `protobuf:"varint,3,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BlockRangeRequest) Reset() {
+	*x = BlockRangeRequest{}
+	mi := &file_proto_node_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlockRangeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlockRangeRequest) ProtoMessage() {}
+
+func (x *BlockRangeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_node_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlockRangeRequest.ProtoReflect.Descriptor instead.
+func (*BlockRangeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_node_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *BlockRangeRequest) GetFromHeight() int64 {
+	if x != nil {
+		return x.FromHeight
+	}
+	return 0
+}
+
+func (x *BlockRangeRequest) GetMaxBlocks() int64 {
+	if x != nil {
+		return x.MaxBlocks
+	}
+	return 0
+}
+
+func (x *BlockRangeRequest) GetMaxBytes() int64 {
+	if x != nil {
+		return x.MaxBytes
+	}
+	return 0
+}
+
+type HeaderList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Headers       []*BlockHeader         `protobuf:"bytes,1,rep,name=headers,proto3" json:"headers,omitempty"`
+	NextHeight    int64                  `protobuf:"varint,2,opt,name=next_height,json=nextHeight,proto3" json:"next_height,omitempty"`
+	HasMore       bool                   `protobuf:"varint,3,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeaderList) Reset() {
+	*x = HeaderList{}
+	mi := &file_proto_node_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeaderList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeaderList) ProtoMessage() {}
+
+func (x *HeaderList) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_node_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeaderList.ProtoReflect.Descriptor instead.
+func (*HeaderList) Descriptor() ([]byte, []int) {
+	return file_proto_node_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *HeaderList) GetHeaders() []*BlockHeader {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *HeaderList) GetNextHeight() int64 {
+	if x != nil {
+		return x.NextHeight
+	}
+	return 0
+}
+
+func (x *HeaderList) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+type GetBalanceRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Address string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	// Balance right after this block; latest when unset.
+	Height        *int64 `protobuf:"varint,2,opt,name=height,proto3,oneof" json:"height,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetBalanceRequest) Reset() {
 	*x = GetBalanceRequest{}
-	mi := &file_proto_node_proto_msgTypes[9]
+	mi := &file_proto_node_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -542,7 +1022,7 @@ func (x *GetBalanceRequest) String() string {
 func (*GetBalanceRequest) ProtoMessage() {}
 
 func (x *GetBalanceRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_node_proto_msgTypes[9]
+	mi := &file_proto_node_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -555,7 +1035,7 @@ func (x *GetBalanceRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetBalanceRequest.ProtoReflect.Descriptor instead.
 func (*GetBalanceRequest) Descriptor() ([]byte, []int) {
-	return file_proto_node_proto_rawDescGZIP(), []int{9}
+	return file_proto_node_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *GetBalanceRequest) GetAddress() string {
@@ -565,6 +1045,13 @@ func (x *GetBalanceRequest) GetAddress() string {
 	return ""
 }
 
+func (x *GetBalanceRequest) GetHeight() int64 {
+	if x != nil && x.Height != nil {
+		return *x.Height
+	}
+	return 0
+}
+
 type GetBalanceResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Balance       float64                `protobuf:"fixed64,1,opt,name=balance,proto3" json:"balance,omitempty"`
@@ -575,7 +1062,7 @@ type GetBalanceResponse struct {
 
 func (x *GetBalanceResponse) Reset() {
 	*x = GetBalanceResponse{}
-	mi := &file_proto_node_proto_msgTypes[10]
+	mi := &file_proto_node_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -587,7 +1074,7 @@ func (x *GetBalanceResponse) String() string {
 func (*GetBalanceResponse) ProtoMessage() {}
 
 func (x *GetBalanceResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_node_proto_msgTypes[10]
+	mi := &file_proto_node_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -600,7 +1087,7 @@ func (x *GetBalanceResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetBalanceResponse.ProtoReflect.Descriptor instead.
 func (*GetBalanceResponse) Descriptor() ([]byte, []int) {
-	return file_proto_node_proto_rawDescGZIP(), []int{10}
+	return file_proto_node_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *GetBalanceResponse) GetBalance() float64 {
@@ -617,61 +1104,1004 @@ func (x *GetBalanceResponse) GetAddress() string {
 	return ""
 }
 
-var File_proto_node_proto protoreflect.FileDescriptor
+// Transactions involving an address, oldest first. Pass back next_cursor to
+// get the following page.
+type AccountHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Direction     TxDirection            `protobuf:"varint,2,opt,name=direction,proto3,enum=node.TxDirection" json:"direction,omitempty"`
+	FromHeight    int64                  `protobuf:"varint,3,opt,name=from_height,json=fromHeight,proto3" json:"from_height,omitempty"`
+	ToHeight      *int64                 `protobuf:"varint,4,opt,name=to_height,json=toHeight,proto3,oneof" json:"to_height,omitempty"`
+	Cursor        []byte                 `protobuf:"bytes,5,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit         int32                  `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_proto_node_proto_rawDesc = "" +
-	"\n" +
-	"\x10proto/node.proto\x12\x04node\"\xb3\x01\n" +
-	"\vTransaction\x12\x16\n" +
-	"\x06sender\x18\x01 \x01(\fR\x06sender\x12\x1a\n" +
-	"\breceiver\x18\x02 \x01(\fR\breceiver\x12\x16\n" +
-	"\x06amount\x18\x03 \x01(\x01R\x06amount\x12\x1c\n" +
-	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp\x12\x1c\n" +
-	"\tsignature\x18\x05 \x01(\fR\tsignature\x12\x1c\n" +
-	"\tpublicKey\x18\x06 \x01(\fR\tpublicKey\"\xee\x01\n" +
-	"\x05Block\x12\x16\n" +
-	"\x06height\x18\x01 \x01(\x03R\x06height\x125\n" +
-	"\ftransactions\x18\x02 \x03(\v2\x11.node.TransactionR\ftransactions\x12\x1e\n" +
-	"\n" +
-	"merkleRoot\x18\x03 \x01(\fR\n" +
-	"merkleRoot\x12,\n" +
-	"\x11previousBlockHash\x18\x04 \x01(\fR\x11previousBlockHash\x12*\n" +
-	"\x10currentBlockHash\x18\x05 \x01(\fR\x10currentBlockHash\x12\x1c\n" +
-	"\ttimestamp\x18\x06 \x01(\x03R\ttimestamp\"|\n" +
-	"\x04Vote\x12\x18\n" +
-	"\avoterId\x18\x01 \x01(\tR\avoterId\x12 \n" +
-	"\vblockHeight\x18\x02 \x01(\x03R\vblockHeight\x12\x1c\n" +
-	"\tblockHash\x18\x03 \x01(\fR\tblockHash\x12\x1a\n" +
-	"\bapproved\x18\x04 \x01(\bR\bapproved\"&\n" +
-	"\fBlockRequest\x12\x16\n" +
-	"\x06height\x18\x01 \x01(\x03R\x06height\"\"\n" +
-	"\bGetBlock\x12\x16\n" +
-	"\x06height\x18\x01 \x01(\x03R\x06height\"\a\n" +
-	"\x05Empty\"<\n" +
-	"\x06Status\x12\x18\n" +
-	"\amessage\x18\x01 \x01(\tR\amessage\x12\x18\n" +
-	"\asuccess\x18\x02 \x01(\bR\asuccess\"0\n" +
-	"\rHeightRequest\x12\x1f\n" +
-	"\vfrom_height\x18\x01 \x01(\x03R\n" +
-	"fromHeight\"0\n" +
-	"\tBlockList\x12#\n" +
-	"\x06blocks\x18\x01 \x03(\v2\v.node.BlockR\x06blocks\"-\n" +
-	"\x11GetBalanceRequest\x12\x18\n" +
-	"\aaddress\x18\x01 \x01(\tR\aaddress\"H\n" +
-	"\x12GetBalanceResponse\x12\x18\n" +
-	"\abalance\x18\x01 \x01(\x01R\abalance\x12\x18\n" +
-	"\aaddress\x18\x02 \x01(\tR\aaddress2\x93\x03\n" +
-	"\vNodeService\x122\n" +
+func (x *AccountHistoryRequest) Reset() {
+	*x = AccountHistoryRequest{}
+	mi := &file_proto_node_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccountHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountHistoryRequest) ProtoMessage() {}
+
+func (x *AccountHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_node_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountHistoryRequest.ProtoReflect.Descriptor instead.
+func (*AccountHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_node_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *AccountHistoryRequest) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *AccountHistoryRequest) GetDirection() TxDirection {
+	if x != nil {
+		return x.Direction
+	}
+	return TxDirection_BOTH
+}
+
+func (x *AccountHistoryRequest) GetFromHeight() int64 {
+	if x != nil {
+		return x.FromHeight
+	}
+	return 0
+}
+
+func (x *AccountHistoryRequest) GetToHeight() int64 {
+	if x != nil && x.ToHeight != nil {
+		return *x.ToHeight
+	}
+	return 0
+}
+
+func (x *AccountHistoryRequest) GetCursor() []byte {
+	if x != nil {
+		return x.Cursor
+	}
+	return nil
+}
+
+func (x *AccountHistoryRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type AccountTx struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Height        int64                  `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	Index         int32                  `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	Direction     TxDirection            `protobuf:"varint,3,opt,name=direction,proto3,enum=node.TxDirection" json:"direction,omitempty"`
+	Transaction   *Transaction           `protobuf:"bytes,4,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	TxHash        []byte                 `protobuf:"bytes,5,opt,name=txHash,proto3" json:"txHash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AccountTx) Reset() {
+	*x = AccountTx{}
+	mi := &file_proto_node_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccountTx) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountTx) ProtoMessage() {}
+
+func (x *AccountTx) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_node_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountTx.ProtoReflect.Descriptor instead.
+func (*AccountTx) Descriptor() ([]byte, []int) {
+	return file_proto_node_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *AccountTx) GetHeight() int64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *AccountTx) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *AccountTx) GetDirection() TxDirection {
+	if x != nil {
+		return x.Direction
+	}
+	return TxDirection_BOTH
+}
+
+func (x *AccountTx) GetTransaction() *Transaction {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+func (x *AccountTx) GetTxHash() []byte {
+	if x != nil {
+		return x.TxHash
+	}
+	return nil
+}
+
+type AccountHistory struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       []*AccountTx           `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	NextCursor    []byte                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	HasMore       bool                   `protobuf:"varint,3,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AccountHistory) Reset() {
+	*x = AccountHistory{}
+	mi := &file_proto_node_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccountHistory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountHistory) ProtoMessage() {}
+
+func (x *AccountHistory) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_node_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountHistory.ProtoReflect.Descriptor instead.
+func (*AccountHistory) Descriptor() ([]byte, []int) {
+	return file_proto_node_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *AccountHistory) GetEntries() []*AccountTx {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *AccountHistory) GetNextCursor() []byte {
+	if x != nil {
+		return x.NextCursor
+	}
+	return nil
+}
+
+func (x *AccountHistory) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+type Account struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Balance       float64                `protobuf:"fixed64,2,opt,name=balance,proto3" json:"balance,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Account) Reset() {
+	*x = Account{}
+	mi := &file_proto_node_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Account) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Account) ProtoMessage() {}
+
+func (x *Account) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_node_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Account.ProtoReflect.Descriptor instead.
+func (*Account) Descriptor() ([]byte, []int) {
+	return file_proto_node_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *Account) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *Account) GetBalance() float64 {
+	if x != nil {
+		return x.Balance
+	}
+	return 0
+}
+
+type SnapshotInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Height        int64                  `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	BlockHash     []byte                 `protobuf:"bytes,2,opt,name=blockHash,proto3" json:"blockHash,omitempty"`
+	StateRoot     []byte                 `protobuf:"bytes,3,opt,name=stateRoot,proto3" json:"stateRoot,omitempty"`
+	Accounts      int64                  `protobuf:"varint,4,opt,name=accounts,proto3" json:"accounts,omitempty"`
+	ChunkHashes   [][]byte               `protobuf:"bytes,5,rep,name=chunkHashes,proto3" json:"chunkHashes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotInfo) Reset() {
+	*x = SnapshotInfo{}
+	mi := &file_proto_node_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotInfo) ProtoMessage() {}
+
+func (x *SnapshotInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_node_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotInfo.ProtoReflect.Descriptor instead.
+func (*SnapshotInfo) Descriptor() ([]byte, []int) {
+	return file_proto_node_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SnapshotInfo) GetHeight() int64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *SnapshotInfo) GetBlockHash() []byte {
+	if x != nil {
+		return x.BlockHash
+	}
+	return nil
+}
+
+func (x *SnapshotInfo) GetStateRoot() []byte {
+	if x != nil {
+		return x.StateRoot
+	}
+	return nil
+}
+
+func (x *SnapshotInfo) GetAccounts() int64 {
+	if x != nil {
+		return x.Accounts
+	}
+	return 0
+}
+
+func (x *SnapshotInfo) GetChunkHashes() [][]byte {
+	if x != nil {
+		return x.ChunkHashes
+	}
+	return nil
+}
+
+type SnapshotList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Snapshots     []*SnapshotInfo        `protobuf:"bytes,1,rep,name=snapshots,proto3" json:"snapshots,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotList) Reset() {
+	*x = SnapshotList{}
+	mi := &file_proto_node_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotList) ProtoMessage() {}
+
+func (x *SnapshotList) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_node_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotList.ProtoReflect.Descriptor instead.
+func (*SnapshotList) Descriptor() ([]byte, []int) {
+	return file_proto_node_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *SnapshotList) GetSnapshots() []*SnapshotInfo {
+	if x != nil {
+		return x.Snapshots
+	}
+	return nil
+}
+
+type SnapshotChunkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Height        int64                  `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	Index         int32                  `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotChunkRequest) Reset() {
+	*x = SnapshotChunkRequest{}
+	mi := &file_proto_node_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotChunkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotChunkRequest) ProtoMessage() {}
+
+func (x *SnapshotChunkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_node_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotChunkRequest.ProtoReflect.Descriptor instead.
+func (*SnapshotChunkRequest) Descriptor() ([]byte, []int) {
+	return file_proto_node_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SnapshotChunkRequest) GetHeight() int64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *SnapshotChunkRequest) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+type SnapshotChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Height        int64                  `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	Index         int32                  `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	Accounts      []*Account             `protobuf:"bytes,3,rep,name=accounts,proto3" json:"accounts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotChunk) Reset() {
+	*x = SnapshotChunk{}
+	mi := &file_proto_node_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotChunk) ProtoMessage() {}
+
+func (x *SnapshotChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_node_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotChunk.ProtoReflect.Descriptor instead.
+func (*SnapshotChunk) Descriptor() ([]byte, []int) {
+	return file_proto_node_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *SnapshotChunk) GetHeight() int64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *SnapshotChunk) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *SnapshotChunk) GetAccounts() []*Account {
+	if x != nil {
+		return x.Accounts
+	}
+	return nil
+}
+
+// Selects a transaction of a block by hash, or by position when txHash is
+// empty.
+type TransactionProofRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Height        int64                  `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	TxHash        []byte                 `protobuf:"bytes,2,opt,name=txHash,proto3" json:"txHash,omitempty"`
+	Index         int32                  `protobuf:"varint,3,opt,name=index,proto3" json:"index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransactionProofRequest) Reset() {
+	*x = TransactionProofRequest{}
+	mi := &file_proto_node_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionProofRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionProofRequest) ProtoMessage() {}
+
+func (x *TransactionProofRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_node_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionProofRequest.ProtoReflect.Descriptor instead.
+func (*TransactionProofRequest) Descriptor() ([]byte, []int) {
+	return file_proto_node_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *TransactionProofRequest) GetHeight() int64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *TransactionProofRequest) GetTxHash() []byte {
+	if x != nil {
+		return x.TxHash
+	}
+	return nil
+}
+
+func (x *TransactionProofRequest) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+// Inclusion proof of a transaction against the merkleRoot of its block
+// header. proof is the serialized mpt.Proof (an RLP list of trie nodes) when
+// txRootType is 0, the audit path (concatenated hashes) when it is 1.
+type TransactionProof struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Height        int64                  `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	BlockHash     []byte                 `protobuf:"bytes,2,opt,name=blockHash,proto3" json:"blockHash,omitempty"`
+	MerkleRoot    []byte                 `protobuf:"bytes,3,opt,name=merkleRoot,proto3" json:"merkleRoot,omitempty"`
+	Index         int32                  `protobuf:"varint,4,opt,name=index,proto3" json:"index,omitempty"`
+	TxHash        []byte                 `protobuf:"bytes,5,opt,name=txHash,proto3" json:"txHash,omitempty"`
+	Transaction   *Transaction           `protobuf:"bytes,6,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	Proof         []byte                 `protobuf:"bytes,7,opt,name=proof,proto3" json:"proof,omitempty"`
+	TxRootType    int32                  `protobuf:"varint,8,opt,name=txRootType,proto3" json:"txRootType,omitempty"`
+	TxCount       int32                  `protobuf:"varint,9,opt,name=txCount,proto3" json:"txCount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransactionProof) Reset() {
+	*x = TransactionProof{}
+	mi := &file_proto_node_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionProof) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionProof) ProtoMessage() {}
+
+func (x *TransactionProof) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_node_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionProof.ProtoReflect.Descriptor instead.
+func (*TransactionProof) Descriptor() ([]byte, []int) {
+	return file_proto_node_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *TransactionProof) GetHeight() int64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *TransactionProof) GetBlockHash() []byte {
+	if x != nil {
+		return x.BlockHash
+	}
+	return nil
+}
+
+func (x *TransactionProof) GetMerkleRoot() []byte {
+	if x != nil {
+		return x.MerkleRoot
+	}
+	return nil
+}
+
+func (x *TransactionProof) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *TransactionProof) GetTxHash() []byte {
+	if x != nil {
+		return x.TxHash
+	}
+	return nil
+}
+
+func (x *TransactionProof) GetTransaction() *Transaction {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+func (x *TransactionProof) GetProof() []byte {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+func (x *TransactionProof) GetTxRootType() int32 {
+	if x != nil {
+		return x.TxRootType
+	}
+	return 0
+}
+
+func (x *TransactionProof) GetTxCount() int32 {
+	if x != nil {
+		return x.TxCount
+	}
+	return 0
+}
+
+// Sent by a peer or client before it talks to a node. Empty fields are not
+// checked, so a client can learn the chain it is connected to.
+type HandshakeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NodeId        string                 `protobuf:"bytes,1,opt,name=nodeId,proto3" json:"nodeId,omitempty"`
+	ChainId       string                 `protobuf:"bytes,2,opt,name=chainId,proto3" json:"chainId,omitempty"`
+	GenesisHash   []byte                 `protobuf:"bytes,3,opt,name=genesisHash,proto3" json:"genesisHash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HandshakeRequest) Reset() {
+	*x = HandshakeRequest{}
+	mi := &file_proto_node_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HandshakeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandshakeRequest) ProtoMessage() {}
+
+func (x *HandshakeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_node_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HandshakeRequest.ProtoReflect.Descriptor instead.
+func (*HandshakeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_node_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *HandshakeRequest) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *HandshakeRequest) GetChainId() string {
+	if x != nil {
+		return x.ChainId
+	}
+	return ""
+}
+
+func (x *HandshakeRequest) GetGenesisHash() []byte {
+	if x != nil {
+		return x.GenesisHash
+	}
+	return nil
+}
+
+type HandshakeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NodeId        string                 `protobuf:"bytes,1,opt,name=nodeId,proto3" json:"nodeId,omitempty"`
+	ChainId       string                 `protobuf:"bytes,2,opt,name=chainId,proto3" json:"chainId,omitempty"`
+	GenesisHash   []byte                 `protobuf:"bytes,3,opt,name=genesisHash,proto3" json:"genesisHash,omitempty"`
+	Height        int64                  `protobuf:"varint,4,opt,name=height,proto3" json:"height,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HandshakeResponse) Reset() {
+	*x = HandshakeResponse{}
+	mi := &file_proto_node_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HandshakeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandshakeResponse) ProtoMessage() {}
+
+func (x *HandshakeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_node_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HandshakeResponse.ProtoReflect.Descriptor instead.
+func (*HandshakeResponse) Descriptor() ([]byte, []int) {
+	return file_proto_node_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *HandshakeResponse) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *HandshakeResponse) GetChainId() string {
+	if x != nil {
+		return x.ChainId
+	}
+	return ""
+}
+
+func (x *HandshakeResponse) GetGenesisHash() []byte {
+	if x != nil {
+		return x.GenesisHash
+	}
+	return nil
+}
+
+func (x *HandshakeResponse) GetHeight() int64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+var File_proto_node_proto protoreflect.FileDescriptor
+
+const file_proto_node_proto_rawDesc = "" +
+	"\n" +
+	"\x10proto/node.proto\x12\x04node\"\xf7\x01\n" +
+	"\vTransaction\x12\x16\n" +
+	"\x06sender\x18\x01 \x01(\fR\x06sender\x12\x1a\n" +
+	"\breceiver\x18\x02 \x01(\fR\breceiver\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\x01R\x06amount\x12\x1c\n" +
+	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp\x12\x1c\n" +
+	"\tsignature\x18\x05 \x01(\fR\tsignature\x12\x1c\n" +
+	"\tpublicKey\x18\x06 \x01(\fR\tpublicKey\x12\x18\n" +
+	"\achainId\x18\a \x01(\tR\achainId\x12(\n" +
+	"\x0fsignatureScheme\x18\b \x01(\x05R\x0fsignatureScheme\"\x82\x03\n" +
+	"\x05Block\x12\x16\n" +
+	"\x06height\x18\x01 \x01(\x03R\x06height\x125\n" +
+	"\ftransactions\x18\x02 \x03(\v2\x11.node.TransactionR\ftransactions\x12\x1e\n" +
+	"\n" +
+	"merkleRoot\x18\x03 \x01(\fR\n" +
+	"merkleRoot\x12,\n" +
+	"\x11previousBlockHash\x18\x04 \x01(\fR\x11previousBlockHash\x12*\n" +
+	"\x10currentBlockHash\x18\x05 \x01(\fR\x10currentBlockHash\x12\x1c\n" +
+	"\ttimestamp\x18\x06 \x01(\x03R\ttimestamp\x12\x1c\n" +
+	"\tstateRoot\x18\a \x01(\fR\tstateRoot\x12\x1a\n" +
+	"\bproposer\x18\b \x01(\tR\bproposer\x12\x18\n" +
+	"\achainId\x18\t \x01(\tR\achainId\x12\x1e\n" +
+	"\n" +
+	"configHash\x18\n" +
+	" \x01(\fR\n" +
+	"configHash\x12\x1e\n" +
+	"\n" +
+	"txRootType\x18\v \x01(\x05R\n" +
+	"txRootType\"\xeb\x02\n" +
+	"\vBlockHeader\x12\x16\n" +
+	"\x06height\x18\x01 \x01(\x03R\x06height\x12\x1e\n" +
+	"\n" +
+	"merkleRoot\x18\x02 \x01(\fR\n" +
+	"merkleRoot\x12,\n" +
+	"\x11previousBlockHash\x18\x03 \x01(\fR\x11previousBlockHash\x12*\n" +
+	"\x10currentBlockHash\x18\x04 \x01(\fR\x10currentBlockHash\x12\x1c\n" +
+	"\ttimestamp\x18\x05 \x01(\x03R\ttimestamp\x12\x18\n" +
+	"\atxCount\x18\x06 \x01(\x05R\atxCount\x12\x1c\n" +
+	"\tstateRoot\x18\a \x01(\fR\tstateRoot\x12\x1a\n" +
+	"\bproposer\x18\b \x01(\tR\bproposer\x12\x18\n" +
+	"\achainId\x18\t \x01(\tR\achainId\x12\x1e\n" +
+	"\n" +
+	"configHash\x18\n" +
+	" \x01(\fR\n" +
+	"configHash\x12\x1e\n" +
+	"\n" +
+	"txRootType\x18\v \x01(\x05R\n" +
+	"txRootType\"B\n" +
+	"\tBlockBody\x125\n" +
+	"\ftransactions\x18\x02 \x03(\v2\x11.node.TransactionR\ftransactions\"\x96\x01\n" +
+	"\x04Vote\x12\x18\n" +
+	"\avoterId\x18\x01 \x01(\tR\avoterId\x12 \n" +
+	"\vblockHeight\x18\x02 \x01(\x03R\vblockHeight\x12\x1c\n" +
+	"\tblockHash\x18\x03 \x01(\fR\tblockHash\x12\x1a\n" +
+	"\bapproved\x18\x04 \x01(\bR\bapproved\x12\x18\n" +
+	"\achainId\x18\x05 \x01(\tR\achainId\"&\n" +
+	"\fBlockRequest\x12\x16\n" +
+	"\x06height\x18\x01 \x01(\x03R\x06height\"&\n" +
+	"\x10BlockHashRequest\x12\x12\n" +
+	"\x04hash\x18\x01 \x01(\fR\x04hash\"\"\n" +
+	"\bGetBlock\x12\x16\n" +
+	"\x06height\x18\x01 \x01(\x03R\x06height\"\a\n" +
+	"\x05Empty\"<\n" +
+	"\x06Status\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12\x18\n" +
+	"\asuccess\x18\x02 \x01(\bR\asuccess\"0\n" +
+	"\rHeightRequest\x12\x1f\n" +
+	"\vfrom_height\x18\x01 \x01(\x03R\n" +
+	"fromHeight\"l\n" +
+	"\tBlockList\x12#\n" +
+	"\x06blocks\x18\x01 \x03(\v2\v.node.BlockR\x06blocks\x12\x1f\n" +
+	"\vnext_height\x18\x02 \x01(\x03R\n" +
+	"nextHeight\x12\x19\n" +
+	"\bhas_more\x18\x03 \x01(\bR\ahasMore\"p\n" +
+	"\x11BlockRangeRequest\x12\x1f\n" +
+	"\vfrom_height\x18\x01 \x01(\x03R\n" +
+	"fromHeight\x12\x1d\n" +
+	"\n" +
+	"max_blocks\x18\x02 \x01(\x03R\tmaxBlocks\x12\x1b\n" +
+	"\tmax_bytes\x18\x03 \x01(\x03R\bmaxBytes\"u\n" +
+	"\n" +
+	"HeaderList\x12+\n" +
+	"\aheaders\x18\x01 \x03(\v2\x11.node.BlockHeaderR\aheaders\x12\x1f\n" +
+	"\vnext_height\x18\x02 \x01(\x03R\n" +
+	"nextHeight\x12\x19\n" +
+	"\bhas_more\x18\x03 \x01(\bR\ahasMore\"U\n" +
+	"\x11GetBalanceRequest\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\x12\x1b\n" +
+	"\x06height\x18\x02 \x01(\x03H\x00R\x06height\x88\x01\x01B\t\n" +
+	"\a_height\"H\n" +
+	"\x12GetBalanceResponse\x12\x18\n" +
+	"\abalance\x18\x01 \x01(\x01R\abalance\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\"\xe1\x01\n" +
+	"\x15AccountHistoryRequest\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\x12/\n" +
+	"\tdirection\x18\x02 \x01(\x0e2\x11.node.TxDirectionR\tdirection\x12\x1f\n" +
+	"\vfrom_height\x18\x03 \x01(\x03R\n" +
+	"fromHeight\x12 \n" +
+	"\tto_height\x18\x04 \x01(\x03H\x00R\btoHeight\x88\x01\x01\x12\x16\n" +
+	"\x06cursor\x18\x05 \x01(\fR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\x06 \x01(\x05R\x05limitB\f\n" +
+	"\n" +
+	"_to_height\"\xb7\x01\n" +
+	"\tAccountTx\x12\x16\n" +
+	"\x06height\x18\x01 \x01(\x03R\x06height\x12\x14\n" +
+	"\x05index\x18\x02 \x01(\x05R\x05index\x12/\n" +
+	"\tdirection\x18\x03 \x01(\x0e2\x11.node.TxDirectionR\tdirection\x123\n" +
+	"\vtransaction\x18\x04 \x01(\v2\x11.node.TransactionR\vtransaction\x12\x16\n" +
+	"\x06txHash\x18\x05 \x01(\fR\x06txHash\"w\n" +
+	"\x0eAccountHistory\x12)\n" +
+	"\aentries\x18\x01 \x03(\v2\x0f.node.AccountTxR\aentries\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\fR\n" +
+	"nextCursor\x12\x19\n" +
+	"\bhas_more\x18\x03 \x01(\bR\ahasMore\"=\n" +
+	"\aAccount\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\x12\x18\n" +
+	"\abalance\x18\x02 \x01(\x01R\abalance\"\xa0\x01\n" +
+	"\fSnapshotInfo\x12\x16\n" +
+	"\x06height\x18\x01 \x01(\x03R\x06height\x12\x1c\n" +
+	"\tblockHash\x18\x02 \x01(\fR\tblockHash\x12\x1c\n" +
+	"\tstateRoot\x18\x03 \x01(\fR\tstateRoot\x12\x1a\n" +
+	"\baccounts\x18\x04 \x01(\x03R\baccounts\x12 \n" +
+	"\vchunkHashes\x18\x05 \x03(\fR\vchunkHashes\"@\n" +
+	"\fSnapshotList\x120\n" +
+	"\tsnapshots\x18\x01 \x03(\v2\x12.node.SnapshotInfoR\tsnapshots\"D\n" +
+	"\x14SnapshotChunkRequest\x12\x16\n" +
+	"\x06height\x18\x01 \x01(\x03R\x06height\x12\x14\n" +
+	"\x05index\x18\x02 \x01(\x05R\x05index\"h\n" +
+	"\rSnapshotChunk\x12\x16\n" +
+	"\x06height\x18\x01 \x01(\x03R\x06height\x12\x14\n" +
+	"\x05index\x18\x02 \x01(\x05R\x05index\x12)\n" +
+	"\baccounts\x18\x03 \x03(\v2\r.node.AccountR\baccounts\"_\n" +
+	"\x17TransactionProofRequest\x12\x16\n" +
+	"\x06height\x18\x01 \x01(\x03R\x06height\x12\x16\n" +
+	"\x06txHash\x18\x02 \x01(\fR\x06txHash\x12\x14\n" +
+	"\x05index\x18\x03 \x01(\x05R\x05index\"\x9b\x02\n" +
+	"\x10TransactionProof\x12\x16\n" +
+	"\x06height\x18\x01 \x01(\x03R\x06height\x12\x1c\n" +
+	"\tblockHash\x18\x02 \x01(\fR\tblockHash\x12\x1e\n" +
+	"\n" +
+	"merkleRoot\x18\x03 \x01(\fR\n" +
+	"merkleRoot\x12\x14\n" +
+	"\x05index\x18\x04 \x01(\x05R\x05index\x12\x16\n" +
+	"\x06txHash\x18\x05 \x01(\fR\x06txHash\x123\n" +
+	"\vtransaction\x18\x06 \x01(\v2\x11.node.TransactionR\vtransaction\x12\x14\n" +
+	"\x05proof\x18\a \x01(\fR\x05proof\x12\x1e\n" +
+	"\n" +
+	"txRootType\x18\b \x01(\x05R\n" +
+	"txRootType\x12\x18\n" +
+	"\atxCount\x18\t \x01(\x05R\atxCount\"f\n" +
+	"\x10HandshakeRequest\x12\x16\n" +
+	"\x06nodeId\x18\x01 \x01(\tR\x06nodeId\x12\x18\n" +
+	"\achainId\x18\x02 \x01(\tR\achainId\x12 \n" +
+	"\vgenesisHash\x18\x03 \x01(\fR\vgenesisHash\"\x7f\n" +
+	"\x11HandshakeResponse\x12\x16\n" +
+	"\x06nodeId\x18\x01 \x01(\tR\x06nodeId\x12\x18\n" +
+	"\achainId\x18\x02 \x01(\tR\achainId\x12 \n" +
+	"\vgenesisHash\x18\x03 \x01(\fR\vgenesisHash\x12\x16\n" +
+	"\x06height\x18\x04 \x01(\x03R\x06height*/\n" +
+	"\vTxDirection\x12\b\n" +
+	"\x04BOTH\x10\x00\x12\b\n" +
+	"\x04SENT\x10\x01\x12\f\n" +
+	"\bRECEIVED\x10\x022\xc1\b\n" +
+	"\vNodeService\x12<\n" +
+	"\tHandshake\x12\x16.node.HandshakeRequest\x1a\x17.node.HandshakeResponse\x122\n" +
 	"\x0fSendTransaction\x12\x11.node.Transaction\x1a\f.node.Status\x12)\n" +
 	"\fProposeBlock\x12\v.node.Block\x1a\f.node.Status\x12%\n" +
 	"\tVoteBlock\x12\n" +
 	".node.Vote\x1a\f.node.Status\x12+\n" +
 	"\bGetBlock\x12\x12.node.BlockRequest\x1a\v.node.Block\x12*\n" +
-	"\x0eGetLatestBlock\x12\v.node.Empty\x1a\v.node.Block\x12(\n" +
+	"\x0eGetLatestBlock\x12\v.node.Empty\x1a\v.node.Block\x125\n" +
+	"\x0eGetBlockByHash\x12\x16.node.BlockHashRequest\x1a\v.node.Block\x127\n" +
+	"\x0eGetBlockHeader\x12\x12.node.BlockRequest\x1a\x11.node.BlockHeader\x12A\n" +
+	"\x14GetBlockHeaderByHash\x12\x16.node.BlockHashRequest\x1a\x11.node.BlockHeader\x126\n" +
+	"\x14GetLatestBlockHeader\x12\v.node.Empty\x1a\x11.node.BlockHeader\x12(\n" +
 	"\vCommitBlock\x12\v.node.Block\x1a\f.node.Status\x12:\n" +
-	"\x12GetBlockFromHeight\x12\x13.node.HeightRequest\x1a\x0f.node.BlockList\x12?\n" +
+	"\x12GetBlockFromHeight\x12\x13.node.HeightRequest\x1a\x0f.node.BlockList\x129\n" +
+	"\rGetBlockRange\x12\x17.node.BlockRangeRequest\x1a\x0f.node.BlockList\x12;\n" +
+	"\x0eGetHeaderRange\x12\x17.node.BlockRangeRequest\x1a\x10.node.HeaderList\x12?\n" +
 	"\n" +
-	"GetBalance\x12\x17.node.GetBalanceRequest\x1a\x18.node.GetBalanceResponseB\x0eZ\fproto/nodepbb\x06proto3"
+	"GetBalance\x12\x17.node.GetBalanceRequest\x1a\x18.node.GetBalanceResponse\x12F\n" +
+	"\x11GetAccountHistory\x12\x1b.node.AccountHistoryRequest\x1a\x14.node.AccountHistory\x12L\n" +
+	"\x13GetTransactionProof\x12\x1d.node.TransactionProofRequest\x1a\x16.node.TransactionProof\x120\n" +
+	"\rListSnapshots\x12\v.node.Empty\x1a\x12.node.SnapshotList\x12C\n" +
+	"\x10GetSnapshotChunk\x12\x1a.node.SnapshotChunkRequest\x1a\x13.node.SnapshotChunkB\x0eZ\fproto/nodepbb\x06proto3"
 
 var (
 	file_proto_node_proto_rawDescOnce sync.Once
@@ -685,44 +2115,94 @@ func file_proto_node_proto_rawDescGZIP() []byte {
 	return file_proto_node_proto_rawDescData
 }
 
-var file_proto_node_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_proto_node_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_proto_node_proto_msgTypes = make([]protoimpl.MessageInfo, 28)
 var file_proto_node_proto_goTypes = []any{
-	(*Transaction)(nil),        // 0: node.Transaction
-	(*Block)(nil),              // 1: node.Block
-	(*Vote)(nil),               // 2: node.Vote
-	(*BlockRequest)(nil),       // 3: node.BlockRequest
-	(*GetBlock)(nil),           // 4: node.GetBlock
-	(*Empty)(nil),              // 5: node.Empty
-	(*Status)(nil),             // 6: node.Status
-	(*HeightRequest)(nil),      // 7: node.HeightRequest
-	(*BlockList)(nil),          // 8: node.BlockList
-	(*GetBalanceRequest)(nil),  // 9: node.GetBalanceRequest
-	(*GetBalanceResponse)(nil), // 10: node.GetBalanceResponse
+	(TxDirection)(0),                // 0: node.TxDirection
+	(*Transaction)(nil),             // 1: node.Transaction
+	(*Block)(nil),                   // 2: node.Block
+	(*BlockHeader)(nil),             // 3: node.BlockHeader
+	(*BlockBody)(nil),               // 4: node.BlockBody
+	(*Vote)(nil),                    // 5: node.Vote
+	(*BlockRequest)(nil),            // 6: node.BlockRequest
+	(*BlockHashRequest)(nil),        // 7: node.BlockHashRequest
+	(*GetBlock)(nil),                // 8: node.GetBlock
+	(*Empty)(nil),                   // 9: node.Empty
+	(*Status)(nil),                  // 10: node.Status
+	(*HeightRequest)(nil),           // 11: node.HeightRequest
+	(*BlockList)(nil),               // 12: node.BlockList
+	(*BlockRangeRequest)(nil),       // 13: node.BlockRangeRequest
+	(*HeaderList)(nil),              // 14: node.HeaderList
+	(*GetBalanceRequest)(nil),       // 15: node.GetBalanceRequest
+	(*GetBalanceResponse)(nil),      // 16: node.GetBalanceResponse
+	(*AccountHistoryRequest)(nil),   // 17: node.AccountHistoryRequest
+	(*AccountTx)(nil),               // 18: node.AccountTx
+	(*AccountHistory)(nil),          // 19: node.AccountHistory
+	(*Account)(nil),                 // 20: node.Account
+	(*SnapshotInfo)(nil),            // 21: node.SnapshotInfo
+	(*SnapshotList)(nil),            // 22: node.SnapshotList
+	(*SnapshotChunkRequest)(nil),    // 23: node.SnapshotChunkRequest
+	(*SnapshotChunk)(nil),           // 24: node.SnapshotChunk
+	(*TransactionProofRequest)(nil), // 25: node.TransactionProofRequest
+	(*TransactionProof)(nil),        // 26: node.TransactionProof
+	(*HandshakeRequest)(nil),        // 27: node.HandshakeRequest
+	(*HandshakeResponse)(nil),       // 28: node.HandshakeResponse
 }
 var file_proto_node_proto_depIdxs = []int32{
-	0,  // 0: node.Block.transactions:type_name -> node.Transaction
-	1,  // 1: node.BlockList.blocks:type_name -> node.Block
-	0,  // 2: node.NodeService.SendTransaction:input_type -> node.Transaction
-	1,  // 3: node.NodeService.ProposeBlock:input_type -> node.Block
-	2,  // 4: node.NodeService.VoteBlock:input_type -> node.Vote
-	3,  // 5: node.NodeService.GetBlock:input_type -> node.BlockRequest
-	5,  // 6: node.NodeService.GetLatestBlock:input_type -> node.Empty
-	1,  // 7: node.NodeService.CommitBlock:input_type -> node.Block
-	7,  // 8: node.NodeService.GetBlockFromHeight:input_type -> node.HeightRequest
-	9,  // 9: node.NodeService.GetBalance:input_type -> node.GetBalanceRequest
-	6,  // 10: node.NodeService.SendTransaction:output_type -> node.Status
-	6,  // 11: node.NodeService.ProposeBlock:output_type -> node.Status
-	6,  // 12: node.NodeService.VoteBlock:output_type -> node.Status
-	1,  // 13: node.NodeService.GetBlock:output_type -> node.Block
-	1,  // 14: node.NodeService.GetLatestBlock:output_type -> node.Block
-	6,  // 15: node.NodeService.CommitBlock:output_type -> node.Status
-	8,  // 16: node.NodeService.GetBlockFromHeight:output_type -> node.BlockList
-	10, // 17: node.NodeService.GetBalance:output_type -> node.GetBalanceResponse
-	10, // [10:18] is the sub-list for method output_type
-	2,  // [2:10] is the sub-list for method input_type
-	2,  // [2:2] is the sub-list for extension type_name
-	2,  // [2:2] is the sub-list for extension extendee
-	0,  // [0:2] is the sub-list for field type_name
+	1,  // 0: node.Block.transactions:type_name -> node.Transaction
+	1,  // 1: node.BlockBody.transactions:type_name -> node.Transaction
+	2,  // 2: node.BlockList.blocks:type_name -> node.Block
+	3,  // 3: node.HeaderList.headers:type_name -> node.BlockHeader
+	0,  // 4: node.AccountHistoryRequest.direction:type_name -> node.TxDirection
+	0,  // 5: node.AccountTx.direction:type_name -> node.TxDirection
+	1,  // 6: node.AccountTx.transaction:type_name -> node.Transaction
+	18, // 7: node.AccountHistory.entries:type_name -> node.AccountTx
+	21, // 8: node.SnapshotList.snapshots:type_name -> node.SnapshotInfo
+	20, // 9: node.SnapshotChunk.accounts:type_name -> node.Account
+	1,  // 10: node.TransactionProof.transaction:type_name -> node.Transaction
+	27, // 11: node.NodeService.Handshake:input_type -> node.HandshakeRequest
+	1,  // 12: node.NodeService.SendTransaction:input_type -> node.Transaction
+	2,  // 13: node.NodeService.ProposeBlock:input_type -> node.Block
+	5,  // 14: node.NodeService.VoteBlock:input_type -> node.Vote
+	6,  // 15: node.NodeService.GetBlock:input_type -> node.BlockRequest
+	9,  // 16: node.NodeService.GetLatestBlock:input_type -> node.Empty
+	7,  // 17: node.NodeService.GetBlockByHash:input_type -> node.BlockHashRequest
+	6,  // 18: node.NodeService.GetBlockHeader:input_type -> node.BlockRequest
+	7,  // 19: node.NodeService.GetBlockHeaderByHash:input_type -> node.BlockHashRequest
+	9,  // 20: node.NodeService.GetLatestBlockHeader:input_type -> node.Empty
+	2,  // 21: node.NodeService.CommitBlock:input_type -> node.Block
+	11, // 22: node.NodeService.GetBlockFromHeight:input_type -> node.HeightRequest
+	13, // 23: node.NodeService.GetBlockRange:input_type -> node.BlockRangeRequest
+	13, // 24: node.NodeService.GetHeaderRange:input_type -> node.BlockRangeRequest
+	15, // 25: node.NodeService.GetBalance:input_type -> node.GetBalanceRequest
+	17, // 26: node.NodeService.GetAccountHistory:input_type -> node.AccountHistoryRequest
+	25, // 27: node.NodeService.GetTransactionProof:input_type -> node.TransactionProofRequest
+	9,  // 28: node.NodeService.ListSnapshots:input_type -> node.Empty
+	23, // 29: node.NodeService.GetSnapshotChunk:input_type -> node.SnapshotChunkRequest
+	28, // 30: node.NodeService.Handshake:output_type -> node.HandshakeResponse
+	10, // 31: node.NodeService.SendTransaction:output_type -> node.Status
+	10, // 32: node.NodeService.ProposeBlock:output_type -> node.Status
+	10, // 33: node.NodeService.VoteBlock:output_type -> node.Status
+	2,  // 34: node.NodeService.GetBlock:output_type -> node.Block
+	2,  // 35: node.NodeService.GetLatestBlock:output_type -> node.Block
+	2,  // 36: node.NodeService.GetBlockByHash:output_type -> node.Block
+	3,  // 37: node.NodeService.GetBlockHeader:output_type -> node.BlockHeader
+	3,  // 38: node.NodeService.GetBlockHeaderByHash:output_type -> node.BlockHeader
+	3,  // 39: node.NodeService.GetLatestBlockHeader:output_type -> node.BlockHeader
+	10, // 40: node.NodeService.CommitBlock:output_type -> node.Status
+	12, // 41: node.NodeService.GetBlockFromHeight:output_type -> node.BlockList
+	12, // 42: node.NodeService.GetBlockRange:output_type -> node.BlockList
+	14, // 43: node.NodeService.GetHeaderRange:output_type -> node.HeaderList
+	16, // 44: node.NodeService.GetBalance:output_type -> node.GetBalanceResponse
+	19, // 45: node.NodeService.GetAccountHistory:output_type -> node.AccountHistory
+	26, // 46: node.NodeService.GetTransactionProof:output_type -> node.TransactionProof
+	22, // 47: node.NodeService.ListSnapshots:output_type -> node.SnapshotList
+	24, // 48: node.NodeService.GetSnapshotChunk:output_type -> node.SnapshotChunk
+	30, // [30:49] is the sub-list for method output_type
+	11, // [11:30] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
 }
 
 func init() { file_proto_node_proto_init() }
@@ -730,18 +2210,21 @@ func file_proto_node_proto_init() {
 	if File_proto_node_proto != nil {
 		return
 	}
+	file_proto_node_proto_msgTypes[14].OneofWrappers = []any{}
+	file_proto_node_proto_msgTypes[16].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_node_proto_rawDesc), len(file_proto_node_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   11,
+			NumEnums:      1,
+			NumMessages:   28,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_proto_node_proto_goTypes,
 		DependencyIndexes: file_proto_node_proto_depIdxs,
+		EnumInfos:         file_proto_node_proto_enumTypes,
 		MessageInfos:      file_proto_node_proto_msgTypes,
 	}.Build()
 	File_proto_node_proto = out.File
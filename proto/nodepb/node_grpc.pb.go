@@ -19,20 +19,33 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	NodeService_SendTransaction_FullMethodName    = "/node.NodeService/SendTransaction"
-	NodeService_ProposeBlock_FullMethodName       = "/node.NodeService/ProposeBlock"
-	NodeService_VoteBlock_FullMethodName          = "/node.NodeService/VoteBlock"
-	NodeService_GetBlock_FullMethodName           = "/node.NodeService/GetBlock"
-	NodeService_GetLatestBlock_FullMethodName     = "/node.NodeService/GetLatestBlock"
-	NodeService_CommitBlock_FullMethodName        = "/node.NodeService/CommitBlock"
-	NodeService_GetBlockFromHeight_FullMethodName = "/node.NodeService/GetBlockFromHeight"
-	NodeService_GetBalance_FullMethodName         = "/node.NodeService/GetBalance"
+	NodeService_Handshake_FullMethodName            = "/node.NodeService/Handshake"
+	NodeService_SendTransaction_FullMethodName      = "/node.NodeService/SendTransaction"
+	NodeService_ProposeBlock_FullMethodName         = "/node.NodeService/ProposeBlock"
+	NodeService_VoteBlock_FullMethodName            = "/node.NodeService/VoteBlock"
+	NodeService_GetBlock_FullMethodName             = "/node.NodeService/GetBlock"
+	NodeService_GetLatestBlock_FullMethodName       = "/node.NodeService/GetLatestBlock"
+	NodeService_GetBlockByHash_FullMethodName       = "/node.NodeService/GetBlockByHash"
+	NodeService_GetBlockHeader_FullMethodName       = "/node.NodeService/GetBlockHeader"
+	NodeService_GetBlockHeaderByHash_FullMethodName = "/node.NodeService/GetBlockHeaderByHash"
+	NodeService_GetLatestBlockHeader_FullMethodName = "/node.NodeService/GetLatestBlockHeader"
+	NodeService_CommitBlock_FullMethodName          = "/node.NodeService/CommitBlock"
+	NodeService_GetBlockFromHeight_FullMethodName   = "/node.NodeService/GetBlockFromHeight"
+	NodeService_GetBlockRange_FullMethodName        = "/node.NodeService/GetBlockRange"
+	NodeService_GetHeaderRange_FullMethodName       = "/node.NodeService/GetHeaderRange"
+	NodeService_GetBalance_FullMethodName           = "/node.NodeService/GetBalance"
+	NodeService_GetAccountHistory_FullMethodName    = "/node.NodeService/GetAccountHistory"
+	NodeService_GetTransactionProof_FullMethodName  = "/node.NodeService/GetTransactionProof"
+	NodeService_ListSnapshots_FullMethodName        = "/node.NodeService/ListSnapshots"
+	NodeService_GetSnapshotChunk_FullMethodName     = "/node.NodeService/GetSnapshotChunk"
 )
 
 // NodeServiceClient is the client API for NodeService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type NodeServiceClient interface {
+	// Check that both sides run the same chain (chain ID and genesis hash)
+	Handshake(ctx context.Context, in *HandshakeRequest, opts ...grpc.CallOption) (*HandshakeResponse, error)
 	// Send a signed transaction to a node
 	SendTransaction(ctx context.Context, in *Transaction, opts ...grpc.CallOption) (*Status, error)
 	// Leader proposes a block to followers
@@ -43,11 +56,30 @@ type NodeServiceClient interface {
 	GetBlock(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*Block, error)
 	// Sync: Get the latest block
 	GetLatestBlock(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Block, error)
+	// Sync: Get block by hash
+	GetBlockByHash(ctx context.Context, in *BlockHashRequest, opts ...grpc.CallOption) (*Block, error)
+	// Header-only variants of the block queries
+	GetBlockHeader(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*BlockHeader, error)
+	GetBlockHeaderByHash(ctx context.Context, in *BlockHashRequest, opts ...grpc.CallOption) (*BlockHeader, error)
+	GetLatestBlockHeader(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*BlockHeader, error)
 	// Block commit
 	CommitBlock(ctx context.Context, in *Block, opts ...grpc.CallOption) (*Status, error)
-	// Get Block from height
+	// Get Block from height (one page, capped like GetBlockRange)
 	GetBlockFromHeight(ctx context.Context, in *HeightRequest, opts ...grpc.CallOption) (*BlockList, error)
+	// Sync: page of full blocks starting at a height
+	GetBlockRange(ctx context.Context, in *BlockRangeRequest, opts ...grpc.CallOption) (*BlockList, error)
+	// Sync: page of headers starting at a height (header-first download)
+	GetHeaderRange(ctx context.Context, in *BlockRangeRequest, opts ...grpc.CallOption) (*HeaderList, error)
+	// Get balance
 	GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*GetBalanceResponse, error)
+	// Paged list of the transactions sent or received by an address
+	GetAccountHistory(ctx context.Context, in *AccountHistoryRequest, opts ...grpc.CallOption) (*AccountHistory, error)
+	// Inclusion proof of a transaction against its block's Merkle root
+	GetTransactionProof(ctx context.Context, in *TransactionProofRequest, opts ...grpc.CallOption) (*TransactionProof, error)
+	// Snapshot sync: list available state snapshots, newest first
+	ListSnapshots(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SnapshotList, error)
+	// Snapshot sync: download one chunk of a snapshot
+	GetSnapshotChunk(ctx context.Context, in *SnapshotChunkRequest, opts ...grpc.CallOption) (*SnapshotChunk, error)
 }
 
 type nodeServiceClient struct {
@@ -58,6 +90,16 @@ func NewNodeServiceClient(cc grpc.ClientConnInterface) NodeServiceClient {
 	return &nodeServiceClient{cc}
 }
 
+func (c *nodeServiceClient) Handshake(ctx context.Context, in *HandshakeRequest, opts ...grpc.CallOption) (*HandshakeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HandshakeResponse)
+	err := c.cc.Invoke(ctx, NodeService_Handshake_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *nodeServiceClient) SendTransaction(ctx context.Context, in *Transaction, opts ...grpc.CallOption) (*Status, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(Status)
@@ -108,6 +150,46 @@ func (c *nodeServiceClient) GetLatestBlock(ctx context.Context, in *Empty, opts
 	return out, nil
 }
 
+func (c *nodeServiceClient) GetBlockByHash(ctx context.Context, in *BlockHashRequest, opts ...grpc.CallOption) (*Block, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Block)
+	err := c.cc.Invoke(ctx, NodeService_GetBlockByHash_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeServiceClient) GetBlockHeader(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*BlockHeader, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BlockHeader)
+	err := c.cc.Invoke(ctx, NodeService_GetBlockHeader_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeServiceClient) GetBlockHeaderByHash(ctx context.Context, in *BlockHashRequest, opts ...grpc.CallOption) (*BlockHeader, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BlockHeader)
+	err := c.cc.Invoke(ctx, NodeService_GetBlockHeaderByHash_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeServiceClient) GetLatestBlockHeader(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*BlockHeader, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BlockHeader)
+	err := c.cc.Invoke(ctx, NodeService_GetLatestBlockHeader_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *nodeServiceClient) CommitBlock(ctx context.Context, in *Block, opts ...grpc.CallOption) (*Status, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(Status)
@@ -128,6 +210,26 @@ func (c *nodeServiceClient) GetBlockFromHeight(ctx context.Context, in *HeightRe
 	return out, nil
 }
 
+func (c *nodeServiceClient) GetBlockRange(ctx context.Context, in *BlockRangeRequest, opts ...grpc.CallOption) (*BlockList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BlockList)
+	err := c.cc.Invoke(ctx, NodeService_GetBlockRange_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeServiceClient) GetHeaderRange(ctx context.Context, in *BlockRangeRequest, opts ...grpc.CallOption) (*HeaderList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HeaderList)
+	err := c.cc.Invoke(ctx, NodeService_GetHeaderRange_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *nodeServiceClient) GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*GetBalanceResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetBalanceResponse)
@@ -138,10 +240,52 @@ func (c *nodeServiceClient) GetBalance(ctx context.Context, in *GetBalanceReques
 	return out, nil
 }
 
+func (c *nodeServiceClient) GetAccountHistory(ctx context.Context, in *AccountHistoryRequest, opts ...grpc.CallOption) (*AccountHistory, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AccountHistory)
+	err := c.cc.Invoke(ctx, NodeService_GetAccountHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeServiceClient) GetTransactionProof(ctx context.Context, in *TransactionProofRequest, opts ...grpc.CallOption) (*TransactionProof, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TransactionProof)
+	err := c.cc.Invoke(ctx, NodeService_GetTransactionProof_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeServiceClient) ListSnapshots(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SnapshotList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SnapshotList)
+	err := c.cc.Invoke(ctx, NodeService_ListSnapshots_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeServiceClient) GetSnapshotChunk(ctx context.Context, in *SnapshotChunkRequest, opts ...grpc.CallOption) (*SnapshotChunk, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SnapshotChunk)
+	err := c.cc.Invoke(ctx, NodeService_GetSnapshotChunk_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // NodeServiceServer is the server API for NodeService service.
 // All implementations must embed UnimplementedNodeServiceServer
 // for forward compatibility.
 type NodeServiceServer interface {
+	// Check that both sides run the same chain (chain ID and genesis hash)
+	Handshake(context.Context, *HandshakeRequest) (*HandshakeResponse, error)
 	// Send a signed transaction to a node
 	SendTransaction(context.Context, *Transaction) (*Status, error)
 	// Leader proposes a block to followers
@@ -152,11 +296,30 @@ type NodeServiceServer interface {
 	GetBlock(context.Context, *BlockRequest) (*Block, error)
 	// Sync: Get the latest block
 	GetLatestBlock(context.Context, *Empty) (*Block, error)
+	// Sync: Get block by hash
+	GetBlockByHash(context.Context, *BlockHashRequest) (*Block, error)
+	// Header-only variants of the block queries
+	GetBlockHeader(context.Context, *BlockRequest) (*BlockHeader, error)
+	GetBlockHeaderByHash(context.Context, *BlockHashRequest) (*BlockHeader, error)
+	GetLatestBlockHeader(context.Context, *Empty) (*BlockHeader, error)
 	// Block commit
 	CommitBlock(context.Context, *Block) (*Status, error)
-	// Get Block from height
+	// Get Block from height (one page, capped like GetBlockRange)
 	GetBlockFromHeight(context.Context, *HeightRequest) (*BlockList, error)
+	// Sync: page of full blocks starting at a height
+	GetBlockRange(context.Context, *BlockRangeRequest) (*BlockList, error)
+	// Sync: page of headers starting at a height (header-first download)
+	GetHeaderRange(context.Context, *BlockRangeRequest) (*HeaderList, error)
+	// Get balance
 	GetBalance(context.Context, *GetBalanceRequest) (*GetBalanceResponse, error)
+	// Paged list of the transactions sent or received by an address
+	GetAccountHistory(context.Context, *AccountHistoryRequest) (*AccountHistory, error)
+	// Inclusion proof of a transaction against its block's Merkle root
+	GetTransactionProof(context.Context, *TransactionProofRequest) (*TransactionProof, error)
+	// Snapshot sync: list available state snapshots, newest first
+	ListSnapshots(context.Context, *Empty) (*SnapshotList, error)
+	// Snapshot sync: download one chunk of a snapshot
+	GetSnapshotChunk(context.Context, *SnapshotChunkRequest) (*SnapshotChunk, error)
 	mustEmbedUnimplementedNodeServiceServer()
 }
 
@@ -167,6 +330,9 @@ type NodeServiceServer interface {
 // pointer dereference when methods are called.
 type UnimplementedNodeServiceServer struct{}
 
+func (UnimplementedNodeServiceServer) Handshake(context.Context, *HandshakeRequest) (*HandshakeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Handshake not implemented")
+}
 func (UnimplementedNodeServiceServer) SendTransaction(context.Context, *Transaction) (*Status, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SendTransaction not implemented")
 }
@@ -182,15 +348,45 @@ func (UnimplementedNodeServiceServer) GetBlock(context.Context, *BlockRequest) (
 func (UnimplementedNodeServiceServer) GetLatestBlock(context.Context, *Empty) (*Block, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetLatestBlock not implemented")
 }
+func (UnimplementedNodeServiceServer) GetBlockByHash(context.Context, *BlockHashRequest) (*Block, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlockByHash not implemented")
+}
+func (UnimplementedNodeServiceServer) GetBlockHeader(context.Context, *BlockRequest) (*BlockHeader, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlockHeader not implemented")
+}
+func (UnimplementedNodeServiceServer) GetBlockHeaderByHash(context.Context, *BlockHashRequest) (*BlockHeader, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlockHeaderByHash not implemented")
+}
+func (UnimplementedNodeServiceServer) GetLatestBlockHeader(context.Context, *Empty) (*BlockHeader, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLatestBlockHeader not implemented")
+}
 func (UnimplementedNodeServiceServer) CommitBlock(context.Context, *Block) (*Status, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CommitBlock not implemented")
 }
 func (UnimplementedNodeServiceServer) GetBlockFromHeight(context.Context, *HeightRequest) (*BlockList, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetBlockFromHeight not implemented")
 }
+func (UnimplementedNodeServiceServer) GetBlockRange(context.Context, *BlockRangeRequest) (*BlockList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlockRange not implemented")
+}
+func (UnimplementedNodeServiceServer) GetHeaderRange(context.Context, *BlockRangeRequest) (*HeaderList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHeaderRange not implemented")
+}
 func (UnimplementedNodeServiceServer) GetBalance(context.Context, *GetBalanceRequest) (*GetBalanceResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetBalance not implemented")
 }
+func (UnimplementedNodeServiceServer) GetAccountHistory(context.Context, *AccountHistoryRequest) (*AccountHistory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAccountHistory not implemented")
+}
+func (UnimplementedNodeServiceServer) GetTransactionProof(context.Context, *TransactionProofRequest) (*TransactionProof, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransactionProof not implemented")
+}
+func (UnimplementedNodeServiceServer) ListSnapshots(context.Context, *Empty) (*SnapshotList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSnapshots not implemented")
+}
+func (UnimplementedNodeServiceServer) GetSnapshotChunk(context.Context, *SnapshotChunkRequest) (*SnapshotChunk, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSnapshotChunk not implemented")
+}
 func (UnimplementedNodeServiceServer) mustEmbedUnimplementedNodeServiceServer() {}
 func (UnimplementedNodeServiceServer) testEmbeddedByValue()                     {}
 
@@ -212,6 +408,24 @@ func RegisterNodeServiceServer(s grpc.ServiceRegistrar, srv NodeServiceServer) {
 	s.RegisterService(&NodeService_ServiceDesc, srv)
 }
 
+func _NodeService_Handshake_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HandshakeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).Handshake(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NodeService_Handshake_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).Handshake(ctx, req.(*HandshakeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _NodeService_SendTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Transaction)
 	if err := dec(in); err != nil {
@@ -302,6 +516,78 @@ func _NodeService_GetLatestBlock_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _NodeService_GetBlockByHash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockHashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).GetBlockByHash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NodeService_GetBlockByHash_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).GetBlockByHash(ctx, req.(*BlockHashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NodeService_GetBlockHeader_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).GetBlockHeader(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NodeService_GetBlockHeader_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).GetBlockHeader(ctx, req.(*BlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NodeService_GetBlockHeaderByHash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockHashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).GetBlockHeaderByHash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NodeService_GetBlockHeaderByHash_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).GetBlockHeaderByHash(ctx, req.(*BlockHashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NodeService_GetLatestBlockHeader_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).GetLatestBlockHeader(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NodeService_GetLatestBlockHeader_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).GetLatestBlockHeader(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _NodeService_CommitBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Block)
 	if err := dec(in); err != nil {
@@ -338,6 +624,42 @@ func _NodeService_GetBlockFromHeight_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _NodeService_GetBlockRange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockRangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).GetBlockRange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NodeService_GetBlockRange_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).GetBlockRange(ctx, req.(*BlockRangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NodeService_GetHeaderRange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockRangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).GetHeaderRange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NodeService_GetHeaderRange_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).GetHeaderRange(ctx, req.(*BlockRangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _NodeService_GetBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetBalanceRequest)
 	if err := dec(in); err != nil {
@@ -356,6 +678,78 @@ func _NodeService_GetBalance_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _NodeService_GetAccountHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccountHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).GetAccountHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NodeService_GetAccountHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).GetAccountHistory(ctx, req.(*AccountHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NodeService_GetTransactionProof_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransactionProofRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).GetTransactionProof(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NodeService_GetTransactionProof_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).GetTransactionProof(ctx, req.(*TransactionProofRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NodeService_ListSnapshots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).ListSnapshots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NodeService_ListSnapshots_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).ListSnapshots(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NodeService_GetSnapshotChunk_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SnapshotChunkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).GetSnapshotChunk(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NodeService_GetSnapshotChunk_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).GetSnapshotChunk(ctx, req.(*SnapshotChunkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // NodeService_ServiceDesc is the grpc.ServiceDesc for NodeService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -363,6 +757,10 @@ var NodeService_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "node.NodeService",
 	HandlerType: (*NodeServiceServer)(nil),
 	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Handshake",
+			Handler:    _NodeService_Handshake_Handler,
+		},
 		{
 			MethodName: "SendTransaction",
 			Handler:    _NodeService_SendTransaction_Handler,
@@ -383,6 +781,22 @@ var NodeService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetLatestBlock",
 			Handler:    _NodeService_GetLatestBlock_Handler,
 		},
+		{
+			MethodName: "GetBlockByHash",
+			Handler:    _NodeService_GetBlockByHash_Handler,
+		},
+		{
+			MethodName: "GetBlockHeader",
+			Handler:    _NodeService_GetBlockHeader_Handler,
+		},
+		{
+			MethodName: "GetBlockHeaderByHash",
+			Handler:    _NodeService_GetBlockHeaderByHash_Handler,
+		},
+		{
+			MethodName: "GetLatestBlockHeader",
+			Handler:    _NodeService_GetLatestBlockHeader_Handler,
+		},
 		{
 			MethodName: "CommitBlock",
 			Handler:    _NodeService_CommitBlock_Handler,
@@ -391,10 +805,34 @@ var NodeService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetBlockFromHeight",
 			Handler:    _NodeService_GetBlockFromHeight_Handler,
 		},
+		{
+			MethodName: "GetBlockRange",
+			Handler:    _NodeService_GetBlockRange_Handler,
+		},
+		{
+			MethodName: "GetHeaderRange",
+			Handler:    _NodeService_GetHeaderRange_Handler,
+		},
 		{
 			MethodName: "GetBalance",
 			Handler:    _NodeService_GetBalance_Handler,
 		},
+		{
+			MethodName: "GetAccountHistory",
+			Handler:    _NodeService_GetAccountHistory_Handler,
+		},
+		{
+			MethodName: "GetTransactionProof",
+			Handler:    _NodeService_GetTransactionProof_Handler,
+		},
+		{
+			MethodName: "ListSnapshots",
+			Handler:    _NodeService_ListSnapshots_Handler,
+		},
+		{
+			MethodName: "GetSnapshotChunk",
+			Handler:    _NodeService_GetSnapshotChunk_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/node.proto",